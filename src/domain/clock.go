@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"sync"
+	"time"
+)
+
+//Clock tells the time at which things happen, such as the date of a new tweet
+type Clock interface {
+	Now() time.Time
+}
+
+//SystemClock is a Clock that tells the actual time
+type SystemClock struct{}
+
+//Now returns the current time
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+//FixedClock is a Clock that stays at the same time until it is told to move, used for testing
+type FixedClock struct {
+	now   time.Time
+	mutex sync.Mutex
+}
+
+//NewFixedClock returns a FixedClock stopped at now
+func NewFixedClock(now time.Time) *FixedClock {
+	return &FixedClock{now: now}
+}
+
+//Now returns the time the clock is stopped at
+func (c *FixedClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+//Set stops the clock at another time
+func (c *FixedClock) Set(now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = now
+}
+
+//Advance moves the clock forward by d
+func (c *FixedClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
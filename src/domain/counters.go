@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+//Counters are how many times a tweet was liked and retweeted
+type Counters struct {
+	Likes    int `json:"likes"`
+	Retweets int `json:"retweets"`
+}
+
+//String returns the counters that aren't zero, as they are shown after the text of a tweet
+func (c Counters) String() string {
+	var parts []string
+	if c.Likes > 0 {
+		parts = append(parts, plural(c.Likes, "like"))
+	}
+	if c.Retweets > 0 {
+		parts = append(parts, plural(c.Retweets, "retweet"))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", strings.Join(parts, ", "))
+}
+
+func plural(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
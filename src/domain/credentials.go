@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//Schemes a password hash can be written in. The scheme is the first field of the hash,
+//so hashes written by older versions can still be verified and then upgraded
+const (
+	//PlainPasswordScheme is how passwords were kept before they were hashed: "plain$<password>"
+	PlainPasswordScheme = "plain"
+	//PBKDF2PasswordScheme is "pbkdf2-sha256$<iterations>$<salt>$<key>", salt and key in base64
+	PBKDF2PasswordScheme = "pbkdf2-sha256"
+)
+
+const (
+	passwordSaltSize = 16
+	passwordKeySize  = 32
+)
+
+//PasswordHashIterations is how many PBKDF2 iterations new hashes use.
+//Hashes with fewer iterations get upgraded the next time their user logs in
+var PasswordHashIterations = 100000
+
+//ErrInvalidPasswordHash is returned when a stored hash can't be read
+var ErrInvalidPasswordHash = errors.New("Invalid password hash")
+
+//Credentials prove that someone is a user. They are kept apart from the User,
+//which is public and referenced by tweets
+type Credentials struct {
+	Name         string `json:"name"`
+	PasswordHash string `json:"passwordHash"`
+}
+
+//NewCredentials returns the credentials of a user, hashing their password with a random salt
+func NewCredentials(name, password string) (Credentials, error) {
+	hash, err := HashPassword(password)
+	if err != nil {
+		return Credentials{}, err
+	}
+	return Credentials{Name: name, PasswordHash: hash}, nil
+}
+
+//NewPlainCredentials returns credentials that keep the password as it is.
+//They are only meant for users saved before passwords were hashed
+func NewPlainCredentials(name, password string) Credentials {
+	return Credentials{Name: name, PasswordHash: PlainPasswordScheme + "$" + password}
+}
+
+//Verify returns if password is the one of the credentials
+func (c Credentials) Verify(password string) bool {
+	ok, err := VerifyPassword(c.PasswordHash, password)
+	return err == nil && ok
+}
+
+//NeedsUpgrade returns if the password hash is weaker than the one new credentials get
+func (c Credentials) NeedsUpgrade() bool {
+	fields := strings.Split(c.PasswordHash, "$")
+	if fields[0] != PBKDF2PasswordScheme || len(fields) != 4 {
+		return true
+	}
+	iterations, err := strconv.Atoi(fields[1])
+	return err != nil || iterations < PasswordHashIterations
+}
+
+//HashPassword returns a salted hash of password in the current scheme
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, passwordSaltSize)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", fmt.Errorf("Couldn't hash password, %w", err)
+	}
+	key, err := pbkdf2.Key(sha256.New, password, salt, PasswordHashIterations, passwordKeySize)
+	if err != nil {
+		return "", fmt.Errorf("Couldn't hash password, %w", err)
+	}
+	return strings.Join([]string{
+		PBKDF2PasswordScheme,
+		strconv.Itoa(PasswordHashIterations),
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	}, "$"), nil
+}
+
+//VerifyPassword returns if password is the one that was hashed, whatever the scheme of the hash
+func VerifyPassword(hash, password string) (bool, error) {
+	fields := strings.Split(hash, "$")
+	switch fields[0] {
+	case PlainPasswordScheme:
+		plain := strings.TrimPrefix(hash, PlainPasswordScheme+"$")
+		return subtle.ConstantTimeCompare([]byte(plain), []byte(password)) == 1, nil
+	case PBKDF2PasswordScheme:
+		if len(fields) != 4 {
+			return false, ErrInvalidPasswordHash
+		}
+		iterations, err := strconv.Atoi(fields[1])
+		if err != nil || iterations <= 0 {
+			return false, ErrInvalidPasswordHash
+		}
+		salt, err := base64.RawStdEncoding.DecodeString(fields[2])
+		if err != nil {
+			return false, ErrInvalidPasswordHash
+		}
+		expected, err := base64.RawStdEncoding.DecodeString(fields[3])
+		if err != nil {
+			return false, ErrInvalidPasswordHash
+		}
+		key, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(expected))
+		if err != nil {
+			return false, err
+		}
+		return subtle.ConstantTimeCompare(key, expected) == 1, nil
+	}
+	return false, ErrInvalidPasswordHash
+}
@@ -0,0 +1,83 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/cursoGo/src/domain"
+)
+
+func TestCredentialsVerifyTheirPassword(t *testing.T) {
+	//Initialization
+	credentials, err := domain.NewCredentials("root", "hunter2")
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+
+	//Operation
+	right := credentials.Verify("hunter2")
+	wrong := credentials.Verify("hunter3")
+
+	//Validation
+	if !right {
+		t.Error("The right password was not verified")
+	}
+	if wrong {
+		t.Error("A wrong password was verified")
+	}
+}
+
+func TestPasswordHashesAreSalted(t *testing.T) {
+	//Operation
+	first, _ := domain.HashPassword("hunter2")
+	second, _ := domain.HashPassword("hunter2")
+
+	//Validation
+	if first == second {
+		t.Error("Two hashes of the same password should be different")
+	}
+}
+
+func TestPlainCredentialsNeedUpgrade(t *testing.T) {
+	//Initialization
+	credentials := domain.NewPlainCredentials("root", "hunter2")
+
+	//Validation
+	if !credentials.Verify("hunter2") {
+		t.Error("The right password was not verified")
+	}
+	if !credentials.NeedsUpgrade() {
+		t.Error("Plain credentials should need an upgrade")
+	}
+}
+
+func TestCredentialsWithFewerIterationsNeedUpgrade(t *testing.T) {
+	//Initialization
+	iterations := domain.PasswordHashIterations
+	defer func() { domain.PasswordHashIterations = iterations }()
+	domain.PasswordHashIterations = 1000
+	credentials, _ := domain.NewCredentials("root", "hunter2")
+
+	//Operation
+	current := credentials.NeedsUpgrade()
+	domain.PasswordHashIterations = 2000
+	outdated := credentials.NeedsUpgrade()
+
+	//Validation
+	if current || !outdated {
+		t.Error("Only hashes with fewer iterations than the current ones should need an upgrade")
+	}
+	if !credentials.Verify("hunter2") {
+		t.Error("Outdated credentials should still be verified")
+	}
+}
+
+func TestCantVerifyInvalidHash(t *testing.T) {
+	//Operation
+	_, err := domain.VerifyPassword("md5$abc", "hunter2")
+
+	//Validation
+	if err != domain.ErrInvalidPasswordHash {
+		t.Errorf("Expected ErrInvalidPasswordHash but got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+//Draft is a text or image tweet that a user saved to publish later. A scheduled draft is published
+//on its own at PublishAt
+type Draft struct {
+	ID     int    `json:"id"`
+	Author User   `json:"author"`
+	Text   string `json:"text"`
+	//ImageURL is the URL of the image of the tweet, or empty for a text tweet
+	ImageURL string    `json:"imageURL,omitempty"`
+	Saved    time.Time `json:"saved"`
+	//PublishAt is when the draft is published, if it is scheduled
+	PublishAt *time.Time `json:"publishAt,omitempty"`
+	//Error is why the draft couldn't be published when it was scheduled to be
+	Error string `json:"error,omitempty"`
+}
+
+//NewDraft returns a draft of author, if its text could be the text of a tweet of up to maxLength characters
+func NewDraft(id int, author User, text string, imageURL string, saved time.Time, maxLength int) (*Draft, error) {
+	draft := &Draft{ID: id, Author: User{ID: author.ID, Name: author.Name}, Saved: saved}
+	if err := draft.SetContent(text, imageURL, maxLength); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+//SetContent changes the text and image URL of the draft, if the text could be the text of a tweet
+//of up to maxLength characters
+func (d *Draft) SetContent(text string, imageURL string, maxLength int) error {
+	if err := validateTextUpTo(text, maxLength); err != nil {
+		return err
+	}
+	d.Text = NormalizeText(text)
+	d.ImageURL = imageURL
+	return nil
+}
+
+//IsScheduled returns if the draft is published on its own at some time
+func (d Draft) IsScheduled() bool {
+	return d.PublishAt != nil
+}
+
+//IsDue returns if the draft is scheduled to be published by now
+func (d Draft) IsDue(now time.Time) bool {
+	return d.IsScheduled() && !d.PublishAt.After(now)
+}
+
+//String returns a formatted string of the Draft
+func (d Draft) String() string {
+	formattedString := fmt.Sprintf("[%d] %s", d.ID, d.Text)
+	if d.ImageURL != "" {
+		formattedString += "\n" + d.ImageURL
+	}
+	if d.IsScheduled() {
+		formattedString += fmt.Sprintf("\n  scheduled for %s", d.PublishAt.Format("2006-01-02 15:04"))
+	}
+	if d.Error != "" {
+		formattedString += fmt.Sprintf("\n  couldn't be published: %s", d.Error)
+	}
+	return formattedString
+}
@@ -0,0 +1,46 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/utility"
+)
+
+func TestDraftCantBeLongerThanATweet(t *testing.T) {
+	//Initialization
+	text := strings.Repeat("a", domain.DefaultMaxTweetLength+1)
+
+	//Operation
+	_, err := domain.NewDraft(1, domain.NewUser("root", ""), text, "", time.Now(), domain.DefaultMaxTweetLength)
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Can't have more than 140 characters")
+}
+
+func TestScheduledDraftIsDueAtItsTime(t *testing.T) {
+	//Initialization
+	saved := time.Date(2018, time.March, 1, 12, 0, 0, 0, time.UTC)
+	draft, err := domain.NewDraft(1, domain.User{ID: 1, Name: "root", Password: "root"}, "hola", "http://img", saved, domain.DefaultMaxTweetLength)
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	publishAt := saved.Add(time.Hour)
+
+	//Operation
+	draft.PublishAt = &publishAt
+
+	//Validation
+	if draft.Author.Password != "" {
+		t.Error("The draft should not keep the password of its author")
+	}
+	if draft.IsDue(saved) || !draft.IsDue(publishAt) || !draft.IsDue(publishAt.Add(time.Minute)) {
+		t.Error("The draft should be due once its time comes")
+	}
+	if draft.String() != "[1] hola\nhttp://img\n  scheduled for 2018-03-01 13:00" {
+		t.Errorf("Unexpected draft %s", draft)
+	}
+}
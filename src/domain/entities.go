@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+//hashtagPattern and mentionPattern match a #hashtag or an @mention that doesn't start in the middle of a word
+var (
+	hashtagPattern = regexp.MustCompile(`(?:^|[^\pL\pN_&])#([\pL\pN_]+)`)
+	mentionPattern = regexp.MustCompile(`(?:^|[^\pL\pN_])@([\pL\pN_]+)`)
+)
+
+//ExtractHashtags returns the hashtags of a text, lowercased and without the #, each once and in the order they appear.
+//Hashtags made only of numbers are not taken as such
+func ExtractHashtags(text string) []string {
+	var hashtags []string
+	for _, tag := range extract(hashtagPattern, text) {
+		tag = NormalizeHashtag(tag)
+		if hasLetter(tag) && !contains(hashtags, tag) {
+			hashtags = append(hashtags, tag)
+		}
+	}
+	return hashtags
+}
+
+//ExtractMentions returns the names of the users mentioned in a text, without the @, each once and in the order they appear
+func ExtractMentions(text string) []string {
+	var mentions []string
+	for _, name := range extract(mentionPattern, text) {
+		if !contains(mentions, name) {
+			mentions = append(mentions, name)
+		}
+	}
+	return mentions
+}
+
+//NormalizeHashtag returns a hashtag as it is indexed, lowercased and without the #
+func NormalizeHashtag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(tag, "#"))
+}
+
+func extract(pattern *regexp.Regexp, text string) []string {
+	var found []string
+	for _, match := range pattern.FindAllStringSubmatch(text, -1) {
+		found = append(found, match[1])
+	}
+	return found
+}
+
+func hasLetter(text string) bool {
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, other := range values {
+		if other == value {
+			return true
+		}
+	}
+	return false
+}
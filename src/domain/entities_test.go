@@ -0,0 +1,47 @@
+package domain_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cursoGo/src/domain"
+)
+
+func TestHashtagsAreExtractedLowercasedAndOnce(t *testing.T) {
+	//Initialization
+	text := "#Go es lindo, #go y #Programación #2018 mail#no"
+
+	//Operation
+	hashtags := domain.ExtractHashtags(text)
+
+	//Validation
+	expected := []string{"go", "programación"}
+	if !reflect.DeepEqual(hashtags, expected) {
+		t.Errorf("Expected %v but was %v", expected, hashtags)
+	}
+}
+
+func TestMentionsAreExtractedOnce(t *testing.T) {
+	//Initialization
+	text := "@gonza y @manu, @gonza: escribanme a root@example.com"
+
+	//Operation
+	mentions := domain.ExtractMentions(text)
+
+	//Validation
+	expected := []string{"gonza", "manu"}
+	if !reflect.DeepEqual(mentions, expected) {
+		t.Errorf("Expected %v but was %v", expected, mentions)
+	}
+}
+
+func TestTextWithoutEntitiesHasNone(t *testing.T) {
+	//Operation
+	hashtags := domain.ExtractHashtags("just text")
+	mentions := domain.ExtractMentions("just text")
+
+	//Validation
+	if len(hashtags) != 0 || len(mentions) != 0 {
+		t.Errorf("Expected no entities but got %v and %v", hashtags, mentions)
+	}
+}
@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+//IDGenerator gives the IDs of new tweets. Implementations are safe for concurrent use
+type IDGenerator interface {
+	NextID() int
+}
+
+//IDRestorer is an IDGenerator that has to be told about the IDs that were given in another run,
+//so that it doesn't give them again
+type IDRestorer interface {
+	Restore(id int)
+}
+
+//SequentialIDGenerator gives consecutive IDs, starting from 0
+type SequentialIDGenerator struct {
+	last int64
+}
+
+//NewSequentialIDGenerator returns a SequentialIDGenerator that hasn't given any ID yet
+func NewSequentialIDGenerator() *SequentialIDGenerator {
+	return &SequentialIDGenerator{last: -1}
+}
+
+//NextID returns the ID that follows the last one
+func (g *SequentialIDGenerator) NextID() int {
+	return int(atomic.AddInt64(&g.last, 1))
+}
+
+//LastID returns the last ID given, or -1 if none was
+func (g *SequentialIDGenerator) LastID() int {
+	return int(atomic.LoadInt64(&g.last))
+}
+
+//Reset makes the generator start again from 0
+func (g *SequentialIDGenerator) Reset() {
+	atomic.StoreInt64(&g.last, -1)
+}
+
+//Restore makes sure that the next IDs are greater than a given one
+func (g *SequentialIDGenerator) Restore(id int) {
+	for {
+		last := atomic.LoadInt64(&g.last)
+		if int64(id) <= last || atomic.CompareAndSwapInt64(&g.last, last, int64(id)) {
+			return
+		}
+	}
+}
+
+//Layout of a snowflake ID: milliseconds since SnowflakeEpoch, then the node, then a sequence number
+const (
+	snowflakeNodeBits     = 10
+	snowflakeSequenceBits = 12
+	snowflakeMaxNode      = 1<<snowflakeNodeBits - 1
+	snowflakeMaxSequence  = 1<<snowflakeSequenceBits - 1
+)
+
+//SnowflakeEpoch is the time from which snowflake IDs count milliseconds
+var SnowflakeEpoch = time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+//SnowflakeIDGenerator gives 64 bit IDs that are ordered by the time at which they were given,
+//and that don't clash with the ones given by generators of other nodes
+type SnowflakeIDGenerator struct {
+	clock    Clock
+	node     int64
+	millis   int64
+	sequence int64
+	mutex    sync.Mutex
+}
+
+//NewSnowflakeIDGenerator returns a SnowflakeIDGenerator for a node between 0 and 1023
+func NewSnowflakeIDGenerator(clock Clock, node int) *SnowflakeIDGenerator {
+	return &SnowflakeIDGenerator{clock: clock, node: int64(node & snowflakeMaxNode), millis: -1}
+}
+
+//NewRandomIDGenerator returns a SnowflakeIDGenerator for a random node
+func NewRandomIDGenerator(clock Clock) *SnowflakeIDGenerator {
+	var node [2]byte
+	rand.Read(node[:])
+	return NewSnowflakeIDGenerator(clock, int(binary.BigEndian.Uint16(node[:])))
+}
+
+//NextID returns an ID greater than the ones given before.
+//When the clock goes back, or too many IDs are asked for in the same millisecond,
+//it keeps counting from the last millisecond used
+func (g *SnowflakeIDGenerator) NextID() int {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	millis := int64(g.clock.Now().Sub(SnowflakeEpoch) / time.Millisecond)
+	if millis > g.millis {
+		g.millis = millis
+		g.sequence = 0
+	} else {
+		g.sequence = (g.sequence + 1) & snowflakeMaxSequence
+		if g.sequence == 0 {
+			g.millis++
+		}
+	}
+	return int(g.millis<<(snowflakeNodeBits+snowflakeSequenceBits) |
+		g.node<<snowflakeSequenceBits |
+		g.sequence)
+}
+
+//SnowflakeTime returns the time, to the millisecond, at which a snowflake ID was given
+func SnowflakeTime(id int) time.Time {
+	millis := int64(id) >> (snowflakeNodeBits + snowflakeSequenceBits)
+	return SnowflakeEpoch.Add(time.Duration(millis) * time.Millisecond)
+}
+
+//FixedIDGenerator gives a known list of IDs, used for testing.
+//Once they run out, it keeps counting from the last one
+type FixedIDGenerator struct {
+	ids   []int
+	last  int
+	mutex sync.Mutex
+}
+
+//NewFixedIDGenerator returns a FixedIDGenerator that gives ids in order
+func NewFixedIDGenerator(ids ...int) *FixedIDGenerator {
+	return &FixedIDGenerator{ids: ids, last: -1}
+}
+
+//NextID returns the next of the known IDs
+func (g *FixedIDGenerator) NextID() int {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if len(g.ids) == 0 {
+		g.last++
+		return g.last
+	}
+	g.last = g.ids[0]
+	g.ids = g.ids[1:]
+	return g.last
+}
@@ -0,0 +1,177 @@
+package domain_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cursoGo/src/domain"
+)
+
+func TestSequentialIDGeneratorStartsAtZero(t *testing.T) {
+	//Initialization
+	ids := domain.NewSequentialIDGenerator()
+
+	//Operation
+	first := ids.NextID()
+	second := ids.NextID()
+
+	//Validation
+	if first != 0 || second != 1 {
+		t.Errorf("Expected IDs 0 and 1 but got %d and %d", first, second)
+	}
+	if ids.LastID() != 1 {
+		t.Errorf("Expected last ID 1 but was %d", ids.LastID())
+	}
+}
+
+func TestSequentialIDGeneratorRestoresGreaterIDsOnly(t *testing.T) {
+	//Initialization
+	ids := domain.NewSequentialIDGenerator()
+	ids.Restore(10)
+
+	//Operation
+	ids.Restore(3)
+	next := ids.NextID()
+
+	//Validation
+	if next != 11 {
+		t.Errorf("Expected ID 11 but was %d", next)
+	}
+}
+
+func TestSequentialIDGeneratorDoesntRepeatIDsConcurrently(t *testing.T) {
+	//Initialization
+	ids := domain.NewSequentialIDGenerator()
+	given := make(chan int, 1000)
+
+	//Operation
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				given <- ids.NextID()
+			}
+		}()
+	}
+	wg.Wait()
+	close(given)
+
+	//Validation
+	seen := make(map[int]bool)
+	for id := range given {
+		if seen[id] {
+			t.Errorf("ID %d was given twice", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestSnowflakeIDsAreOrderedByTime(t *testing.T) {
+	//Initialization
+	clock := domain.NewFixedClock(time.Date(2018, time.March, 1, 12, 0, 0, 0, time.UTC))
+	ids := domain.NewSnowflakeIDGenerator(clock, 7)
+
+	//Operation
+	first := ids.NextID()
+	second := ids.NextID()
+	clock.Advance(time.Second)
+	third := ids.NextID()
+
+	//Validation
+	if !(first < second && second < third) {
+		t.Errorf("Expected increasing IDs but got %d, %d and %d", first, second, third)
+	}
+	if !domain.SnowflakeTime(third).Equal(clock.Now()) {
+		t.Errorf("Expected the ID to tell %s but told %s", clock.Now(), domain.SnowflakeTime(third))
+	}
+}
+
+func TestSnowflakeIDsDontRepeatWhenTheClockGoesBack(t *testing.T) {
+	//Initialization
+	clock := domain.NewFixedClock(time.Date(2018, time.March, 1, 12, 0, 0, 0, time.UTC))
+	ids := domain.NewSnowflakeIDGenerator(clock, 7)
+	first := ids.NextID()
+
+	//Operation
+	clock.Advance(-time.Minute)
+	second := ids.NextID()
+
+	//Validation
+	if second <= first {
+		t.Errorf("Expected an ID greater than %d but was %d", first, second)
+	}
+}
+
+func TestSnowflakeIDsOfDifferentNodesDontClash(t *testing.T) {
+	//Initialization
+	clock := domain.NewFixedClock(time.Date(2018, time.March, 1, 12, 0, 0, 0, time.UTC))
+	firstNode := domain.NewSnowflakeIDGenerator(clock, 1)
+	secondNode := domain.NewSnowflakeIDGenerator(clock, 2)
+
+	//Operation
+	first := firstNode.NextID()
+	second := secondNode.NextID()
+
+	//Validation
+	if first == second {
+		t.Errorf("Both nodes gave ID %d", first)
+	}
+}
+
+func TestFixedIDGeneratorGivesKnownIDs(t *testing.T) {
+	//Initialization
+	ids := domain.NewFixedIDGenerator(42, 7)
+
+	//Operation
+	first := ids.NextID()
+	second := ids.NextID()
+	third := ids.NextID()
+
+	//Validation
+	if first != 42 || second != 7 || third != 8 {
+		t.Errorf("Expected IDs 42, 7 and 8 but got %d, %d and %d", first, second, third)
+	}
+}
+
+func TestFixedClockOnlyMovesWhenTold(t *testing.T) {
+	//Initialization
+	start := time.Date(2018, time.March, 1, 12, 0, 0, 0, time.UTC)
+	clock := domain.NewFixedClock(start)
+
+	//Operation
+	before := clock.Now()
+	clock.Advance(time.Hour)
+
+	//Validation
+	if !before.Equal(start) {
+		t.Errorf("Expected %s but was %s", start, before)
+	}
+	if !clock.Now().Equal(start.Add(time.Hour)) {
+		t.Errorf("Expected %s but was %s", start.Add(time.Hour), clock.Now())
+	}
+}
+
+func TestTweetFactoryUsesItsGeneratorAndClock(t *testing.T) {
+	//Initialization
+	now := time.Date(2018, time.March, 1, 12, 0, 0, 0, time.UTC)
+	tweets := domain.NewTweetFactory(domain.NewFixedIDGenerator(100), domain.NewFixedClock(now))
+	user := domain.NewUser("root", "root")
+
+	//Operation
+	tweet, err := tweets.NewImageTweet(user, "look", "https://google.com.ar")
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if tweet.GetID() != 100 {
+		t.Errorf("Expected ID 100 but was %d", tweet.GetID())
+	}
+	if !tweet.GetDate().Equal(now) {
+		t.Errorf("Expected date %s but was %s", now, tweet.GetDate())
+	}
+}
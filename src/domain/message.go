@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+//MaxMessageLength is how many characters, as counted by TweetLength, the text of a direct message can have
+const MaxMessageLength = 1000
+
+//Message is a private message sent by a user to the other members of a conversation
+type Message struct {
+	ID             int       `json:"id"`
+	ConversationID int       `json:"conversationID"`
+	Sender         User      `json:"sender"`
+	Text           string    `json:"text"`
+	Date           time.Time `json:"date"`
+}
+
+//NewMessage returns a message of sender in a conversation, if its text is valid
+func NewMessage(id int, conversationID int, sender User, text string, date time.Time) (*Message, error) {
+	if err := validateTextUpTo(text, MaxMessageLength); err != nil {
+		return nil, err
+	}
+	return &Message{
+		ID:             id,
+		ConversationID: conversationID,
+		Sender:         User{ID: sender.ID, Name: sender.Name},
+		Text:           NormalizeText(text),
+		Date:           date,
+	}, nil
+}
+
+//String returns a formatted string of the Message
+func (m Message) String() string {
+	return fmt.Sprintf("[%s] @%s: %s", m.Date.Format("2006-01-02 15:04"), m.Sender, m.Text)
+}
@@ -0,0 +1,50 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/utility"
+)
+
+func TestMessagesCanBeLongerThanTweets(t *testing.T) {
+	//Initialization
+	sender := domain.User{ID: 1, Name: "root", Password: "root"}
+	date := time.Date(2018, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	//Operation
+	message, err := domain.NewMessage(1, 1, sender, strings.Repeat("a", 500), date)
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if message.Sender.Password != "" {
+		t.Error("The message should not keep the password of the sender")
+	}
+	if message.String() != "[2018-03-01 12:00] @root: "+strings.Repeat("a", 500) {
+		t.Errorf("Unexpected message %s", message)
+	}
+}
+
+func TestMessageCantBeTooLong(t *testing.T) {
+	//Initialization
+	text := strings.Repeat("a", domain.MaxMessageLength+1)
+
+	//Operation
+	_, err := domain.NewMessage(1, 1, domain.NewUser("root", ""), text, time.Now())
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Can't have more than 1000 characters")
+}
+
+func TestMessageCantBeEmpty(t *testing.T) {
+	//Operation
+	_, err := domain.NewMessage(1, 1, domain.NewUser("root", ""), "", time.Now())
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Can't have no text")
+}
@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+//NotificationKind is what happened for a user to be notified
+type NotificationKind string
+
+//Kinds of notification
+const (
+	FollowNotification  NotificationKind = "follow"
+	MentionNotification NotificationKind = "mention"
+	ReplyNotification   NotificationKind = "reply"
+	QuoteNotification   NotificationKind = "quote"
+	LikeNotification    NotificationKind = "like"
+	RetweetNotification NotificationKind = "retweet"
+	ApproveNotification NotificationKind = "approve"
+)
+
+//Notification tells a user that another one, the actor, did something that involves them.
+//TweetID is the mention, reply or quote that the actor published, or the tweet of the user that they liked or retweeted.
+//Follows and approved follow requests have no tweet
+type Notification struct {
+	ID        int              `json:"id"`
+	Kind      NotificationKind `json:"kind"`
+	Recipient int              `json:"recipient"`
+	Actor     User             `json:"actor"`
+	TweetID   int              `json:"tweetID,omitempty"`
+	Date      time.Time        `json:"date"`
+	Read      bool             `json:"read"`
+}
+
+//NewNotification returns an unread notification for the user with the recipient ID, keeping only the ID and name of actor
+func NewNotification(kind NotificationKind, recipient int, actor User, tweetID int, date time.Time) Notification {
+	return Notification{
+		Kind:      kind,
+		Recipient: recipient,
+		Actor:     User{ID: actor.ID, Name: actor.Name},
+		TweetID:   tweetID,
+		Date:      date,
+	}
+}
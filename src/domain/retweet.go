@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+//ErrCantEditRetweet is returned when trying to change the text of a retweet
+var ErrCantEditRetweet = errors.New("Can't edit a retweet")
+
+//Retweet is a tweet of another user shared again by a user, without adding any text.
+//It has its own ID and date, the ones of the moment it was retweeted
+type Retweet struct {
+	user      User
+	date      *time.Time
+	id        int
+	retweeted Tweeter
+}
+
+//GetRetweeted returns the tweet that was retweeted
+func (t *Retweet) GetRetweeted() Tweeter {
+	return t.retweeted
+}
+
+//GetUser returns the user that retweeted
+func (t *Retweet) GetUser() User {
+	return t.user
+}
+
+//GetDate returns the date at which the tweet was retweeted
+func (t *Retweet) GetDate() *time.Time {
+	return t.date
+}
+
+//GetID returns the ID of the retweet
+func (t *Retweet) GetID() int {
+	return t.id
+}
+
+//GetText returns the text of the retweeted tweet
+func (t *Retweet) GetText() string {
+	return t.retweeted.GetText()
+}
+
+//SetText fails, as retweets have no text of their own
+func (t *Retweet) SetText(string) error {
+	return ErrCantEditRetweet
+}
+
+//GetCounters returns the counters of the retweeted tweet
+func (t *Retweet) GetCounters() Counters {
+	return t.retweeted.GetCounters()
+}
+
+//SetCounters changes the counters of the retweeted tweet
+func (t *Retweet) SetCounters(counters Counters) {
+	t.retweeted.SetCounters(counters)
+}
+
+//String returns a formatted string of the Retweet, saying who retweeted
+func (t *Retweet) String() string {
+	formattedString := fmt.Sprintf("@%s retweeted\n%s", t.user, t.retweeted)
+	return formattedString
+}
+
+//Equals returns if a given Retweet is the same as another
+func (t *Retweet) Equals(other Tweeter) bool {
+	castedTweet, castOk := other.(*Retweet)
+	if !castOk {
+		return false
+	}
+	return (t.id == castedTweet.id &&
+		t.user.Equals(castedTweet.user) &&
+		t.retweeted.GetID() == castedTweet.retweeted.GetID())
+}
+
+//GetOriginal returns the tweet that a retweet shares, or the tweet itself if it isn't a retweet
+func GetOriginal(tweet Tweeter) Tweeter {
+	if retweet, ok := tweet.(*Retweet); ok {
+		return retweet.retweeted
+	}
+	return tweet
+}
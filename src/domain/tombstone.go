@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+//Tombstone is what is left of a deleted tweet that had replies, so that they can still be
+//followed back to the tweets the deleted one answered
+type Tombstone struct {
+	ID       int       `json:"id"`
+	User     User      `json:"user"`
+	Date     time.Time `json:"date"`
+	ParentID *int      `json:"parentID,omitempty"`
+}
+
+//NewTombstone returns the tombstone of a tweet
+func NewTombstone(tweet Tweeter) Tombstone {
+	tombstone := Tombstone{ID: tweet.GetID(), User: NewUser(tweet.GetUser().Name, ""), Date: *tweet.GetDate()}
+	if parentID, ok := GetParentID(tweet); ok {
+		tombstone.ParentID = &parentID
+	}
+	return tombstone
+}
+
+//String returns a formatted string of the Tombstone
+func (t Tombstone) String() string {
+	return fmt.Sprintf("[%d] This tweet was deleted", t.ID)
+}
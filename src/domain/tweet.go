@@ -1,26 +1,45 @@
 package domain
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 )
 
-var currentID = -1
+//Errors returned when a tweet is not valid
+var (
+	ErrEmptyText       = errors.New("Can't have no text")
+	ErrTextTooLong     = errors.New("Can't have more than 140 characters")
+	ErrMissingImageURL = errors.New("Cant create an image tweet without an URL")
+)
+
+//currentIDs gives the IDs of the tweets created without a TweetFactory
+var currentIDs = NewSequentialIDGenerator()
 
-//getNextID returns the id of the next tweet
-func getNextID() int {
-	currentID++
-	return (currentID)
+//defaultTweetFactory creates the tweets of NewTextTweet, NewImageTweet and NewQuoteTweet
+var defaultTweetFactory = NewTweetFactory(currentIDs, SystemClock{})
+
+//DefaultIDGenerator returns the generator shared by the tweets created without a TweetFactory
+func DefaultIDGenerator() *SequentialIDGenerator {
+	return currentIDs
 }
 
 //GetCurrentID returns the id of the last tweet
 func GetCurrentID() int {
-	return currentID
+	return currentIDs.LastID()
 }
 
 //ResetCurrentID serves as an initialization, resetting the current ID
 func ResetCurrentID() {
-	currentID = -1
+	currentIDs.Reset()
+}
+
+//RestoreCurrentID makes sure that the next tweets get IDs greater than a given one,
+//used after loading tweets that were created in another run
+func RestoreCurrentID(id int) {
+	currentIDs.Restore(id)
 }
 
 //Tweeter is an interface that defines a tweet
@@ -32,25 +51,37 @@ type Tweeter interface {
 	GetDate() *time.Time
 	GetText() string
 	SetText(string) error
+	GetCounters() Counters
+	SetCounters(Counters)
 }
 
-//TextTweet is a tweet that has just text
+//TextTweet is a tweet that has just text.
+//Its text can be edited while others read it, so it is guarded by mutex, as are
+//the other parts of the tweets that embed it that can change
 type TextTweet struct {
-	user User
-	date *time.Time
-	id   int
-	text string
+	user     User
+	date     *time.Time
+	id       int
+	text     string
+	counters Counters
+	//maxLength is how many characters its text can have, or 0 for DefaultMaxTweetLength
+	maxLength int
+	mutex     sync.RWMutex
 }
 
 //NewTextTweet returns a new TextTweet
 func NewTextTweet(usr User, txt string) (*TextTweet, error) {
-	now := time.Now()
-	textTweet := TextTweet{user: usr, date: &now, id: getNextID()}
-	err := textTweet.SetText(txt) //Invalid tweet texts handled at SetText
-	if err != nil {
-		return nil, fmt.Errorf(err.Error())
-	}
-	return &textTweet, nil
+	return defaultTweetFactory.NewTextTweet(usr, txt)
+}
+
+//init fills a new tweet whose text can have up to maxLength characters.
+//It keeps the user without its password, as tweets are public
+func (t *TextTweet) init(id int, date time.Time, usr User, txt string, maxLength int) error {
+	t.user = usr.Public()
+	t.date = &date
+	t.id = id
+	t.maxLength = maxLength
+	return t.SetText(txt) //Invalid tweet texts handled at SetText
 }
 
 //GetUser returns the user that posted the tweet
@@ -70,24 +101,55 @@ func (t *TextTweet) GetID() int {
 
 //GetText returns the text of the text tweet
 func (t *TextTweet) GetText() string {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
 	return t.text
 }
 
-//SetText changes the text of a given tweet
+//SetText changes the text of a given tweet, normalized with NormalizeText. It can have as many
+//characters as the TweetFactory that created the tweet allowed, or DefaultMaxTweetLength if it was restored
 func (t *TextTweet) SetText(newText string) error {
-	if newText == "" {
-		return fmt.Errorf("Can't have no text")
+	limit := t.maxLength
+	if limit == 0 {
+		limit = DefaultMaxTweetLength
 	}
-	if len(newText) > 140 {
-		return fmt.Errorf("Can't have more than 140 characters")
+	return t.setTextUpTo(newText, limit)
+}
+
+//setTextUpTo changes the text of a given tweet, if it has up to limit characters
+func (t *TextTweet) setTextUpTo(newText string, limit int) error {
+	err := validateTextUpTo(newText, limit)
+	if err != nil {
+		return err
 	}
-	t.text = newText
+	t.restoreText(newText)
 	return nil
 }
 
+//restoreText changes the text of a given tweet without checking it
+func (t *TextTweet) restoreText(newText string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.text = NormalizeText(newText)
+}
+
+//GetCounters returns how many times the tweet was liked and retweeted
+func (t *TextTweet) GetCounters() Counters {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return t.counters
+}
+
+//SetCounters changes how many times the tweet was liked and retweeted
+func (t *TextTweet) SetCounters(counters Counters) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.counters = counters
+}
+
 func (t *TextTweet) String() string {
 	//date := tw.Date.Format("Mon Jan _2 15:04:05 2006")
-	formattedString := fmt.Sprintf("[%d] @%s: %s", t.id, t.user, t.text)
+	formattedString := fmt.Sprintf("[%d] @%s: %s%s", t.id, t.user, t.GetText(), t.GetCounters())
 	return formattedString
 }
 
@@ -96,7 +158,7 @@ func (t *TextTweet) Equals(other Tweeter) bool {
 	return (t.date == other.GetDate() &&
 		t.id == other.GetID() &&
 		t.user.Equals(other.GetUser()) &&
-		t.text == other.GetText())
+		t.GetText() == other.GetText())
 }
 
 //ImageTweet is a tweet that contains an image
@@ -107,17 +169,7 @@ type ImageTweet struct {
 
 //NewImageTweet returns a new ImageTweet
 func NewImageTweet(user User, text string, url string) (*ImageTweet, error) {
-	if url == "" {
-		return nil, fmt.Errorf("Cant create an image tweet without an URL")
-	}
-
-	textTweet, err := NewTextTweet(user, text)
-	if err != nil {
-		return nil, fmt.Errorf("Couldn't create ImageTweet, %s", err.Error())
-	}
-
-	imageTweet := ImageTweet{TextTweet: *textTweet, imageURL: url}
-	return &imageTweet, nil
+	return defaultTweetFactory.NewImageTweet(user, text, url)
 }
 
 //GetURL returns the URL of the imageTweet
@@ -141,30 +193,63 @@ func (t *ImageTweet) Equals(other Tweeter) bool {
 		t.imageURL == castedTweet.imageURL)
 }
 
-//QuoteTweet is a tweet that quotes another
+//MaxQuoteDepth is how many nested quotes are shown when a QuoteTweet is printed
+const MaxQuoteDepth = 3
+
+//QuoteTweet is a tweet that quotes another. It shows the quoted tweet as it is now,
+//so edits to it are seen in the quote, and once it is deleted only its ID is left
 type QuoteTweet struct {
 	TextTweet
 	quotedTweet Tweeter
+	quotedID    int
 }
 
 //NewQuoteTweet returns a new QuoteTweet
 func NewQuoteTweet(user User, text string, quoted Tweeter) (*QuoteTweet, error) {
-	textTweet, err := NewTextTweet(user, text)
-	if err != nil {
-		return nil, fmt.Errorf("Couldn't create QuoteTweet, %s", err.Error())
-	}
-	quoteTweet := QuoteTweet{TextTweet: *textTweet, quotedTweet: quoted}
-	return &quoteTweet, nil
+	return defaultTweetFactory.NewQuoteTweet(user, text, quoted)
 }
 
-//GetQuotedTweet returns the quotedtweet of the QuoteTweet
+//GetQuotedTweet returns the quotedtweet of the QuoteTweet, or nil if it was deleted
 func (t *QuoteTweet) GetQuotedTweet() Tweeter {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
 	return t.quotedTweet
 }
 
-//String returns a formatted string of the QuoteTweet
+//GetQuotedID returns the ID of the quoted tweet, which is kept after it is deleted
+func (t *QuoteTweet) GetQuotedID() int {
+	return t.quotedID
+}
+
+//RemoveQuotedTweet forgets the quoted tweet, once it was deleted
+func (t *QuoteTweet) RemoveQuotedTweet() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.quotedTweet = nil
+}
+
+//String returns a formatted string of the QuoteTweet,
+//with the quoted tweets under it up to MaxQuoteDepth
 func (t *QuoteTweet) String() string {
-	formattedString := fmt.Sprintf("%s %q", &t.TextTweet, t.quotedTweet)
+	return t.format(MaxQuoteDepth)
+}
+
+//format returns the QuoteTweet showing depth levels of quoted tweets, each line of them starting with "> "
+func (t *QuoteTweet) format(depth int) string {
+	var quoted string
+	switch quotedTweet := t.GetQuotedTweet(); {
+	case quotedTweet == nil:
+		quoted = fmt.Sprintf("[%d] This tweet was deleted", t.quotedID)
+	case depth <= 1:
+		quoted = fmt.Sprintf("[%d] ...", t.quotedID)
+	default:
+		if quote, ok := quotedTweet.(*QuoteTweet); ok {
+			quoted = quote.format(depth - 1)
+		} else {
+			quoted = quotedTweet.String()
+		}
+	}
+	formattedString := fmt.Sprintf("%s\n> %s", &t.TextTweet, strings.Replace(quoted, "\n", "\n> ", -1))
 	return formattedString
 }
 
@@ -175,5 +260,52 @@ func (t *QuoteTweet) Equals(other Tweeter) bool {
 		return false
 	}
 	return (t.TextTweet.Equals(&castedTweet.TextTweet) &&
-		t.quotedTweet.Equals(castedTweet.quotedTweet))
+		t.quotedID == castedTweet.quotedID)
+}
+
+//ReplyTweet is a tweet that answers another one, its parent.
+//It only keeps the parent's ID, as the parent can be deleted while its replies stay
+type ReplyTweet struct {
+	TextTweet
+	parentID int
+}
+
+//NewReplyTweet returns a new ReplyTweet
+func NewReplyTweet(user User, text string, parent Tweeter) (*ReplyTweet, error) {
+	return defaultTweetFactory.NewReplyTweet(user, text, parent)
+}
+
+//NewRetweet returns a new Retweet of a tweet by user
+func NewRetweet(user User, retweeted Tweeter) *Retweet {
+	return defaultTweetFactory.NewRetweet(user, retweeted)
+}
+
+//GetParentID returns the ID of the tweet that the ReplyTweet answers
+func (t *ReplyTweet) GetParentID() int {
+	return t.parentID
+}
+
+//String returns a formatted string of the ReplyTweet
+func (t *ReplyTweet) String() string {
+	formattedString := fmt.Sprintf("%s (reply to [%d])", &t.TextTweet, t.parentID)
+	return formattedString
+}
+
+//Equals returns if a given ReplyTweet is the same as another
+func (t *ReplyTweet) Equals(other Tweeter) bool {
+	castedTweet, castOk := other.(*ReplyTweet)
+	if !castOk {
+		return false
+	}
+	return (t.TextTweet.Equals(&castedTweet.TextTweet) &&
+		t.parentID == castedTweet.parentID)
+}
+
+//GetParentID returns the ID of the tweet that a tweet answers, if it is a reply
+func GetParentID(tweet Tweeter) (int, bool) {
+	reply, ok := tweet.(*ReplyTweet)
+	if !ok {
+		return 0, false
+	}
+	return reply.GetParentID(), true
 }
@@ -0,0 +1,99 @@
+package domain
+
+import "fmt"
+
+//TweetFactory creates tweets taking their IDs from an IDGenerator and their dates from a Clock
+type TweetFactory struct {
+	ids       IDGenerator
+	clock     Clock
+	maxLength int
+}
+
+//TweetFactoryOption changes how a TweetFactory creates tweets
+type TweetFactoryOption func(*TweetFactory)
+
+//WithMaxTweetLength makes the text of the tweets of a TweetFactory have up to limit characters,
+//as counted by TweetLength, instead of DefaultMaxTweetLength
+func WithMaxTweetLength(limit int) TweetFactoryOption {
+	return func(f *TweetFactory) {
+		f.maxLength = limit
+	}
+}
+
+//NewTweetFactory returns a TweetFactory that uses the given generator and clock
+func NewTweetFactory(ids IDGenerator, clock Clock, options ...TweetFactoryOption) *TweetFactory {
+	f := &TweetFactory{ids: ids, clock: clock, maxLength: DefaultMaxTweetLength}
+	for _, option := range options {
+		option(f)
+	}
+	return f
+}
+
+//MaxTweetLength returns how many characters the text of the tweets of the factory can have
+func (f *TweetFactory) MaxTweetLength() int {
+	return f.maxLength
+}
+
+//SetText changes the text of a tweet, which can have as many characters as the tweets of the factory.
+//Retweets can't change their text
+func (f *TweetFactory) SetText(tweet Tweeter, text string) error {
+	if textTweet, ok := tweet.(interface{ setTextUpTo(string, int) error }); ok {
+		return textTweet.setTextUpTo(text, f.maxLength)
+	}
+	return tweet.SetText(text)
+}
+
+//NewTextTweet returns a new TextTweet
+func (f *TweetFactory) NewTextTweet(user User, text string) (*TextTweet, error) {
+	var textTweet TextTweet
+	err := textTweet.init(f.ids.NextID(), f.clock.Now(), user, text, f.maxLength)
+	if err != nil {
+		return nil, err
+	}
+	return &textTweet, nil
+}
+
+//NewImageTweet returns a new ImageTweet
+func (f *TweetFactory) NewImageTweet(user User, text string, url string) (*ImageTweet, error) {
+	if url == "" {
+		return nil, ErrMissingImageURL
+	}
+
+	imageTweet := ImageTweet{imageURL: url}
+	err := imageTweet.init(f.ids.NextID(), f.clock.Now(), user, text, f.maxLength)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't create ImageTweet, %w", err)
+	}
+	return &imageTweet, nil
+}
+
+//NewQuoteTweet returns a new QuoteTweet
+func (f *TweetFactory) NewQuoteTweet(user User, text string, quoted Tweeter) (*QuoteTweet, error) {
+	quoteTweet := QuoteTweet{quotedTweet: quoted, quotedID: quoted.GetID()}
+	err := quoteTweet.init(f.ids.NextID(), f.clock.Now(), user, text, f.maxLength)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't create QuoteTweet, %w", err)
+	}
+	return &quoteTweet, nil
+}
+
+//NewReplyTweet returns a new ReplyTweet that answers parent
+func (f *TweetFactory) NewReplyTweet(user User, text string, parent Tweeter) (*ReplyTweet, error) {
+	replyTweet := ReplyTweet{parentID: parent.GetID()}
+	err := replyTweet.init(f.ids.NextID(), f.clock.Now(), user, text, f.maxLength)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't create ReplyTweet, %w", err)
+	}
+	return &replyTweet, nil
+}
+
+//NewRetweet returns a new Retweet of a tweet by user. Retweeting a retweet shares its original tweet
+func (f *TweetFactory) NewRetweet(user User, retweeted Tweeter) *Retweet {
+	date := f.clock.Now()
+	return &Retweet{
+		user:      user.Public(),
+		date:      &date,
+		id:        f.ids.NextID(),
+		retweeted: GetOriginal(retweeted),
+	}
+}
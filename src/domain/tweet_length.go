@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"unicode"
+
+	"github.com/rivo/uniseg"
+	"golang.org/x/text/unicode/norm"
+)
+
+//ErrInvisibleCharacters is returned when the text of a tweet has control or invisible characters
+var ErrInvisibleCharacters = errors.New("Can't have control or invisible characters")
+
+//DefaultMaxTweetLength is how many characters, as counted by TweetLength, the text of a tweet can have,
+//unless its TweetFactory was made WithMaxTweetLength
+const DefaultMaxTweetLength = 140
+
+//URLLength is how many characters every URL counts as, whatever its length, as it would once shortened
+const URLLength = 23
+
+//urlPattern matches the URLs of a text
+var urlPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s]+`)
+
+//textTooLongError is ErrTextTooLong, telling the limit that was in place
+type textTooLongError struct {
+	limit int
+}
+
+func (e textTooLongError) Error() string {
+	return fmt.Sprintf("Can't have more than %d characters", e.limit)
+}
+
+func (e textTooLongError) Is(target error) bool {
+	return target == ErrTextTooLong
+}
+
+//NormalizeText returns the text of a tweet in NFC, so that the same characters are always written the same way
+func NormalizeText(text string) string {
+	return norm.NFC.String(text)
+}
+
+//TweetLength returns how many characters a text counts as in a tweet. Characters are the ones that users see,
+//so an accented letter or an emoji made of many code points count as one, and URLs count as URLLength
+func TweetLength(text string) int {
+	text = NormalizeText(text)
+	length := 0
+	last := 0
+	for _, url := range urlPattern.FindAllStringIndex(text, -1) {
+		length += uniseg.GraphemeClusterCount(text[last:url[0]]) + URLLength
+		last = url[1]
+	}
+	return length + uniseg.GraphemeClusterCount(text[last:])
+}
+
+//validateTextUpTo returns why a text that can have up to limit characters isn't valid, if it isn't
+func validateTextUpTo(text string, limit int) error {
+	if text == "" {
+		return ErrEmptyText
+	}
+	for _, r := range text {
+		if isInvisible(r) {
+			return ErrInvisibleCharacters
+		}
+	}
+	if TweetLength(text) > limit {
+		return textTooLongError{limit: limit}
+	}
+	return nil
+}
+
+//isInvisible returns if a character is a control or format character that can't be seen.
+//New lines and tabs are allowed, as are the joiners and tags that make up emoji
+func isInvisible(r rune) bool {
+	switch {
+	case r == '\n' || r == '\t':
+		return false
+	case r == '\u200d' || (r >= '\U000e0020' && r <= '\U000e007f'):
+		return false
+	}
+	return unicode.Is(unicode.Cc, r) || unicode.Is(unicode.Cf, r)
+}
@@ -0,0 +1,131 @@
+package domain_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/utility"
+)
+
+func TestTweetLengthCountsAccentedLettersOnce(t *testing.T) {
+	//Initialization
+	text := strings.Repeat("á", 140)
+
+	//Operation
+	tweet, err := domain.NewTextTweet(domain.NewUser("root", "root"), text)
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if domain.TweetLength(tweet.GetText()) != 140 {
+		t.Errorf("Expected 140 characters but got %d", domain.TweetLength(tweet.GetText()))
+	}
+}
+
+func TestTweetLengthCountsEmojiOnce(t *testing.T) {
+	//Initialization
+	family := "\U0001F468\u200d\U0001F469\u200d\U0001F467"
+	flag := "\U0001F1E6\U0001F1F7"
+	thumbs := "\U0001F44D\U0001F3FD"
+
+	//Operation
+	length := domain.TweetLength(family + flag + thumbs)
+
+	//Validation
+	if length != 3 {
+		t.Errorf("Expected 3 characters but got %d", length)
+	}
+}
+
+func TestTweetLengthCountsDecomposedLettersOnce(t *testing.T) {
+	//Operation
+	length := domain.TweetLength("cancio\u0301n")
+
+	//Validation
+	if length != 7 {
+		t.Errorf("Expected 7 characters but got %d", length)
+	}
+}
+
+func TestTweetLengthCountsURLsWithFixedLength(t *testing.T) {
+	//Initialization
+	url := "https://example.com/" + strings.Repeat("a", 200)
+
+	//Operation
+	length := domain.TweetLength("mirá " + url + " y www.golang.org")
+
+	//Validation
+	expected := len("mira ") + domain.URLLength + len(" y ") + domain.URLLength
+	if length != expected {
+		t.Errorf("Expected %d characters but got %d", expected, length)
+	}
+}
+
+func TestTweetTextIsNormalized(t *testing.T) {
+	//Operation
+	tweet, _ := domain.NewTextTweet(domain.NewUser("root", "root"), "cancio\u0301n")
+
+	//Validation
+	if tweet.GetText() != "canci\u00f3n" {
+		t.Errorf("Expected the text in NFC but was %q", tweet.GetText())
+	}
+}
+
+func TestCantCreateTweetWithInvisibleCharacters(t *testing.T) {
+	for _, text := range []string{"hola\u200bmundo", "hola\x07", "\u202ehola"} {
+		//Operation
+		_, err := domain.NewTextTweet(domain.NewUser("root", "root"), text)
+
+		//Validation
+		utility.ValidateExpectedError(t, err, "Can't have control or invisible characters")
+	}
+}
+
+func TestTweetsCanHaveNewLines(t *testing.T) {
+	//Operation
+	_, err := domain.NewTextTweet(domain.NewUser("root", "root"), "hola\nmundo")
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+	}
+}
+
+func TestMaxTweetLengthIsAnOptionOfTheFactory(t *testing.T) {
+	//Initialization
+	factory := domain.NewTweetFactory(domain.NewSequentialIDGenerator(), domain.SystemClock{}, domain.WithMaxTweetLength(10))
+
+	//Operation
+	_, err := factory.NewTextTweet(domain.NewUser("root", "root"), "más de diez")
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Can't have more than 10 characters")
+	if !errors.Is(err, domain.ErrTextTooLong) {
+		t.Error("Expected ErrTextTooLong")
+	}
+	if _, err := domain.NewTextTweet(domain.NewUser("root", "root"), "más de diez"); err != nil {
+		t.Errorf("Other factories should keep the default limit, %s", err.Error())
+	}
+}
+
+func TestTweetsOfFactoryCanBeEditedUpToItsLimit(t *testing.T) {
+	//Initialization
+	factory := domain.NewTweetFactory(domain.NewSequentialIDGenerator(), domain.SystemClock{}, domain.WithMaxTweetLength(200))
+	tweet, _ := factory.NewTextTweet(domain.NewUser("root", "root"), "hola")
+	restored, _ := domain.NewTextTweet(domain.NewUser("root", "root"), "hola")
+	text := strings.Repeat("a", 150)
+
+	//Operation
+	err := tweet.SetText(text)
+	restoredErr := factory.SetText(restored, text)
+
+	//Validation
+	if err != nil || restoredErr != nil {
+		t.Errorf("Unexpected errors, %v and %v", err, restoredErr)
+	}
+	utility.ValidateExpectedError(t, factory.SetText(tweet, strings.Repeat("a", 201)), "Can't have more than 200 characters")
+}
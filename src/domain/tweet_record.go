@@ -0,0 +1,176 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+//Kinds of tweets a TweetRecord can hold
+const (
+	TextTweetType  = "text"
+	ImageTweetType = "image"
+	QuoteTweetType = "quote"
+	ReplyTweetType = "reply"
+	RetweetType    = "retweet"
+)
+
+//GetTweetType returns the kind of a tweet, as it is kept in its TweetRecord
+func GetTweetType(tweet Tweeter) string {
+	switch tweet.(type) {
+	case *Retweet:
+		return RetweetType
+	case *QuoteTweet:
+		return QuoteTweetType
+	case *ReplyTweet:
+		return ReplyTweetType
+	case *ImageTweet:
+		return ImageTweetType
+	}
+	return TextTweetType
+}
+
+//TweetRecord is a plain copy of a tweet that can be saved and restored later
+type TweetRecord struct {
+	Type      string       `json:"type"`
+	ID        int          `json:"id"`
+	User      User         `json:"user"`
+	Date      time.Time    `json:"date"`
+	Text      string       `json:"text"`
+	ImageURL  string       `json:"imageURL,omitempty"`
+	Quoted    *TweetRecord `json:"quoted,omitempty"`
+	QuotedID  *int         `json:"quotedID,omitempty"`
+	ParentID  *int         `json:"parentID,omitempty"`
+	Retweeted *TweetRecord `json:"retweeted,omitempty"`
+}
+
+//NewTweetRecord returns the record of a given tweet
+func NewTweetRecord(tweet Tweeter) (TweetRecord, error) {
+	var record TweetRecord
+	switch t := tweet.(type) {
+	case *QuoteTweet:
+		record = newTextTweetRecord(&t.TextTweet, QuoteTweetType)
+		record.QuotedID = &t.quotedID
+		if quotedTweet := t.GetQuotedTweet(); quotedTweet != nil {
+			quoted, err := NewTweetRecord(quotedTweet)
+			if err != nil {
+				return record, err
+			}
+			record.Quoted = &quoted
+		}
+	case *Retweet:
+		retweeted, err := NewTweetRecord(t.retweeted)
+		if err != nil {
+			return record, err
+		}
+		record = TweetRecord{
+			Type:      RetweetType,
+			ID:        t.id,
+			User:      recordUser(t.user),
+			Date:      *t.date,
+			Retweeted: &retweeted,
+		}
+	case *ReplyTweet:
+		record = newTextTweetRecord(&t.TextTweet, ReplyTweetType)
+		record.ParentID = &t.parentID
+	case *ImageTweet:
+		record = newTextTweetRecord(&t.TextTweet, ImageTweetType)
+		record.ImageURL = t.imageURL
+	case *TextTweet:
+		record = newTextTweetRecord(t, TextTweetType)
+	default:
+		return record, fmt.Errorf("Unknown kind of tweet %T", tweet)
+	}
+	return record, nil
+}
+
+func newTextTweetRecord(t *TextTweet, tweetType string) TweetRecord {
+	return TweetRecord{
+		Type: tweetType,
+		ID:   t.id,
+		User: recordUser(t.user),
+		Date: *t.date,
+		Text: t.GetText(),
+	}
+}
+
+//recordUser returns the ID and name of the author of a tweet, leaving out its password and follows
+func recordUser(user User) User {
+	return User{ID: user.ID, Name: user.Name}
+}
+
+//RestoreText changes the text of a tweet to one it had before, as when replaying its edits.
+//The text isn't checked again, as it was when it was first set
+func RestoreText(tweet Tweeter, text string) error {
+	if textTweet, ok := tweet.(interface{ restoreText(string) }); ok {
+		textTweet.restoreText(text)
+		return nil
+	}
+	return tweet.SetText(text)
+}
+
+//Restore rebuilds the tweet of the record, keeping its ID and date.
+//Quoted and retweeted tweets are first looked up with find, so that they can be shared with
+//tweets that were already restored, and are only rebuilt when find returns nil
+func (r TweetRecord) Restore(find func(id int) Tweeter) (Tweeter, error) {
+	switch r.Type {
+	case TextTweetType:
+		var textTweet TextTweet
+		r.restoreInto(&textTweet)
+		return &textTweet, nil
+	case ImageTweetType:
+		imageTweet := ImageTweet{imageURL: r.ImageURL}
+		r.restoreInto(&imageTweet.TextTweet)
+		return &imageTweet, nil
+	case ReplyTweetType:
+		if r.ParentID == nil {
+			return nil, fmt.Errorf("Reply tweet %d has no parent", r.ID)
+		}
+		replyTweet := ReplyTweet{parentID: *r.ParentID}
+		r.restoreInto(&replyTweet.TextTweet)
+		return &replyTweet, nil
+	case QuoteTweetType:
+		if r.Quoted == nil {
+			//The quoted tweet was deleted
+			if r.QuotedID == nil {
+				return nil, fmt.Errorf("Quote tweet %d has no quoted tweet", r.ID)
+			}
+			quoteTweet := QuoteTweet{quotedID: *r.QuotedID}
+			r.restoreInto(&quoteTweet.TextTweet)
+			return &quoteTweet, nil
+		}
+		quoted := find(r.Quoted.ID)
+		if quoted == nil {
+			var err error
+			quoted, err = r.Quoted.Restore(find)
+			if err != nil {
+				return nil, err
+			}
+		}
+		quoteTweet := QuoteTweet{quotedTweet: quoted, quotedID: quoted.GetID()}
+		r.restoreInto(&quoteTweet.TextTweet)
+		return &quoteTweet, nil
+	case RetweetType:
+		if r.Retweeted == nil {
+			return nil, fmt.Errorf("Retweet %d has no retweeted tweet", r.ID)
+		}
+		retweeted := find(r.Retweeted.ID)
+		if retweeted == nil {
+			var err error
+			retweeted, err = r.Retweeted.Restore(find)
+			if err != nil {
+				return nil, err
+			}
+		}
+		date := r.Date
+		return &Retweet{user: r.User, date: &date, id: r.ID, retweeted: retweeted}, nil
+	}
+	return nil, fmt.Errorf("Unknown kind of tweet %q", r.Type)
+}
+
+func (r TweetRecord) restoreInto(t *TextTweet) {
+	date := r.Date
+	t.user = r.User
+	t.date = &date
+	t.id = r.ID
+	t.text = r.Text
+}
@@ -1,6 +1,8 @@
 package domain_test
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/cursoGo/src/utility"
@@ -82,8 +84,8 @@ func TestCanCompareTwoImageTweets(t *testing.T) {
 	secondTweet, _ := domain.NewImageTweet(user, text, secondURL)
 
 	//Operation
-	firstResult := firstTweet.Equals(*firstTweet)
-	secondResult := secondTweet.Equals(*firstTweet)
+	firstResult := firstTweet.Equals(firstTweet)
+	secondResult := secondTweet.Equals(firstTweet)
 
 	//Validation
 	if !firstResult {
@@ -119,8 +121,8 @@ func TestCanCompareTwoQuoteTweets(t *testing.T) {
 	secondQuoteTweet, _ := domain.NewQuoteTweet(user, text, secondTweetToBeQuoted)
 
 	//Operation
-	firstResult := firstQuoteTweet.Equals(*firstQuoteTweet)
-	secondResult := secondQuoteTweet.Equals(*firstQuoteTweet)
+	firstResult := firstQuoteTweet.Equals(firstQuoteTweet)
+	secondResult := secondQuoteTweet.Equals(firstQuoteTweet)
 
 	//Validation
 	if !firstResult {
@@ -130,3 +132,200 @@ func TestCanCompareTwoQuoteTweets(t *testing.T) {
 		t.Error("Second result should be false")
 	}
 }
+
+func TestReplyTweetKeepsItsParentID(t *testing.T) {
+	//Initialization
+	user := domain.NewUser("root", "root")
+	parent, _ := domain.NewTextTweet(user, "question")
+
+	//Operation
+	reply, err := domain.NewReplyTweet(user, "answer", parent)
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	parentID, ok := domain.GetParentID(reply)
+	if !ok || parentID != parent.GetID() {
+		t.Errorf("Expected parent %d but was %d", parent.GetID(), parentID)
+	}
+	if _, ok := domain.GetParentID(parent); ok {
+		t.Error("A text tweet should not have a parent")
+	}
+}
+
+func TestReplyTweetCanBeRestoredFromItsRecord(t *testing.T) {
+	//Initialization
+	user := domain.NewUser("root", "root")
+	parent, _ := domain.NewTextTweet(user, "question")
+	reply, _ := domain.NewReplyTweet(user, "answer", parent)
+	record, _ := domain.NewTweetRecord(reply)
+
+	//Operation
+	restored, err := record.Restore(func(int) domain.Tweeter { return nil })
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	restoredReply, ok := restored.(*domain.ReplyTweet)
+	if !ok || restoredReply.GetParentID() != parent.GetID() || restoredReply.GetText() != "answer" {
+		t.Errorf("Reply tweet was not restored, got %s", restored)
+	}
+}
+
+func TestNestedQuotesAreShownUpToMaxDepth(t *testing.T) {
+	//Initialization
+	user := domain.NewUser("root", "root")
+	var tweet domain.Tweeter
+	tweet, _ = domain.NewTextTweet(user, "original")
+	for i := 0; i < domain.MaxQuoteDepth+1; i++ {
+		tweet, _ = domain.NewQuoteTweet(user, "quoting", tweet)
+	}
+
+	//Operation
+	lines := strings.Split(tweet.String(), "\n")
+
+	//Validation
+	if len(lines) != domain.MaxQuoteDepth+1 {
+		t.Errorf("Expected %d lines but got %d:\n%s", domain.MaxQuoteDepth+1, len(lines), tweet)
+		return
+	}
+	last := lines[len(lines)-1]
+	if !strings.HasPrefix(last, strings.Repeat("> ", domain.MaxQuoteDepth)) || !strings.HasSuffix(last, "...") {
+		t.Errorf("Unexpected last line %q", last)
+	}
+}
+
+func TestQuoteOfDeletedTweetShowsItWasDeleted(t *testing.T) {
+	//Initialization
+	user := domain.NewUser("root", "root")
+	quoted, _ := domain.NewTextTweet(user, "quote me")
+	quote, _ := domain.NewQuoteTweet(user, "nice", quoted)
+
+	//Operation
+	quote.RemoveQuotedTweet()
+
+	//Validation
+	expected := fmt.Sprintf("> [%d] This tweet was deleted", quoted.GetID())
+	if !strings.HasSuffix(quote.String(), expected) || quote.GetQuotedID() != quoted.GetID() {
+		t.Errorf("Unexpected quote %s", quote)
+	}
+}
+
+func TestRetweetShowsWhoRetweeted(t *testing.T) {
+	//Initialization
+	author := domain.NewUser("root", "root")
+	retweeter := domain.NewUser("gonza", "hunter3")
+	original, _ := domain.NewTextTweet(author, "share me")
+
+	//Operation
+	retweet := domain.NewRetweet(retweeter, original)
+
+	//Validation
+	expected := fmt.Sprintf("@gonza retweeted\n%s", original)
+	if retweet.String() != expected {
+		t.Errorf("Expected %q but was %q", expected, retweet.String())
+	}
+	if retweet.GetID() == original.GetID() || retweet.GetText() != original.GetText() {
+		t.Errorf("Unexpected retweet %s", retweet)
+	}
+}
+
+func TestRetweetOfRetweetSharesTheOriginal(t *testing.T) {
+	//Initialization
+	author := domain.NewUser("root", "root")
+	original, _ := domain.NewTextTweet(author, "share me")
+	retweet := domain.NewRetweet(domain.NewUser("gonza", "hunter3"), original)
+
+	//Operation
+	again := domain.NewRetweet(domain.NewUser("manu", "hunter2"), retweet)
+
+	//Validation
+	if again.GetRetweeted() != original {
+		t.Errorf("Expected a retweet of %s but got %s", original, again)
+	}
+}
+
+func TestCantEditRetweet(t *testing.T) {
+	//Initialization
+	original, _ := domain.NewTextTweet(domain.NewUser("root", "root"), "share me")
+	retweet := domain.NewRetweet(domain.NewUser("gonza", "hunter3"), original)
+
+	//Operation
+	err := retweet.SetText("changed")
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Can't edit a retweet")
+}
+
+func TestRetweetCanBeRestoredFromItsRecord(t *testing.T) {
+	//Initialization
+	original, _ := domain.NewTextTweet(domain.NewUser("root", "root"), "share me")
+	retweet := domain.NewRetweet(domain.NewUser("gonza", "hunter3"), original)
+	record, _ := domain.NewTweetRecord(retweet)
+
+	//Operation
+	restored, err := record.Restore(func(int) domain.Tweeter { return original })
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if !restored.Equals(retweet) || restored.(*domain.Retweet).GetRetweeted() != original {
+		t.Errorf("Retweet was not restored, got %s", restored)
+	}
+}
+
+func TestRestoredTweetsKeepTheIDOfTheirUsers(t *testing.T) {
+	//Initialization
+	user := domain.User{ID: 7, Name: "gonza", Password: "hunter3"}
+	original, _ := domain.NewTextTweet(domain.User{ID: 3, Name: "root"}, "share me")
+	retweet := domain.NewRetweet(user, original)
+	record, _ := domain.NewTweetRecord(retweet)
+
+	//Operation
+	restored, err := record.Restore(func(int) domain.Tweeter { return nil })
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if restoredUser := restored.GetUser(); restoredUser.ID != 7 || restoredUser.Password != "" {
+		t.Errorf("Expected user 7 without password but got %+v", restoredUser)
+	}
+	if retweeted := restored.(*domain.Retweet).GetRetweeted(); retweeted.GetUser().ID != 3 {
+		t.Errorf("Expected user 3 but got %+v", retweeted.GetUser())
+	}
+}
+
+func TestTweetShowsItsCounters(t *testing.T) {
+	//Initialization
+	tweet, _ := domain.NewTextTweet(domain.NewUser("root", "root"), "count me")
+
+	//Operation
+	tweet.SetCounters(domain.Counters{Likes: 2, Retweets: 1})
+
+	//Validation
+	expected := fmt.Sprintf("[%d] @root: count me (2 likes, 1 retweet)", tweet.GetID())
+	if tweet.String() != expected {
+		t.Errorf("Expected %q but was %q", expected, tweet.String())
+	}
+}
+
+func TestTweetWithoutCountersShowsNone(t *testing.T) {
+	//Initialization
+	tweet, _ := domain.NewTextTweet(domain.NewUser("root", "root"), "count me")
+
+	//Operation
+	tweet.SetCounters(domain.Counters{})
+
+	//Validation
+	if strings.Contains(tweet.String(), "(") {
+		t.Errorf("Expected no counters but was %q", tweet.String())
+	}
+}
@@ -1,9 +1,16 @@
 package domain
 
-//User of tweeter
+//User of tweeter. It is public, so the password is only set on the users given
+//when registering or logging in: what gets stored are the user's Credentials.
+//The ID is given by the repository when the user is stored, and Following is
+//a copy of who the user followed at the moment it was read.
+//The tweets of Protected users can only be seen by the followers they approve
 type User struct {
-	Name, Password string
-	Following      []User
+	ID        int `json:",omitempty"`
+	Name      string
+	Password  string `json:",omitempty"`
+	Protected bool   `json:",omitempty"`
+	Following []User `json:",omitempty"`
 }
 
 //NewUser Creates a new user
@@ -11,10 +18,15 @@ func NewUser(name, password string) User {
 	return User{Name: name, Password: password}
 }
 
-//Equals returns if two users are the same
+//Public returns the user without its password
+func (u User) Public() User {
+	u.Password = ""
+	return u
+}
+
+//Equals returns if two users are the same. Names are unique, so they are enough
 func (u User) Equals(other User) bool {
-	return (other.Name == u.Name &&
-		other.Password == u.Password)
+	return other.Name == u.Name
 }
 
 //String returns the user as a printable string (just the name)
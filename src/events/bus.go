@@ -0,0 +1,221 @@
+package events
+
+import (
+	"log"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+//Handler is given the events published to a Bus that it is subscribed to
+type Handler func(Event)
+
+//Overflow is what an asynchronous subscription does with a new event when its buffer is full
+type Overflow int
+
+const (
+	//Block makes the publisher wait until the subscriber makes room, slowing it down to the pace of the subscriber
+	Block Overflow = iota
+	//DropNewest discards the new event, keeping the ones that were already waiting
+	DropNewest
+	//DropOldest discards the event that waited the most to make room for the new one
+	DropOldest
+)
+
+//Bus delivers the events published to it to every subscriber, in the order they subscribed.
+//Synchronous subscribers are run by the publisher before Publish returns, while asynchronous
+//ones have a buffer and a goroutine of their own. It is safe for concurrent use
+type Bus struct {
+	subscriptions []*Subscription
+	mutex         sync.RWMutex
+	running       sync.WaitGroup
+}
+
+//NewBus returns a Bus without subscribers
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+//Subscription is the subscription of a Handler to a Bus
+type Subscription struct {
+	bus      *Bus
+	handler  Handler
+	async    bool
+	overflow Overflow
+	events   chan Event
+	done     chan struct{}
+	once     sync.Once
+	//drain tells the goroutine of an asynchronous subscription to handle the events left in its buffer before stopping
+	drain   bool
+	dropped int64
+}
+
+//Subscribe subscribes handler to the events of the bus, running it synchronously on every Publish
+func (b *Bus) Subscribe(handler Handler) *Subscription {
+	subscription := &Subscription{bus: b, handler: handler, done: make(chan struct{})}
+	b.add(subscription)
+	return subscription
+}
+
+//SubscribeAsync subscribes handler to the events of the bus, running it on a goroutine of its own.
+//Up to buffer events wait for it to handle them, and overflow says what happens when there are more
+func (b *Bus) SubscribeAsync(handler Handler, buffer int, overflow Overflow) *Subscription {
+	if buffer < 1 {
+		buffer = 1
+	}
+	subscription := &Subscription{
+		bus:      b,
+		handler:  handler,
+		async:    true,
+		overflow: overflow,
+		events:   make(chan Event, buffer),
+		done:     make(chan struct{}),
+	}
+	b.running.Add(1)
+	go subscription.run()
+	b.add(subscription)
+	return subscription
+}
+
+func (b *Bus) add(subscription *Subscription) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.subscriptions = append(b.subscriptions, subscription)
+}
+
+//Subscribers returns how many subscribers the bus has
+func (b *Bus) Subscribers() int {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return len(b.subscriptions)
+}
+
+//Publish delivers an event to every subscriber. The bus isn't locked while delivering,
+//so handlers can publish, subscribe and unsubscribe
+func (b *Bus) Publish(event Event) {
+	b.mutex.RLock()
+	subscriptions := append([]*Subscription(nil), b.subscriptions...)
+	b.mutex.RUnlock()
+	for _, subscription := range subscriptions {
+		subscription.deliver(event)
+	}
+}
+
+//Close unsubscribes every subscriber, waiting for the asynchronous ones to handle the events left in their buffers.
+//It must not be called from a handler
+func (b *Bus) Close() {
+	b.mutex.Lock()
+	subscriptions := b.subscriptions
+	b.subscriptions = nil
+	b.mutex.Unlock()
+	for _, subscription := range subscriptions {
+		subscription.stop(true)
+	}
+	b.running.Wait()
+}
+
+//Unsubscribe stops the delivery of events to the subscriber. The events that an asynchronous one
+//had in its buffer are discarded, though the one it was handling, if any, is finished
+func (s *Subscription) Unsubscribe() {
+	s.bus.mutex.Lock()
+	for i, subscription := range s.bus.subscriptions {
+		if subscription == s {
+			s.bus.subscriptions = append(s.bus.subscriptions[:i:i], s.bus.subscriptions[i+1:]...)
+			break
+		}
+	}
+	s.bus.mutex.Unlock()
+	s.stop(false)
+}
+
+func (s *Subscription) stop(drain bool) {
+	s.once.Do(func() {
+		s.drain = drain
+		close(s.done)
+	})
+}
+
+//Dropped returns how many events an asynchronous subscriber lost because its buffer was full
+func (s *Subscription) Dropped() int {
+	return int(atomic.LoadInt64(&s.dropped))
+}
+
+//Pending returns how many events are waiting in the buffer of an asynchronous subscriber
+func (s *Subscription) Pending() int {
+	return len(s.events)
+}
+
+//deliver gives an event to the subscriber, or to its buffer if it is asynchronous
+func (s *Subscription) deliver(event Event) {
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+	if !s.async {
+		s.handle(event)
+		return
+	}
+	switch s.overflow {
+	case DropNewest:
+		select {
+		case s.events <- event:
+		default:
+			atomic.AddInt64(&s.dropped, 1)
+		}
+	case DropOldest:
+		for {
+			select {
+			case s.events <- event:
+				return
+			default:
+			}
+			select {
+			case <-s.events:
+				atomic.AddInt64(&s.dropped, 1)
+			default:
+			}
+		}
+	default:
+		select {
+		case s.events <- event:
+		case <-s.done:
+		}
+	}
+}
+
+//run handles the events of an asynchronous subscriber until it is stopped
+func (s *Subscription) run() {
+	defer s.bus.running.Done()
+	for {
+		select {
+		case <-s.done:
+			for s.drain {
+				select {
+				case event := <-s.events:
+					s.handle(event)
+				default:
+					return
+				}
+			}
+			return
+		default:
+		}
+		select {
+		case event := <-s.events:
+			s.handle(event)
+		case <-s.done:
+		}
+	}
+}
+
+//handle runs the handler on an event. A handler that panics doesn't take the publisher nor the bus down with it,
+//but the panic is logged
+func (s *Subscription) handle(event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Handler panicked with %s event, %v\n%s", event.Kind(), r, debug.Stack())
+		}
+	}()
+	s.handler(event)
+}
@@ -0,0 +1,221 @@
+package events_test
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/events"
+)
+
+//registered returns a UserRegistered event of the user with that name
+func registered(name string) events.Event {
+	return events.UserRegistered{User: domain.NewUser(name, "")}
+}
+
+//names returns the names of the users of UserRegistered events
+func names(received []events.Event) []string {
+	var names []string
+	for _, event := range received {
+		names = append(names, event.(events.UserRegistered).User.Name)
+	}
+	return names
+}
+
+//expectNames fails the test if the names aren't the expected ones, in order
+func expectNames(t *testing.T, got []string, expected ...string) {
+	if len(got) != len(expected) {
+		t.Errorf("Expected %v but got %v", expected, got)
+		return
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Expected %v but got %v", expected, got)
+			return
+		}
+	}
+}
+
+func TestSynchronousSubscribersGetEventsBeforePublishReturns(t *testing.T) {
+	//Initialization
+	bus := events.NewBus()
+	var first, second []events.Event
+	bus.Subscribe(func(event events.Event) { first = append(first, event) })
+	bus.Subscribe(func(event events.Event) { second = append(second, event) })
+
+	//Operation
+	bus.Publish(registered("manu"))
+	bus.Publish(registered("gonza"))
+
+	//Validation
+	expectNames(t, names(first), "manu", "gonza")
+	expectNames(t, names(second), "manu", "gonza")
+	if first[0].Kind() != "UserRegistered" {
+		t.Errorf("Unexpected kind %s", first[0].Kind())
+	}
+}
+
+func TestUnsubscribedHandlersGetNoEvents(t *testing.T) {
+	//Initialization
+	bus := events.NewBus()
+	var received []events.Event
+	subscription := bus.Subscribe(func(event events.Event) { received = append(received, event) })
+	bus.Publish(registered("manu"))
+
+	//Operation
+	subscription.Unsubscribe()
+	bus.Publish(registered("gonza"))
+
+	//Validation
+	expectNames(t, names(received), "manu")
+}
+
+func TestPanickingHandlerDoesNotStopOthers(t *testing.T) {
+	//Initialization
+	bus := events.NewBus()
+	var received []events.Event
+	bus.Subscribe(func(event events.Event) { panic("broken subscriber") })
+	bus.Subscribe(func(event events.Event) { received = append(received, event) })
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	//Operation
+	bus.Publish(registered("manu"))
+
+	//Validation
+	expectNames(t, names(received), "manu")
+	if !strings.Contains(logged.String(), "Handler panicked with UserRegistered event, broken subscriber") {
+		t.Errorf("Expected the panic to be logged but got %q", logged.String())
+	}
+}
+
+//collector is an asynchronous handler that keeps what it gets, waiting for release before handling anything
+type collector struct {
+	release  chan struct{}
+	received []events.Event
+	mutex    sync.Mutex
+}
+
+func newCollector() *collector {
+	return &collector{release: make(chan struct{})}
+}
+
+func (c *collector) handle(event events.Event) {
+	<-c.release
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.received = append(c.received, event)
+}
+
+func (c *collector) names() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return names(c.received)
+}
+
+func TestAsynchronousSubscribersDontMakePublisherWait(t *testing.T) {
+	//Initialization
+	bus := events.NewBus()
+	collector := newCollector()
+	bus.SubscribeAsync(collector.handle, 10, events.Block)
+
+	//Operation
+	bus.Publish(registered("manu"))
+	bus.Publish(registered("gonza"))
+	close(collector.release)
+	bus.Close()
+
+	//Validation
+	expectNames(t, collector.names(), "manu", "gonza")
+}
+
+func TestDropNewestKeepsWhatWasWaiting(t *testing.T) {
+	//Initialization
+	bus := events.NewBus()
+	collector := newCollector()
+	subscription := bus.SubscribeAsync(collector.handle, 1, events.DropNewest)
+
+	//Operation
+	bus.Publish(registered("manu"))
+	waitUntil(t, func() bool { return subscription.Pending() == 0 })
+	bus.Publish(registered("gonza"))
+	bus.Publish(registered("root"))
+	close(collector.release)
+	bus.Close()
+
+	//Validation
+	expectNames(t, collector.names(), "manu", "gonza")
+	if subscription.Dropped() != 1 {
+		t.Errorf("Expected 1 dropped event but got %d", subscription.Dropped())
+	}
+}
+
+func TestDropOldestKeepsTheLatestEvents(t *testing.T) {
+	//Initialization
+	bus := events.NewBus()
+	collector := newCollector()
+	subscription := bus.SubscribeAsync(collector.handle, 2, events.DropOldest)
+
+	//Operation
+	bus.Publish(registered("manu"))
+	waitUntil(t, func() bool { return subscription.Pending() == 0 })
+	for _, name := range []string{"gonza", "root", "ana", "beto"} {
+		bus.Publish(registered(name))
+	}
+	close(collector.release)
+	bus.Close()
+
+	//Validation
+	expectNames(t, collector.names(), "manu", "ana", "beto")
+	if subscription.Dropped() != 2 {
+		t.Errorf("Expected 2 dropped events but got %d", subscription.Dropped())
+	}
+}
+
+func TestBlockMakesPublisherWaitForRoom(t *testing.T) {
+	//Initialization
+	bus := events.NewBus()
+	collector := newCollector()
+	subscription := bus.SubscribeAsync(collector.handle, 1, events.Block)
+	bus.Publish(registered("manu"))
+	waitUntil(t, func() bool { return subscription.Pending() == 0 })
+	bus.Publish(registered("gonza"))
+
+	//Operation
+	published := make(chan struct{})
+	go func() {
+		bus.Publish(registered("root"))
+		close(published)
+	}()
+
+	//Validation
+	select {
+	case <-published:
+		t.Error("The publisher should wait while the buffer is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(collector.release)
+	<-published
+	bus.Close()
+	expectNames(t, collector.names(), "manu", "gonza", "root")
+	if subscription.Dropped() != 0 {
+		t.Errorf("Expected no dropped events but got %d", subscription.Dropped())
+	}
+}
+
+//waitUntil waits for condition to be true, failing the test if it takes more than a second
+func waitUntil(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
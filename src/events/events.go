@@ -0,0 +1,51 @@
+package events
+
+import "github.com/cursoGo/src/domain"
+
+//Event is something that happened in the tweeter, published to a Bus
+type Event interface {
+	//Kind returns the name of the event, such as "TweetPublished"
+	Kind() string
+}
+
+//UserRegistered is published when a new user registers
+type UserRegistered struct {
+	User domain.User
+}
+
+//TweetPublished is published when a tweet is published, which may be a reply, a quote or a retweet
+type TweetPublished struct {
+	Tweet domain.Tweeter
+}
+
+//TweetEdited is published when the text of a tweet is changed, holding the tweet as it is after the change
+type TweetEdited struct {
+	Tweet domain.Tweeter
+}
+
+//TweetDeleted is published when a tweet is deleted, including the retweets that are undone
+type TweetDeleted struct {
+	Tweet domain.Tweeter
+}
+
+//UserFollowed is published when a user starts following another one,
+//which for protected users happens when they approve the request
+type UserFollowed struct {
+	Follower domain.User
+	Followed domain.User
+}
+
+//Kind returns "UserRegistered"
+func (UserRegistered) Kind() string { return "UserRegistered" }
+
+//Kind returns "TweetPublished"
+func (TweetPublished) Kind() string { return "TweetPublished" }
+
+//Kind returns "TweetEdited"
+func (TweetEdited) Kind() string { return "TweetEdited" }
+
+//Kind returns "TweetDeleted"
+func (TweetDeleted) Kind() string { return "TweetDeleted" }
+
+//Kind returns "UserFollowed"
+func (UserFollowed) Kind() string { return "UserFollowed" }
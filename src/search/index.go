@@ -0,0 +1,265 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cursoGo/src/domain"
+)
+
+//Index is an inverted index of tweets, mapping every term to the tweets that have it and where.
+//It isn't safe for concurrent use, it is meant to be guarded by whoever stores the tweets
+type Index struct {
+	postings map[string]map[int][]int
+	docs     map[int]document
+}
+
+//document is what the index keeps of a tweet to filter and rank it
+type document struct {
+	author    string
+	date      time.Time
+	tweetType string
+	terms     []string
+}
+
+//Result is a tweet that matched a query, with how relevant it is
+type Result struct {
+	ID    int
+	Score float64
+}
+
+//NewIndex returns an empty Index
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[int][]int),
+		docs:     make(map[int]document),
+	}
+}
+
+//Add indexes a tweet by its current text, replacing what was indexed of it before
+func (i *Index) Add(tweet domain.Tweeter) {
+	i.Remove(tweet.GetID())
+	doc := document{
+		author:    tweet.GetUser().Name,
+		date:      *tweet.GetDate(),
+		tweetType: domain.GetTweetType(tweet),
+	}
+	for _, token := range Tokenize(tweet.GetText()) {
+		positions, ok := i.postings[token.Term]
+		if !ok {
+			positions = make(map[int][]int)
+			i.postings[token.Term] = positions
+		}
+		positions[tweet.GetID()] = append(positions[tweet.GetID()], token.Position)
+		doc.terms = append(doc.terms, token.Term)
+	}
+	i.docs[tweet.GetID()] = doc
+}
+
+//Remove forgets the tweet with that ID
+func (i *Index) Remove(id int) {
+	doc, ok := i.docs[id]
+	if !ok {
+		return
+	}
+	for _, term := range doc.terms {
+		delete(i.postings[term], id)
+		if len(i.postings[term]) == 0 {
+			delete(i.postings, term)
+		}
+	}
+	delete(i.docs, id)
+}
+
+//Size returns how many tweets are indexed
+func (i *Index) Size() int {
+	return len(i.docs)
+}
+
+//Search returns the tweets that match a query, the most relevant first and the newest first among equally relevant ones.
+//Tweets are ranked by how often they have the terms of the query, weighting rare terms more and long tweets less
+func (i *Index) Search(query Query) []Result {
+	var matches map[int]bool
+	if query.expr == nil {
+		matches = i.all()
+	} else {
+		matches = query.expr.match(i)
+	}
+	var terms []string
+	if query.expr != nil {
+		terms = query.expr.terms()
+	}
+
+	results := make([]Result, 0, len(matches))
+	for id := range matches {
+		if query.Filter.allows(i.docs[id]) {
+			results = append(results, Result{ID: id, Score: i.score(id, terms)})
+		}
+	}
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].Score != results[b].Score {
+			return results[a].Score > results[b].Score
+		}
+		dateA, dateB := i.docs[results[a].ID].date, i.docs[results[b].ID].date
+		if !dateA.Equal(dateB) {
+			return dateA.After(dateB)
+		}
+		return results[a].ID > results[b].ID
+	})
+	return results
+}
+
+//score returns the TF-IDF of the terms in the tweet with that ID, normalized by its length
+func (i *Index) score(id int, terms []string) float64 {
+	length := len(i.docs[id].terms)
+	if length == 0 {
+		return 0
+	}
+	score := 0.0
+	for _, term := range terms {
+		frequency := len(i.postings[term][id])
+		if frequency == 0 {
+			continue
+		}
+		idf := math.Log(1 + float64(len(i.docs))/float64(len(i.postings[term])))
+		score += float64(frequency) * idf
+	}
+	return score / math.Sqrt(float64(length))
+}
+
+func (i *Index) all() map[int]bool {
+	all := make(map[int]bool, len(i.docs))
+	for id := range i.docs {
+		all[id] = true
+	}
+	return all
+}
+
+//node is part of the expression of a query
+type node interface {
+	//match returns the IDs of the indexed tweets that match the node
+	match(i *Index) map[int]bool
+	//terms returns the terms that count for ranking, leaving out the excluded ones
+	terms() []string
+}
+
+//newPhrase returns the node that matches the terms one after the other, or nil if there are none
+func newPhrase(tokens []Token) node {
+	switch len(tokens) {
+	case 0:
+		return nil
+	case 1:
+		return termNode(tokens[0].Term)
+	}
+	var phrase phraseNode
+	for _, token := range tokens {
+		phrase = append(phrase, token.Term)
+	}
+	return phrase
+}
+
+type termNode string
+
+func (n termNode) match(i *Index) map[int]bool {
+	matches := make(map[int]bool)
+	for id := range i.postings[string(n)] {
+		matches[id] = true
+	}
+	return matches
+}
+
+func (n termNode) terms() []string {
+	return []string{string(n)}
+}
+
+type phraseNode []string
+
+func (n phraseNode) match(i *Index) map[int]bool {
+	matches := make(map[int]bool)
+	for id, positions := range i.postings[n[0]] {
+		for _, position := range positions {
+			if n.startsAt(i, id, position) {
+				matches[id] = true
+				break
+			}
+		}
+	}
+	return matches
+}
+
+//startsAt returns if the phrase appears in the tweet with that ID from a position
+func (n phraseNode) startsAt(i *Index, id int, position int) bool {
+	for offset, term := range n[1:] {
+		found := false
+		for _, p := range i.postings[term][id] {
+			if p == position+offset+1 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (n phraseNode) terms() []string {
+	return n
+}
+
+type andNode []node
+
+func (n andNode) match(i *Index) map[int]bool {
+	matches := n[0].match(i)
+	for _, child := range n[1:] {
+		childMatches := child.match(i)
+		for id := range matches {
+			if !childMatches[id] {
+				delete(matches, id)
+			}
+		}
+	}
+	return matches
+}
+
+func (n andNode) terms() []string {
+	var terms []string
+	for _, child := range n {
+		terms = append(terms, child.terms()...)
+	}
+	return terms
+}
+
+type orNode []node
+
+func (n orNode) match(i *Index) map[int]bool {
+	matches := make(map[int]bool)
+	for _, child := range n {
+		for id := range child.match(i) {
+			matches[id] = true
+		}
+	}
+	return matches
+}
+
+func (n orNode) terms() []string {
+	return andNode(n).terms()
+}
+
+type notNode struct {
+	child node
+}
+
+func (n notNode) match(i *Index) map[int]bool {
+	matches := i.all()
+	for id := range n.child.match(i) {
+		delete(matches, id)
+	}
+	return matches
+}
+
+func (n notNode) terms() []string {
+	return nil
+}
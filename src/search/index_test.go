@@ -0,0 +1,139 @@
+package search_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/search"
+)
+
+//newTestIndex returns an index with tweets of gonza and manu, a day apart from each other, in the given order
+func newTestIndex(texts ...string) (*search.Index, []domain.Tweeter) {
+	clock := domain.NewFixedClock(time.Date(2018, time.March, 1, 12, 0, 0, 0, time.UTC))
+	factory := domain.NewTweetFactory(domain.NewSequentialIDGenerator(), clock)
+	index := search.NewIndex()
+	var tweets []domain.Tweeter
+	for i, text := range texts {
+		user := domain.NewUser("gonza", "")
+		if i%2 == 1 {
+			user = domain.NewUser("manu", "")
+		}
+		tweet, _ := factory.NewTextTweet(user, text)
+		index.Add(tweet)
+		tweets = append(tweets, tweet)
+		clock.Advance(24 * time.Hour)
+	}
+	return index, tweets
+}
+
+//runQuery parses and runs a query, returning the IDs of the results
+func runQuery(t *testing.T, index *search.Index, text string) []int {
+	query, err := search.ParseQuery(text)
+	if err != nil {
+		t.Fatalf("Unexpected error, %s", err.Error())
+	}
+	var ids []int
+	for _, result := range index.Search(query) {
+		ids = append(ids, result.ID)
+	}
+	return ids
+}
+
+//expectIDs checks that got are the IDs of the expected tweets, in the same order
+func expectIDs(t *testing.T, got []int, expected ...domain.Tweeter) {
+	if len(got) != len(expected) {
+		t.Errorf("Expected %d results but got %v", len(expected), got)
+		return
+	}
+	for i, tweet := range expected {
+		if got[i] != tweet.GetID() {
+			t.Errorf("Expected tweet %d at position %d but got %v", tweet.GetID(), i, got)
+			return
+		}
+	}
+}
+
+func TestWordsMustAllAppear(t *testing.T) {
+	//Initialization
+	index, tweets := newTestIndex("hola mundo", "hola", "mundo feliz")
+
+	//Operation
+	ids := runQuery(t, index, "Mundo HOLA")
+
+	//Validation
+	expectIDs(t, ids, tweets[0])
+}
+
+func TestSearchFoldsAccents(t *testing.T) {
+	//Initialization
+	index, tweets := newTestIndex("una canción", "otra cosa")
+
+	//Operation
+	ids := runQuery(t, index, "cancion")
+
+	//Validation
+	expectIDs(t, ids, tweets[0])
+}
+
+func TestPhraseMustAppearInOrder(t *testing.T) {
+	//Initialization
+	index, tweets := newTestIndex("mundo hola", "hola, mundo", "hola lindo mundo")
+
+	//Operation
+	ids := runQuery(t, index, `"hola mundo"`)
+
+	//Validation
+	expectIDs(t, ids, tweets[1])
+}
+
+func TestCanSearchWithOrAndNot(t *testing.T) {
+	//Initialization
+	index, tweets := newTestIndex("perro", "gato", "perro y gato", "loro")
+
+	//Operation
+	ids := runQuery(t, index, "(perro OR gato) -loro NOT y")
+
+	//Validation
+	expectIDs(t, ids, tweets[1], tweets[0])
+}
+
+func TestResultsAreRankedByRelevance(t *testing.T) {
+	//Initialization
+	index, tweets := newTestIndex("go go go", "aprendiendo go con muchas otras palabras", "nada que ver")
+
+	//Operation
+	ids := runQuery(t, index, "go")
+
+	//Validation
+	expectIDs(t, ids, tweets[0], tweets[1])
+}
+
+func TestCanFilterByAuthorDateAndType(t *testing.T) {
+	//Initialization
+	index, tweets := newTestIndex("hola", "hola", "hola", "hola")
+
+	//Operation
+	byAuthor := runQuery(t, index, "hola from:manu")
+	byDate := runQuery(t, index, "since:2018-03-02 until:2018-03-03")
+	byType := runQuery(t, index, "hola type:image")
+
+	//Validation
+	expectIDs(t, byAuthor, tweets[3], tweets[1])
+	expectIDs(t, byDate, tweets[2], tweets[1])
+	expectIDs(t, byType)
+}
+
+func TestRemovedTweetsAreNotFound(t *testing.T) {
+	//Initialization
+	index, tweets := newTestIndex("hola", "hola")
+
+	//Operation
+	index.Remove(tweets[0].GetID())
+
+	//Validation
+	expectIDs(t, runQuery(t, index, "hola"), tweets[1])
+	if index.Size() != 1 {
+		t.Errorf("Expected 1 tweet but got %d", index.Size())
+	}
+}
@@ -0,0 +1,291 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/cursoGo/src/domain"
+)
+
+//ErrInvalidQuery is returned when a query can't be parsed
+var ErrInvalidQuery = errors.New("Invalid query")
+
+//dateLayout is how dates are written in the since: and until: filters
+const dateLayout = "2006-01-02"
+
+//Filter narrows the tweets that a query can match. Its zero value lets every tweet through
+type Filter struct {
+	//Author is the name of the user that published the tweets, or empty for anyone
+	Author string
+	//Since and Until are the dates that the tweets must be published from and before, if they aren't zero
+	Since time.Time
+	Until time.Time
+	//Types are the kinds of tweets, as in domain.TweetRecord, or empty for every kind
+	Types []string
+}
+
+//allows returns if the filter lets a document through
+func (f Filter) allows(doc document) bool {
+	if f.Author != "" && !strings.EqualFold(f.Author, doc.author) {
+		return false
+	}
+	if !f.Since.IsZero() && doc.date.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !doc.date.Before(f.Until) {
+		return false
+	}
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, tweetType := range f.Types {
+		if tweetType == doc.tweetType {
+			return true
+		}
+	}
+	return false
+}
+
+//Query is a parsed search. Words must all appear in a tweet unless joined by OR, "quoted words" must
+//appear together and in order, NOT or a leading - excludes what follows and parentheses group.
+//The filters from:<user>, since:<yyyy-mm-dd>, until:<yyyy-mm-dd> (inclusive) and type:<kind> are taken
+//out of the text into the Filter
+type Query struct {
+	expr   node
+	Filter Filter
+}
+
+//ParseQuery parses the text of a search
+func ParseQuery(text string) (Query, error) {
+	var query Query
+	lexemes, err := lex(text)
+	if err != nil {
+		return query, err
+	}
+	var rest []lexeme
+	for _, l := range lexemes {
+		isFilter, err := query.Filter.parse(l)
+		if err != nil {
+			return query, err
+		}
+		if !isFilter {
+			rest = append(rest, l)
+		}
+	}
+
+	p := parser{lexemes: rest}
+	query.expr, err = p.parseOr()
+	if err != nil {
+		return query, err
+	}
+	if !p.done() {
+		return query, fmt.Errorf("%w, unexpected %q", ErrInvalidQuery, p.peek().text)
+	}
+	return query, nil
+}
+
+//parse adds the filter of a lexeme to f, returning false if it isn't a filter
+func (f *Filter) parse(l lexeme) (bool, error) {
+	if l.kind != wordLexeme {
+		return false, nil
+	}
+	parts := strings.SplitN(l.text, ":", 2)
+	if len(parts) != 2 {
+		return false, nil
+	}
+	name, value := strings.ToLower(parts[0]), parts[1]
+	switch name {
+	case "from":
+		f.Author = strings.TrimPrefix(value, "@")
+	case "since", "until":
+		date, err := time.Parse(dateLayout, value)
+		if err != nil {
+			return true, fmt.Errorf("%w, %s must be a date like %s", ErrInvalidQuery, name, dateLayout)
+		}
+		if name == "since" {
+			f.Since = date
+		} else {
+			f.Until = date.AddDate(0, 0, 1)
+		}
+	case "type":
+		switch value = strings.ToLower(value); value {
+		case domain.TextTweetType, domain.ImageTweetType, domain.QuoteTweetType, domain.ReplyTweetType:
+			f.Types = append(f.Types, value)
+		default:
+			return true, fmt.Errorf("%w, unknown type %q", ErrInvalidQuery, value)
+		}
+	default:
+		return false, nil
+	}
+	if value == "" {
+		return true, fmt.Errorf("%w, %s can't be empty", ErrInvalidQuery, name)
+	}
+	return true, nil
+}
+
+//Kinds of lexemes of a query
+const (
+	wordLexeme = iota
+	phraseLexeme
+	openLexeme
+	closeLexeme
+	notLexeme
+)
+
+type lexeme struct {
+	kind int
+	text string
+}
+
+//lex splits the text of a query into words, phrases, parentheses and negations
+func lex(text string) ([]lexeme, error) {
+	var lexemes []lexeme
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			lexemes = append(lexemes, lexeme{kind: openLexeme, text: "("})
+			i++
+		case r == ')':
+			lexemes = append(lexemes, lexeme{kind: closeLexeme, text: ")"})
+			i++
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]):
+			lexemes = append(lexemes, lexeme{kind: notLexeme, text: "-"})
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("%w, unclosed phrase", ErrInvalidQuery)
+			}
+			lexemes = append(lexemes, lexeme{kind: phraseLexeme, text: string(runes[i+1 : end])})
+			i = end + 1
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune(`()"`, runes[end]) {
+				end++
+			}
+			word := string(runes[i:end])
+			if word == "NOT" {
+				lexemes = append(lexemes, lexeme{kind: notLexeme, text: word})
+			} else {
+				lexemes = append(lexemes, lexeme{kind: wordLexeme, text: word})
+			}
+			i = end
+		}
+	}
+	return lexemes, nil
+}
+
+//parser builds the expression of a query out of its lexemes
+type parser struct {
+	lexemes []lexeme
+	pos     int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.lexemes)
+}
+
+func (p *parser) peek() lexeme {
+	return p.lexemes[p.pos]
+}
+
+func (p *parser) peekWord(word string) bool {
+	return !p.done() && p.peek().kind == wordLexeme && p.peek().text == word
+}
+
+//parseOr parses expressions joined by OR
+func (p *parser) parseOr() (node, error) {
+	var children []node
+	for {
+		child, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if child != nil {
+			children = append(children, child)
+		}
+		if !p.peekWord("OR") {
+			break
+		}
+		p.pos++
+	}
+	if len(children) == 1 {
+		return children[0], nil
+	}
+	if len(children) == 0 {
+		return nil, nil
+	}
+	return orNode(children), nil
+}
+
+//parseAnd parses expressions next to each other, optionally joined by AND
+func (p *parser) parseAnd() (node, error) {
+	var children []node
+	for !p.done() && p.peek().kind != closeLexeme && !p.peekWord("OR") {
+		if p.peekWord("AND") {
+			p.pos++
+			continue
+		}
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if child != nil {
+			children = append(children, child)
+		}
+	}
+	if len(children) == 1 {
+		return children[0], nil
+	}
+	if len(children) == 0 {
+		return nil, nil
+	}
+	return andNode(children), nil
+}
+
+//parseUnary parses a negated expression or a single one
+func (p *parser) parseUnary() (node, error) {
+	if p.peek().kind != notLexeme {
+		return p.parsePrimary()
+	}
+	p.pos++
+	if p.done() {
+		return nil, fmt.Errorf("%w, nothing to exclude", ErrInvalidQuery)
+	}
+	child, err := p.parseUnary()
+	if err != nil || child == nil {
+		return nil, err
+	}
+	return notNode{child}, nil
+}
+
+//parsePrimary parses a word, a phrase or an expression between parentheses
+func (p *parser) parsePrimary() (node, error) {
+	l := p.peek()
+	p.pos++
+	switch l.kind {
+	case openLexeme:
+		child, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.done() || p.peek().kind != closeLexeme {
+			return nil, fmt.Errorf("%w, unbalanced parentheses", ErrInvalidQuery)
+		}
+		p.pos++
+		return child, nil
+	case closeLexeme:
+		return nil, fmt.Errorf("%w, unbalanced parentheses", ErrInvalidQuery)
+	}
+	return newPhrase(Tokenize(l.text)), nil
+}
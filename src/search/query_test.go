@@ -0,0 +1,60 @@
+package search_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cursoGo/src/search"
+	"github.com/cursoGo/src/utility"
+)
+
+func TestFiltersAreTakenOutOfTheQuery(t *testing.T) {
+	//Operation
+	query, err := search.ParseQuery("hola from:@gonza since:2018-03-01 until:2018-03-02 type:image")
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	filter := query.Filter
+	if filter.Author != "gonza" || len(filter.Types) != 1 || filter.Types[0] != "image" {
+		t.Errorf("Unexpected filter %+v", filter)
+	}
+	if !filter.Since.Equal(time.Date(2018, time.March, 1, 0, 0, 0, 0, time.UTC)) ||
+		!filter.Until.Equal(time.Date(2018, time.March, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected dates %+v", filter)
+	}
+}
+
+func TestCantParseUnclosedPhrase(t *testing.T) {
+	//Operation
+	_, err := search.ParseQuery(`"hola mundo`)
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Invalid query, unclosed phrase")
+}
+
+func TestCantParseUnbalancedParentheses(t *testing.T) {
+	//Operation
+	_, err := search.ParseQuery("(hola OR chau")
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Invalid query, unbalanced parentheses")
+}
+
+func TestCantParseInvalidDate(t *testing.T) {
+	//Operation
+	_, err := search.ParseQuery("since:ayer")
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Invalid query, since must be a date like 2006-01-02")
+}
+
+func TestCantParseUnknownType(t *testing.T) {
+	//Operation
+	_, err := search.ParseQuery("type:video")
+
+	//Validation
+	utility.ValidateExpectedError(t, err, `Invalid query, unknown type "video"`)
+}
@@ -0,0 +1,43 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+//Token is a word of a text, folded as it is indexed, and its position among the words of the text
+type Token struct {
+	Term     string
+	Position int
+}
+
+//Tokenize splits a text into its words, folding their case and accents.
+//Anything that isn't a letter, a number or an underscore separates words, so #hashtags and
+//@mentions are indexed as plain words
+func Tokenize(text string) []Token {
+	var tokens []Token
+	for _, word := range strings.FieldsFunc(text, isSeparator) {
+		if term := Fold(word); term != "" {
+			tokens = append(tokens, Token{Term: term, Position: len(tokens)})
+		}
+	}
+	return tokens
+}
+
+//Fold returns a word lowercased and without accents, so that "Canción" and "cancion" are the same term
+func Fold(word string) string {
+	var folded strings.Builder
+	for _, r := range norm.NFD.String(word) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		folded.WriteRune(unicode.ToLower(r))
+	}
+	return folded.String()
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsNumber(r) && !unicode.Is(unicode.Mn, r) && r != '_'
+}
@@ -0,0 +1,37 @@
+package search_test
+
+import (
+	"testing"
+
+	"github.com/cursoGo/src/search"
+)
+
+func TestTokenizeFoldsCaseAndAccents(t *testing.T) {
+	//Initialization
+	text := "¡Qué CANCIÓN, pingüino! #Año @gonza"
+
+	//Operation
+	tokens := search.Tokenize(text)
+
+	//Validation
+	expected := []string{"que", "cancion", "pinguino", "ano", "gonza"}
+	if len(tokens) != len(expected) {
+		t.Errorf("Expected %d tokens but got %v", len(expected), tokens)
+		return
+	}
+	for i, token := range tokens {
+		if token.Term != expected[i] || token.Position != i {
+			t.Errorf("Expected %q at %d but got %q at %d", expected[i], i, token.Term, token.Position)
+		}
+	}
+}
+
+func TestFoldHandlesDecomposedAccents(t *testing.T) {
+	//Operation
+	folded := search.Fold("Canción")
+
+	//Validation
+	if folded != "cancion" {
+		t.Errorf("Expected cancion but was %q", folded)
+	}
+}
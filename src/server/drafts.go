@@ -0,0 +1,157 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type draftRequest struct {
+	Text     string `json:"text"`
+	ImageURL string `json:"imageURL"`
+}
+
+//scheduleRequest schedules the draft with DraftID, or a new draft with Text and ImageURL if it has none
+type scheduleRequest struct {
+	DraftID   *int      `json:"draftID"`
+	Text      string    `json:"text"`
+	ImageURL  string    `json:"imageURL"`
+	PublishAt time.Time `json:"publishAt"`
+}
+
+func draftID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid draft ID")
+		return 0, false
+	}
+	return id, true
+}
+
+func respondDrafts(c *gin.Context, drafts []domain.Draft) {
+	if drafts == nil {
+		drafts = []domain.Draft{}
+	}
+	c.JSON(http.StatusOK, drafts)
+}
+
+//listDrafts responds with the drafts of the user that aren't scheduled, in the order they were saved
+func (s *Server) listDrafts(c *gin.Context, token string, user domain.User) {
+	drafts, err := s.drafts.GetDrafts(token)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondDrafts(c, drafts)
+}
+
+func (s *Server) saveDraft(c *gin.Context, token string, user domain.User) {
+	var request draftRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondBadRequest(c, "Invalid request")
+		return
+	}
+	draft, err := s.drafts.SaveDraft(token, request.Text, request.ImageURL)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, draft)
+}
+
+func (s *Server) editDraft(c *gin.Context, token string, user domain.User) {
+	id, ok := draftID(c)
+	if !ok {
+		return
+	}
+	var request draftRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondBadRequest(c, "Invalid request")
+		return
+	}
+	draft, err := s.drafts.EditDraft(token, id, request.Text, request.ImageURL)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, draft)
+}
+
+func (s *Server) deleteDraft(c *gin.Context, token string, user domain.User) {
+	id, ok := draftID(c)
+	if !ok {
+		return
+	}
+	err := s.drafts.DeleteDraft(token, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+//publishDraft publishes a draft of the user right away, responding with the published tweet
+func (s *Server) publishDraft(c *gin.Context, token string, user domain.User) {
+	id, ok := draftID(c)
+	if !ok {
+		return
+	}
+	tweet, err := s.drafts.PublishDraft(token, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	response, err := newTweetResponse(tweet)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+//listScheduled responds with the scheduled drafts of the user, the one published first first
+func (s *Server) listScheduled(c *gin.Context, token string, user domain.User) {
+	drafts, err := s.drafts.GetScheduledTweets(token)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondDrafts(c, drafts)
+}
+
+func (s *Server) schedule(c *gin.Context, token string, user domain.User) {
+	var request scheduleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondBadRequest(c, "Invalid request")
+		return
+	}
+	var draft *domain.Draft
+	var err error
+	if request.DraftID != nil {
+		draft, err = s.drafts.ScheduleDraft(token, *request.DraftID, request.PublishAt)
+	} else {
+		draft, err = s.drafts.ScheduleTweet(token, request.Text, request.ImageURL, request.PublishAt)
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, draft)
+}
+
+//unschedule makes a scheduled draft of the user a plain draft again
+func (s *Server) unschedule(c *gin.Context, token string, user domain.User) {
+	id, ok := draftID(c)
+	if !ok {
+		return
+	}
+	_, err := s.drafts.UnscheduleDraft(token, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,59 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/server"
+	"github.com/cursoGo/src/service"
+)
+
+func TestCanSaveScheduleAndPublishDraftsThroughAPI(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	clock := domain.NewFixedClock(time.Date(2018, time.March, 1, 12, 0, 0, 0, time.UTC))
+	manager.InitializeManagerWith(service.NewMemoryTweetRepository(), domain.NewSequentialIDGenerator(), clock)
+	drafts := service.NewDraftManagerWith(&manager, service.NewMemoryDraftRepository(), clock, time.Hour)
+	defer drafts.Close()
+	s := server.NewServerWith(&manager, nil, drafts)
+	token := registerAndLogin(t, s, "manu")
+
+	//Operation
+	recorder := doRequest(s, "POST", "/drafts", token, map[string]string{"text": "borrador"})
+	var draft domain.Draft
+	json.Unmarshal(recorder.Body.Bytes(), &draft)
+	publishAt := clock.Now().Add(time.Hour)
+	scheduled := doRequest(s, "POST", "/scheduled", token, map[string]interface{}{"draftID": draft.ID, "publishAt": publishAt})
+
+	//Validation
+	expectStatus(t, recorder, http.StatusCreated)
+	expectStatus(t, scheduled, http.StatusCreated)
+	var list []domain.Draft
+	json.Unmarshal(doRequest(s, "GET", "/scheduled", token, nil).Body.Bytes(), &list)
+	if len(list) != 1 || list[0].Text != "borrador" || !list[0].PublishAt.Equal(publishAt) {
+		t.Errorf("Unexpected scheduled drafts %+v", list)
+	}
+	past := map[string]interface{}{"text": "tarde", "publishAt": clock.Now().Add(-time.Hour)}
+	expectStatus(t, doRequest(s, "POST", "/scheduled", token, past), http.StatusBadRequest)
+	clock.Advance(time.Hour)
+	drafts.PublishDue()
+	tweets, _ := manager.GetTweetsFromUser(domain.NewUser("manu", ""))
+	if len(tweets) != 1 || tweets[0].GetText() != "borrador" {
+		t.Errorf("Expected the scheduled draft to be published but got %v", tweets)
+	}
+	expectStatus(t, doRequest(s, "DELETE", "/drafts/"+strconv.Itoa(draft.ID), token, nil), http.StatusNotFound)
+}
+
+func TestDraftRoutesNeedDraftManager(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	token := registerAndLogin(t, s, "manu")
+	//Operation
+	recorder := doRequest(s, "GET", "/drafts", token, nil)
+	//Validation
+	expectStatus(t, recorder, http.StatusNotFound)
+}
@@ -0,0 +1,786 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/search"
+	"github.com/cursoGo/src/service"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+//DefaultHeartbeatInterval is how often a stream sends a comment while there are no events, so that proxies keep it open
+const DefaultHeartbeatInterval = 15 * time.Second
+
+//Server is a JSON HTTP API in front of a TweetManager, where every client logs in with its own session
+type Server struct {
+	manager   *service.TweetManager
+	webhooks  *service.WebhookDispatcher
+	drafts    *service.DraftManager
+	router    *gin.Engine
+	heartbeat time.Duration
+}
+
+//NewServer returns a Server that uses the given manager
+func NewServer(manager *service.TweetManager) *Server {
+	return NewServerWithWebhooks(manager, nil)
+}
+
+//NewServerWithWebhooks returns a Server that uses the given manager, where users can add webhooks to the dispatcher
+func NewServerWithWebhooks(manager *service.TweetManager, webhooks *service.WebhookDispatcher) *Server {
+	return NewServerWith(manager, webhooks, nil)
+}
+
+//NewServerWith returns a Server that uses the given manager, where users can add webhooks to the dispatcher
+//and keep drafts and scheduled tweets in drafts. The routes of the ones that are nil aren't served
+func NewServerWith(manager *service.TweetManager, webhooks *service.WebhookDispatcher, drafts *service.DraftManager) *Server {
+	s := &Server{manager: manager, webhooks: webhooks, drafts: drafts, heartbeat: DefaultHeartbeatInterval}
+
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.POST("/users", s.register)
+	router.POST("/sessions", s.login)
+	router.DELETE("/sessions", s.authenticated(s.logout))
+	router.GET("/timeline", s.authenticated(s.timeline))
+	router.GET("/timeline/stream", s.authenticated(s.timelineStream))
+	router.POST("/tweets", s.authenticated(s.publish))
+	router.GET("/tweets/:id", s.tweetByID)
+	router.PUT("/tweets/:id", s.authenticated(s.edit))
+	router.DELETE("/tweets/:id", s.authenticated(s.delete))
+	router.POST("/tweets/:id/retweets", s.authenticated(s.retweet))
+	router.DELETE("/tweets/:id/retweets", s.authenticated(s.unretweet))
+	router.GET("/tweets/:id/likes", s.likes)
+	router.POST("/tweets/:id/likes", s.authenticated(s.like))
+	router.DELETE("/tweets/:id/likes", s.authenticated(s.unlike))
+	router.POST("/tweets/:id/bookmarks", s.authenticated(s.bookmark))
+	router.DELETE("/tweets/:id/bookmarks", s.authenticated(s.removeBookmark))
+	router.GET("/bookmarks", s.authenticated(s.bookmarks))
+	router.GET("/hashtags/:tag", s.hashtag)
+	router.GET("/mentions", s.authenticated(s.mentions))
+	router.GET("/search", s.search)
+	router.POST("/following", s.authenticated(s.follow))
+	router.DELETE("/following/:name", s.authenticated(s.unfollow))
+	router.GET("/users/:name/followers", s.followers)
+	router.GET("/users/:name/following", s.following)
+	router.GET("/blocks", s.authenticated(s.blocked))
+	router.POST("/blocks", s.authenticated(s.block))
+	router.DELETE("/blocks/:name", s.authenticated(s.unblock))
+	router.GET("/mutes", s.authenticated(s.muted))
+	router.POST("/mutes", s.authenticated(s.mute))
+	router.DELETE("/mutes/:name", s.authenticated(s.unmute))
+	router.GET("/users/:name/tweets", s.userTweets)
+	router.PUT("/protection", s.authenticated(s.protect))
+	router.GET("/requests", s.authenticated(s.requests))
+	router.POST("/requests/:name", s.authenticated(s.approve))
+	router.DELETE("/requests/:name", s.authenticated(s.reject))
+	if webhooks != nil {
+		router.GET("/webhooks", s.authenticated(s.listWebhooks))
+		router.POST("/webhooks", s.authenticated(s.addWebhook))
+		router.DELETE("/webhooks/:id", s.authenticated(s.removeWebhook))
+		router.GET("/webhooks/:id/deliveries", s.authenticated(s.webhookDeliveries))
+	}
+	if drafts != nil {
+		router.GET("/drafts", s.authenticated(s.listDrafts))
+		router.POST("/drafts", s.authenticated(s.saveDraft))
+		router.PUT("/drafts/:id", s.authenticated(s.editDraft))
+		router.DELETE("/drafts/:id", s.authenticated(s.deleteDraft))
+		router.POST("/drafts/:id/publish", s.authenticated(s.publishDraft))
+		router.GET("/scheduled", s.authenticated(s.listScheduled))
+		router.POST("/scheduled", s.authenticated(s.schedule))
+		router.DELETE("/scheduled/:id", s.authenticated(s.unschedule))
+	}
+	s.router = router
+	return s
+}
+
+//ServeHTTP handles an API request
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.router.ServeHTTP(w, r)
+}
+
+//SetHeartbeatInterval changes how often streams send a heartbeat while there are no events
+func (s *Server) SetHeartbeatInterval(interval time.Duration) {
+	s.heartbeat = interval
+}
+
+//Run listens for requests on addr
+func (s *Server) Run(addr string) error {
+	return s.router.Run(addr)
+}
+
+type credentialsRequest struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+type tweetRequest struct {
+	Text     string `json:"text"`
+	ImageURL string `json:"imageURL"`
+	QuotedID *int   `json:"quotedID"`
+	ReplyTo  *int   `json:"replyTo"`
+}
+
+type followRequest struct {
+	Name string `json:"name"`
+}
+
+type protectionRequest struct {
+	Protected bool `json:"protected"`
+}
+
+type tweetResponse struct {
+	ID        int            `json:"id"`
+	Type      string         `json:"type"`
+	User      string         `json:"user"`
+	Date      time.Time      `json:"date"`
+	Text      string         `json:"text"`
+	ImageURL  string         `json:"imageURL,omitempty"`
+	Quoted    *tweetResponse `json:"quoted,omitempty"`
+	QuotedID  *int           `json:"quotedID,omitempty"`
+	ReplyTo   *int           `json:"replyTo,omitempty"`
+	Retweeted *tweetResponse `json:"retweeted,omitempty"`
+	Likes     int            `json:"likes"`
+	Retweets  int            `json:"retweets"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func newTweetResponse(tweet domain.Tweeter) (*tweetResponse, error) {
+	record, err := domain.NewTweetRecord(tweet)
+	if err != nil {
+		return nil, err
+	}
+	response := newTweetResponseFromRecord(record)
+	counters := tweet.GetCounters()
+	response.Likes, response.Retweets = counters.Likes, counters.Retweets
+	return response, nil
+}
+
+func newTweetResponseFromRecord(record domain.TweetRecord) *tweetResponse {
+	response := &tweetResponse{
+		ID:       record.ID,
+		Type:     record.Type,
+		User:     record.User.Name,
+		Date:     record.Date,
+		Text:     record.Text,
+		ImageURL: record.ImageURL,
+		QuotedID: record.QuotedID,
+		ReplyTo:  record.ParentID,
+	}
+	if record.Quoted != nil {
+		response.Quoted = newTweetResponseFromRecord(*record.Quoted)
+	}
+	if record.Retweeted != nil {
+		response.Retweeted = newTweetResponseFromRecord(*record.Retweeted)
+	}
+	return response
+}
+
+//statusFor returns the HTTP status that corresponds to an error returned by the manager
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidLogin),
+		errors.Is(err, service.ErrNotLoggedIn),
+		errors.Is(err, service.ErrSessionExpired),
+		errors.Is(err, service.ErrNoUserLoggedIn),
+		errors.Is(err, service.ErrMustBeLoggedInToTweet):
+		return http.StatusUnauthorized
+	case errors.Is(err, service.ErrCantDeleteOthersTweet),
+		errors.Is(err, service.ErrCantEditOthersTweet),
+		errors.Is(err, service.ErrBlocked),
+		errors.Is(err, service.ErrProtectedAccount):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrTweetNotFound),
+		errors.Is(err, service.ErrUserNotFound),
+		errors.Is(err, service.ErrUserNotRegistered),
+		errors.Is(err, service.ErrNotFollowing),
+		errors.Is(err, service.ErrNotBlocked),
+		errors.Is(err, service.ErrNotMuted),
+		errors.Is(err, service.ErrNoFollowRequest),
+		errors.Is(err, service.ErrNotRetweeted),
+		errors.Is(err, service.ErrNotLiked),
+		errors.Is(err, service.ErrNotBookmarked),
+		errors.Is(err, service.ErrWebhookNotFound),
+		errors.Is(err, service.ErrDraftNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrAlreadyRegistered),
+		errors.Is(err, service.ErrAlreadyFollowing),
+		errors.Is(err, service.ErrAlreadyBlocked),
+		errors.Is(err, service.ErrAlreadyMuted),
+		errors.Is(err, service.ErrAlreadyRequested),
+		errors.Is(err, service.ErrAlreadyRetweeted),
+		errors.Is(err, service.ErrAlreadyLiked),
+		errors.Is(err, service.ErrAlreadyBookmarked):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrInvalidName),
+		errors.Is(err, service.ErrInvalidPassword),
+		errors.Is(err, service.ErrCantFollowYourself),
+		errors.Is(err, service.ErrCantBlockYourself),
+		errors.Is(err, service.ErrCantMuteYourself),
+		errors.Is(err, service.ErrCantRetweetOwnTweet),
+		errors.Is(err, domain.ErrCantEditRetweet),
+		errors.Is(err, domain.ErrEmptyText),
+		errors.Is(err, domain.ErrTextTooLong),
+		errors.Is(err, domain.ErrInvisibleCharacters),
+		errors.Is(err, domain.ErrMissingImageURL),
+		errors.Is(err, search.ErrInvalidQuery),
+		errors.Is(err, service.ErrInvalidWebhookURL),
+		errors.Is(err, service.ErrPrivateWebhookURL),
+		errors.Is(err, service.ErrWebhookHostNotFound),
+		errors.Is(err, service.ErrScheduleInThePast):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func respondError(c *gin.Context, err error) {
+	c.JSON(statusFor(err), errorResponse{Error: err.Error()})
+}
+
+func respondBadRequest(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, errorResponse{Error: message})
+}
+
+//respondUsers responds with the names of the users
+func respondUsers(c *gin.Context, users []domain.User) {
+	names := make([]string, 0, len(users))
+	for _, user := range users {
+		names = append(names, user.Name)
+	}
+	c.JSON(http.StatusOK, names)
+}
+
+func respondTweets(c *gin.Context, tweets []domain.Tweeter) {
+	responses := make([]*tweetResponse, 0, len(tweets))
+	for _, tweet := range tweets {
+		response, err := newTweetResponse(tweet)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		responses = append(responses, response)
+	}
+	c.JSON(http.StatusOK, responses)
+}
+
+func sessionToken(c *gin.Context) string {
+	return strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+}
+
+//authenticated runs handler with the token and user of the request's session
+func (s *Server) authenticated(handler func(*gin.Context, string, domain.User)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := sessionToken(c)
+		user, err := s.manager.GetLoggedInUser(token)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		handler(c, token, *user)
+	}
+}
+
+func (s *Server) register(c *gin.Context) {
+	var request credentialsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondBadRequest(c, "Invalid request")
+		return
+	}
+	err := s.manager.Register(domain.NewUser(request.Name, request.Password))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"name": request.Name})
+}
+
+func (s *Server) login(c *gin.Context) {
+	var request credentialsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondBadRequest(c, "Invalid request")
+		return
+	}
+	token, err := s.manager.Login(domain.NewUser(request.Name, request.Password))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"token": token})
+}
+
+func (s *Server) logout(c *gin.Context, token string, user domain.User) {
+	err := s.manager.Logout(token)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+//timeline responds with a page of the timeline, newest first.
+//The page is chosen with the before, after and limit query parameters
+func (s *Server) timeline(c *gin.Context, token string, user domain.User) {
+	request, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+	page, err := s.manager.GetTimelinePage(token, request)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondTweets(c, page.Tweets)
+}
+
+//timelineStream streams the new tweets of the timeline as Server-Sent Events named "tweet", whose IDs are the ones
+//of the tweets. A client that reconnects with a Last-Event-ID header first gets the tweets it missed since that one.
+//The stream ends when the client disconnects or its session ends
+func (s *Server) timelineStream(c *gin.Context, token string, user domain.User) {
+	var stream *service.TimelineStream
+	var err error
+	if lastEventID := c.GetHeader("Last-Event-ID"); lastEventID != "" {
+		id, convErr := strconv.Atoi(lastEventID)
+		if convErr != nil {
+			respondBadRequest(c, "Invalid Last-Event-ID")
+			return
+		}
+		stream, err = s.manager.WatchTimelineAfter(token, id)
+	} else {
+		stream, err = s.manager.WatchTimeline(token)
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer stream.Close()
+
+	c.Header("Content-Type", sse.ContentType)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+	for _, tweet := range stream.Missed {
+		if writeTweetEvent(c, tweet) != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(s.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case tweet := <-stream.Tweets:
+			if writeTweetEvent(c, tweet) != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := s.manager.GetLoggedInUser(token); err != nil {
+				return
+			}
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+//writeTweetEvent writes a tweet as a Server-Sent Event
+func writeTweetEvent(c *gin.Context, tweet domain.Tweeter) error {
+	response, err := newTweetResponse(tweet)
+	if err != nil {
+		return err
+	}
+	return sse.Encode(c.Writer, sse.Event{Id: strconv.Itoa(tweet.GetID()), Event: "tweet", Data: response})
+}
+
+//pageRequest reads the page asked for in the query, responding with an error if it isn't valid
+func pageRequest(c *gin.Context) (service.PageRequest, bool) {
+	var request service.PageRequest
+	var err error
+	if limit := c.Query("limit"); limit != "" {
+		request.Limit, err = strconv.Atoi(limit)
+	}
+	if before := c.Query("before"); err == nil && before != "" {
+		request.Before, err = strconv.Atoi(before)
+		request.HasBefore = true
+	}
+	if after := c.Query("after"); err == nil && after != "" {
+		request.After, err = strconv.Atoi(after)
+		request.HasAfter = true
+	}
+	if err != nil || (request.HasBefore && request.HasAfter) {
+		respondBadRequest(c, "Invalid page")
+		return request, false
+	}
+	return request, true
+}
+
+func (s *Server) publish(c *gin.Context, token string, user domain.User) {
+	var request tweetRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondBadRequest(c, "Invalid request")
+		return
+	}
+
+	var tweet domain.Tweeter
+	var err error
+	switch {
+	case request.ReplyTo != nil:
+		var parent domain.Tweeter
+		parent, err = s.manager.GetVisibleTweetByID(token, *request.ReplyTo)
+		if err == nil {
+			tweet, err = s.manager.NewReplyTweet(user, request.Text, parent)
+		}
+	case request.QuotedID != nil:
+		var quoted domain.Tweeter
+		quoted, err = s.manager.GetVisibleTweetByID(token, *request.QuotedID)
+		if err == nil {
+			tweet, err = s.manager.NewQuoteTweet(user, request.Text, quoted)
+		}
+	case request.ImageURL != "":
+		tweet, err = s.manager.NewImageTweet(user, request.Text, request.ImageURL)
+	default:
+		tweet, err = s.manager.NewTextTweet(user, request.Text)
+	}
+	if err == nil {
+		err = s.manager.PublishTweet(token, tweet)
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response, err := newTweetResponse(tweet)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+//tweetID returns the ID in the URL, responding with an error if it isn't valid
+func tweetID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid tweet ID")
+		return 0, false
+	}
+	return id, true
+}
+
+func (s *Server) tweetByID(c *gin.Context) {
+	id, ok := tweetID(c)
+	if !ok {
+		return
+	}
+	tweet, err := s.manager.GetVisibleTweetByID(sessionToken(c), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	response, err := newTweetResponse(tweet)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (s *Server) edit(c *gin.Context, token string, user domain.User) {
+	id, ok := tweetID(c)
+	if !ok {
+		return
+	}
+	var request tweetRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondBadRequest(c, "Invalid request")
+		return
+	}
+	err := s.manager.EditTweetTextByID(token, id, request.Text)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	tweet, err := s.manager.GetVisibleTweetByID(token, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	response, err := newTweetResponse(tweet)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (s *Server) delete(c *gin.Context, token string, user domain.User) {
+	id, ok := tweetID(c)
+	if !ok {
+		return
+	}
+	err := s.manager.DeleteTweetByID(token, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) retweet(c *gin.Context, token string, user domain.User) {
+	id, ok := tweetID(c)
+	if !ok {
+		return
+	}
+	retweet, err := s.manager.Retweet(token, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	response, err := newTweetResponse(retweet)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+func (s *Server) unretweet(c *gin.Context, token string, user domain.User) {
+	id, ok := tweetID(c)
+	if !ok {
+		return
+	}
+	err := s.manager.Unretweet(token, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) likes(c *gin.Context) {
+	id, ok := tweetID(c)
+	if !ok {
+		return
+	}
+	users, err := s.manager.GetLikes(sessionToken(c), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondUsers(c, users)
+}
+
+func (s *Server) like(c *gin.Context, token string, user domain.User) {
+	s.tweetAction(c, func(id int) error { return s.manager.LikeTweet(token, id) })
+}
+
+func (s *Server) unlike(c *gin.Context, token string, user domain.User) {
+	s.tweetAction(c, func(id int) error { return s.manager.UnlikeTweet(token, id) })
+}
+
+func (s *Server) bookmark(c *gin.Context, token string, user domain.User) {
+	s.tweetAction(c, func(id int) error { return s.manager.BookmarkTweet(token, id) })
+}
+
+func (s *Server) removeBookmark(c *gin.Context, token string, user domain.User) {
+	s.tweetAction(c, func(id int) error { return s.manager.RemoveBookmark(token, id) })
+}
+
+//tweetAction does an action on the tweet with the ID in the URL, that responds with no content
+func (s *Server) tweetAction(c *gin.Context, action func(id int) error) {
+	id, ok := tweetID(c)
+	if !ok {
+		return
+	}
+	err := action(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) bookmarks(c *gin.Context, token string, user domain.User) {
+	tweets, err := s.manager.GetBookmarks(token)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondTweets(c, tweets)
+}
+
+func (s *Server) hashtag(c *gin.Context) {
+	respondTweets(c, s.manager.GetTweetsWithHashtag(c.Param("tag")))
+}
+
+func (s *Server) mentions(c *gin.Context, token string, user domain.User) {
+	tweets, err := s.manager.GetMentions(token)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondTweets(c, tweets)
+}
+
+func (s *Server) search(c *gin.Context) {
+	tweets, err := s.manager.Search(c.Query("q"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondTweets(c, tweets)
+}
+
+//follow responds 202 Accepted instead of 204 No Content when the followed user is protected,
+//as the follow has to be approved
+func (s *Server) follow(c *gin.Context, token string, user domain.User) {
+	var request followRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondBadRequest(c, "Invalid request")
+		return
+	}
+	err := s.manager.FollowUser(token, request.Name)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if follower, err := s.manager.GetLoggedInUser(token); err == nil && !follower.IsFollowing(domain.NewUser(request.Name, "")) {
+		c.Status(http.StatusAccepted)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+//userAction does something to the user named in the body of the request
+func (s *Server) userAction(c *gin.Context, action func(string) error) {
+	var request followRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondBadRequest(c, "Invalid request")
+		return
+	}
+	err := action(request.Name)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+//namedUserAction does something to the user named in the path
+func (s *Server) namedUserAction(c *gin.Context, action func(string) error) {
+	err := action(c.Param("name"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) unfollow(c *gin.Context, token string, user domain.User) {
+	s.namedUserAction(c, func(name string) error { return s.manager.UnfollowUser(token, name) })
+}
+
+func (s *Server) followers(c *gin.Context) {
+	users, err := s.manager.GetFollowers(domain.NewUser(c.Param("name"), ""))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondUsers(c, users)
+}
+
+func (s *Server) following(c *gin.Context) {
+	users, err := s.manager.GetFollowing(domain.NewUser(c.Param("name"), ""))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondUsers(c, users)
+}
+
+func (s *Server) blocked(c *gin.Context, token string, user domain.User) {
+	users, err := s.manager.GetBlockedUsers(token)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondUsers(c, users)
+}
+
+func (s *Server) block(c *gin.Context, token string, user domain.User) {
+	s.userAction(c, func(name string) error { return s.manager.BlockUser(token, name) })
+}
+
+func (s *Server) unblock(c *gin.Context, token string, user domain.User) {
+	s.namedUserAction(c, func(name string) error { return s.manager.UnblockUser(token, name) })
+}
+
+func (s *Server) muted(c *gin.Context, token string, user domain.User) {
+	users, err := s.manager.GetMutedUsers(token)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondUsers(c, users)
+}
+
+func (s *Server) mute(c *gin.Context, token string, user domain.User) {
+	s.userAction(c, func(name string) error { return s.manager.MuteUser(token, name) })
+}
+
+func (s *Server) unmute(c *gin.Context, token string, user domain.User) {
+	s.namedUserAction(c, func(name string) error { return s.manager.UnmuteUser(token, name) })
+}
+
+func (s *Server) userTweets(c *gin.Context) {
+	tweets, err := s.manager.GetVisibleTweetsFromUser(sessionToken(c), domain.NewUser(c.Param("name"), ""))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondTweets(c, tweets)
+}
+
+func (s *Server) protect(c *gin.Context, token string, user domain.User) {
+	var request protectionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondBadRequest(c, "Invalid request")
+		return
+	}
+	err := s.manager.SetProtected(token, request.Protected)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) requests(c *gin.Context, token string, user domain.User) {
+	users, err := s.manager.GetFollowRequests(token)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	respondUsers(c, users)
+}
+
+func (s *Server) approve(c *gin.Context, token string, user domain.User) {
+	s.namedUserAction(c, func(name string) error { return s.manager.ApproveFollowRequest(token, name) })
+}
+
+func (s *Server) reject(c *gin.Context, token string, user domain.User) {
+	s.namedUserAction(c, func(name string) error { return s.manager.RejectFollowRequest(token, name) })
+}
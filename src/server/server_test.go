@@ -0,0 +1,491 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/server"
+	"github.com/cursoGo/src/service"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+	//Hashing passwords is slow on purpose, but tests don't need it to be
+	domain.PasswordHashIterations = 1000
+}
+
+//UTILITY FUNCTIONS
+
+func newTestServer() *server.Server {
+	var manager service.TweetManager
+	manager.InitializeManager()
+	return server.NewServer(&manager)
+}
+
+func doRequest(s *server.Server, method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	var payload bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&payload).Encode(body)
+	}
+	request := httptest.NewRequest(method, path, &payload)
+	request.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func expectStatus(t *testing.T, recorder *httptest.ResponseRecorder, expected int) bool {
+	if recorder.Code != expected {
+		t.Errorf("Expected status is %d but was %d: %s", expected, recorder.Code, recorder.Body.String())
+		return false
+	}
+	return true
+}
+
+//registerAndLogin registers a user and returns the token of a new session
+func registerAndLogin(t *testing.T, s *server.Server, name string) string {
+	credentials := map[string]string{"name": name, "password": "pw"}
+	doRequest(s, "POST", "/users", "", credentials)
+	recorder := doRequest(s, "POST", "/sessions", "", credentials)
+	var response struct{ Token string }
+	json.Unmarshal(recorder.Body.Bytes(), &response)
+	if response.Token == "" {
+		t.Fatalf("Couldn't log in as %s: %s", name, recorder.Body.String())
+	}
+	return response.Token
+}
+
+type tweetJSON struct {
+	ID        int
+	Type      string
+	User      string
+	Text      string
+	Quoted    *tweetJSON
+	ReplyTo   *int
+	Retweeted *tweetJSON
+	Likes     int
+	Retweets  int
+}
+
+func publish(t *testing.T, s *server.Server, token, text string) tweetJSON {
+	recorder := doRequest(s, "POST", "/tweets", token, map[string]string{"text": text})
+	var tweet tweetJSON
+	json.Unmarshal(recorder.Body.Bytes(), &tweet)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("Couldn't publish %q: %s", text, recorder.Body.String())
+	}
+	return tweet
+}
+
+//REGISTERING AND SESSIONS TESTS
+
+func TestCanRegisterThroughAPI(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	//Operation
+	recorder := doRequest(s, "POST", "/users", "", map[string]string{"name": "root", "password": "root"})
+	//Validation
+	expectStatus(t, recorder, http.StatusCreated)
+}
+
+func TestCantRegisterTwiceThroughAPI(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	credentials := map[string]string{"name": "root", "password": "root"}
+	doRequest(s, "POST", "/users", "", credentials)
+	//Operation
+	recorder := doRequest(s, "POST", "/users", "", credentials)
+	//Validation
+	expectStatus(t, recorder, http.StatusConflict)
+}
+
+func TestCantRegisterWithoutPasswordThroughAPI(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	//Operation
+	recorder := doRequest(s, "POST", "/users", "", map[string]string{"name": "root"})
+	//Validation
+	expectStatus(t, recorder, http.StatusBadRequest)
+}
+
+func TestCantLoginWithWrongPasswordThroughAPI(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	doRequest(s, "POST", "/users", "", map[string]string{"name": "root", "password": "root"})
+	//Operation
+	recorder := doRequest(s, "POST", "/sessions", "", map[string]string{"name": "root", "password": "nope"})
+	//Validation
+	expectStatus(t, recorder, http.StatusUnauthorized)
+}
+
+func TestManyUsersCanBeLoggedInAtOnce(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	manuToken := registerAndLogin(t, s, "manu")
+	gonzaToken := registerAndLogin(t, s, "gonza")
+	//Operation
+	manuTweet := publish(t, s, manuToken, "hola")
+	gonzaTweet := publish(t, s, gonzaToken, "chau")
+	//Validation
+	if manuTweet.User != "manu" || gonzaTweet.User != "gonza" {
+		t.Errorf("Tweets published by the wrong users, %s and %s", manuTweet.User, gonzaTweet.User)
+	}
+}
+
+func TestCantUseSessionAfterLogout(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	token := registerAndLogin(t, s, "root")
+	doRequest(s, "DELETE", "/sessions", token, nil)
+	//Operation
+	recorder := doRequest(s, "GET", "/timeline", token, nil)
+	//Validation
+	expectStatus(t, recorder, http.StatusUnauthorized)
+}
+
+//TWEETS TESTS
+
+func TestCantPublishWithoutSession(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	//Operation
+	recorder := doRequest(s, "POST", "/tweets", "", map[string]string{"text": "hola"})
+	//Validation
+	expectStatus(t, recorder, http.StatusUnauthorized)
+}
+
+func TestCantPublishEmptyTweetThroughAPI(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	token := registerAndLogin(t, s, "root")
+	//Operation
+	recorder := doRequest(s, "POST", "/tweets", token, map[string]string{"text": ""})
+	//Validation
+	expectStatus(t, recorder, http.StatusBadRequest)
+}
+
+func TestCantPublishTweetWithInvisibleCharactersThroughAPI(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	token := registerAndLogin(t, s, "root")
+	//Operation
+	recorder := doRequest(s, "POST", "/tweets", token, map[string]string{"text": "hi\u0007there"})
+	//Validation
+	expectStatus(t, recorder, http.StatusBadRequest)
+}
+
+func TestCanPublishImageAndQuoteTweetsThroughAPI(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	token := registerAndLogin(t, s, "root")
+	quoted := publish(t, s, token, "quote me")
+	//Operation
+	image := doRequest(s, "POST", "/tweets", token, map[string]string{"text": "look", "imageURL": "https://google.com.ar"})
+	quote := doRequest(s, "POST", "/tweets", token, map[string]interface{}{"text": "nice", "quotedID": quoted.ID})
+	//Validation
+	if !expectStatus(t, image, http.StatusCreated) || !expectStatus(t, quote, http.StatusCreated) {
+		return
+	}
+	var quoteTweet tweetJSON
+	json.Unmarshal(quote.Body.Bytes(), &quoteTweet)
+	if quoteTweet.Type != "quote" || quoteTweet.Quoted == nil || quoteTweet.Quoted.ID != quoted.ID {
+		t.Errorf("Unexpected quote tweet %s", quote.Body.String())
+	}
+}
+
+func TestCanGetTweetByIDThroughAPI(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	token := registerAndLogin(t, s, "root")
+	tweet := publish(t, s, token, "hola")
+	//Operation
+	recorder := doRequest(s, "GET", "/tweets/"+strconv.Itoa(tweet.ID), "", nil)
+	//Validation
+	if !expectStatus(t, recorder, http.StatusOK) {
+		return
+	}
+	var retrieved tweetJSON
+	json.Unmarshal(recorder.Body.Bytes(), &retrieved)
+	if retrieved.Text != "hola" {
+		t.Errorf("Expected text is hola but was %s", retrieved.Text)
+	}
+}
+
+func TestGetNonExistentTweetIsNotFound(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	//Operation
+	recorder := doRequest(s, "GET", "/tweets/42", "", nil)
+	//Validation
+	expectStatus(t, recorder, http.StatusNotFound)
+}
+
+func TestCanEditTweetThroughAPI(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	token := registerAndLogin(t, s, "root")
+	tweet := publish(t, s, token, "sample")
+	//Operation
+	recorder := doRequest(s, "PUT", "/tweets/"+strconv.Itoa(tweet.ID), token, map[string]string{"text": "modified sample"})
+	//Validation
+	if !expectStatus(t, recorder, http.StatusOK) {
+		return
+	}
+	var edited tweetJSON
+	json.Unmarshal(recorder.Body.Bytes(), &edited)
+	if edited.Text != "modified sample" {
+		t.Errorf("Expected text is modified sample but was %s", edited.Text)
+	}
+}
+
+func TestCantEditOthersTweetThroughAPI(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	manuToken := registerAndLogin(t, s, "manu")
+	gonzaToken := registerAndLogin(t, s, "gonza")
+	tweet := publish(t, s, manuToken, "sample")
+	//Operation
+	recorder := doRequest(s, "PUT", "/tweets/"+strconv.Itoa(tweet.ID), gonzaToken, map[string]string{"text": "mine"})
+	//Validation
+	expectStatus(t, recorder, http.StatusForbidden)
+}
+
+func TestCanDeleteTweetThroughAPI(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	token := registerAndLogin(t, s, "root")
+	tweet := publish(t, s, token, "sample")
+	//Operation
+	recorder := doRequest(s, "DELETE", "/tweets/"+strconv.Itoa(tweet.ID), token, nil)
+	//Validation
+	if !expectStatus(t, recorder, http.StatusNoContent) {
+		return
+	}
+	expectStatus(t, doRequest(s, "GET", "/tweets/"+strconv.Itoa(tweet.ID), "", nil), http.StatusNotFound)
+}
+
+func TestCantDeleteWithInvalidID(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	token := registerAndLogin(t, s, "root")
+	//Operation
+	recorder := doRequest(s, "DELETE", "/tweets/abc", token, nil)
+	//Validation
+	expectStatus(t, recorder, http.StatusBadRequest)
+}
+
+//FOLLOWING AND TIMELINE TESTS
+
+func TestTimelineIncludesFollowedUsersThroughAPI(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	manuToken := registerAndLogin(t, s, "manu")
+	gonzaToken := registerAndLogin(t, s, "gonza")
+	publish(t, s, gonzaToken, "from gonza")
+	publish(t, s, manuToken, "from manu")
+	//Operation
+	follow := doRequest(s, "POST", "/following", manuToken, map[string]string{"name": "gonza"})
+	recorder := doRequest(s, "GET", "/timeline", manuToken, nil)
+	//Validation
+	if !expectStatus(t, follow, http.StatusNoContent) || !expectStatus(t, recorder, http.StatusOK) {
+		return
+	}
+	var timeline []tweetJSON
+	json.Unmarshal(recorder.Body.Bytes(), &timeline)
+	if len(timeline) != 2 {
+		t.Errorf("Expected size is 2 but was %d", len(timeline))
+	}
+}
+
+func TestCanPageTimelineThroughAPI(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	token := registerAndLogin(t, s, "root")
+	var published []tweetJSON
+	for _, text := range []string{"first", "second", "third"} {
+		published = append(published, publish(t, s, token, text))
+	}
+	//Operation
+	recorder := doRequest(s, "GET", "/timeline?limit=2&before="+strconv.Itoa(published[2].ID), token, nil)
+	//Validation
+	if !expectStatus(t, recorder, http.StatusOK) {
+		return
+	}
+	var timeline []tweetJSON
+	json.Unmarshal(recorder.Body.Bytes(), &timeline)
+	if len(timeline) != 2 || timeline[0].Text != "second" || timeline[1].Text != "first" {
+		t.Errorf("Unexpected page %s", recorder.Body.String())
+	}
+}
+
+func TestCantPageTimelineWithInvalidCursorThroughAPI(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	token := registerAndLogin(t, s, "root")
+	//Operation
+	recorder := doRequest(s, "GET", "/timeline?before=first", token, nil)
+	//Validation
+	expectStatus(t, recorder, http.StatusBadRequest)
+}
+
+func TestCanReplyThroughAPI(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	token := registerAndLogin(t, s, "root")
+	parent := publish(t, s, token, "question")
+	//Operation
+	recorder := doRequest(s, "POST", "/tweets", token, map[string]interface{}{"text": "answer", "replyTo": parent.ID})
+	//Validation
+	if !expectStatus(t, recorder, http.StatusCreated) {
+		return
+	}
+	var reply tweetJSON
+	json.Unmarshal(recorder.Body.Bytes(), &reply)
+	if reply.Type != "reply" || reply.ReplyTo == nil || *reply.ReplyTo != parent.ID {
+		t.Errorf("Unexpected reply %s", recorder.Body.String())
+	}
+}
+
+func TestCanRetweetAndUndoThroughAPI(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	rootToken := registerAndLogin(t, s, "root")
+	gonzaToken := registerAndLogin(t, s, "gonza")
+	original := publish(t, s, rootToken, "share me")
+	path := "/tweets/" + strconv.Itoa(original.ID) + "/retweets"
+
+	//Operation
+	recorder := doRequest(s, "POST", path, gonzaToken, nil)
+	again := doRequest(s, "POST", path, gonzaToken, nil)
+	undo := doRequest(s, "DELETE", path, gonzaToken, nil)
+
+	//Validation
+	if !expectStatus(t, recorder, http.StatusCreated) {
+		return
+	}
+	var retweet tweetJSON
+	json.Unmarshal(recorder.Body.Bytes(), &retweet)
+	if retweet.Type != "retweet" || retweet.User != "gonza" || retweet.Retweeted == nil || retweet.Retweeted.ID != original.ID {
+		t.Errorf("Unexpected retweet %s", recorder.Body.String())
+	}
+	expectStatus(t, again, http.StatusConflict)
+	expectStatus(t, undo, http.StatusNoContent)
+}
+
+func TestCanLikeAndBookmarkThroughAPI(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	rootToken := registerAndLogin(t, s, "root")
+	gonzaToken := registerAndLogin(t, s, "gonza")
+	original := publish(t, s, rootToken, "like me")
+	path := "/tweets/" + strconv.Itoa(original.ID)
+
+	//Operation
+	like := doRequest(s, "POST", path+"/likes", gonzaToken, nil)
+	bookmark := doRequest(s, "POST", path+"/bookmarks", gonzaToken, nil)
+
+	//Validation
+	expectStatus(t, like, http.StatusNoContent)
+	expectStatus(t, bookmark, http.StatusNoContent)
+	var tweet tweetJSON
+	json.Unmarshal(doRequest(s, "GET", path, "", nil).Body.Bytes(), &tweet)
+	if tweet.Likes != 1 {
+		t.Errorf("Expected 1 like but got %d", tweet.Likes)
+	}
+	var bookmarks []tweetJSON
+	json.Unmarshal(doRequest(s, "GET", "/bookmarks", gonzaToken, nil).Body.Bytes(), &bookmarks)
+	if len(bookmarks) != 1 || bookmarks[0].ID != original.ID {
+		t.Errorf("Unexpected bookmarks %v", bookmarks)
+	}
+	expectStatus(t, doRequest(s, "POST", path+"/likes", gonzaToken, nil), http.StatusConflict)
+}
+
+func TestCantFollowUnknownUserThroughAPI(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	token := registerAndLogin(t, s, "root")
+	//Operation
+	recorder := doRequest(s, "POST", "/following", token, map[string]string{"name": "nobody"})
+	//Validation
+	expectStatus(t, recorder, http.StatusNotFound)
+}
+
+func TestCantFollowSameUserTwiceThroughAPI(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	token := registerAndLogin(t, s, "manu")
+	registerAndLogin(t, s, "gonza")
+	doRequest(s, "POST", "/following", token, map[string]string{"name": "gonza"})
+	//Operation
+	recorder := doRequest(s, "POST", "/following", token, map[string]string{"name": "gonza"})
+	//Validation
+	expectStatus(t, recorder, http.StatusConflict)
+}
+
+func TestCantFollowBlockingUserThroughAPI(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	token := registerAndLogin(t, s, "manu")
+	gonzaToken := registerAndLogin(t, s, "gonza")
+	doRequest(s, "POST", "/blocks", token, map[string]string{"name": "gonza"})
+	//Operation
+	recorder := doRequest(s, "POST", "/following", gonzaToken, map[string]string{"name": "manu"})
+	//Validation
+	expectStatus(t, recorder, http.StatusForbidden)
+	var blocked []string
+	json.Unmarshal(doRequest(s, "GET", "/blocks", token, nil).Body.Bytes(), &blocked)
+	if len(blocked) != 1 || blocked[0] != "gonza" {
+		t.Errorf("Unexpected blocked users %v", blocked)
+	}
+}
+
+func TestFollowingProtectedUserNeedsApprovalThroughAPI(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	token := registerAndLogin(t, s, "manu")
+	gonzaToken := registerAndLogin(t, s, "gonza")
+	tweet := publish(t, s, gonzaToken, "only for friends")
+	doRequest(s, "PUT", "/protection", gonzaToken, map[string]bool{"protected": true})
+	path := "/tweets/" + strconv.Itoa(tweet.ID)
+	//Operation
+	recorder := doRequest(s, "POST", "/following", token, map[string]string{"name": "gonza"})
+	//Validation
+	if !expectStatus(t, recorder, http.StatusAccepted) {
+		return
+	}
+	expectStatus(t, doRequest(s, "GET", path, token, nil), http.StatusNotFound)
+	expectStatus(t, doRequest(s, "GET", "/users/gonza/tweets", token, nil), http.StatusForbidden)
+	expectStatus(t, doRequest(s, "POST", "/requests/manu", gonzaToken, nil), http.StatusNoContent)
+	expectStatus(t, doRequest(s, "GET", path, token, nil), http.StatusOK)
+	expectStatus(t, doRequest(s, "GET", path, "", nil), http.StatusNotFound)
+}
+
+func TestCanUnfollowAndListFollowersThroughAPI(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	token := registerAndLogin(t, s, "manu")
+	gonzaToken := registerAndLogin(t, s, "gonza")
+	doRequest(s, "POST", "/following", token, map[string]string{"name": "gonza"})
+	doRequest(s, "POST", "/following", gonzaToken, map[string]string{"name": "manu"})
+	//Operation
+	recorder := doRequest(s, "DELETE", "/following/gonza", token, nil)
+	//Validation
+	if !expectStatus(t, recorder, http.StatusNoContent) {
+		return
+	}
+	var followers []string
+	json.Unmarshal(doRequest(s, "GET", "/users/manu/followers", "", nil).Body.Bytes(), &followers)
+	if len(followers) != 1 || followers[0] != "gonza" {
+		t.Errorf("Unexpected followers %v", followers)
+	}
+	expectStatus(t, doRequest(s, "DELETE", "/following/gonza", token, nil), http.StatusNotFound)
+}
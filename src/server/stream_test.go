@@ -0,0 +1,201 @@
+package server_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cursoGo/src/server"
+	"github.com/cursoGo/src/service"
+)
+
+//newStreamServer returns a server listening on a local port, with the manager behind it
+func newStreamServer() (*server.Server, *service.TweetManager, *httptest.Server) {
+	var manager service.TweetManager
+	manager.InitializeManager()
+	s := server.NewServer(&manager)
+	return s, &manager, httptest.NewServer(s)
+}
+
+//openStream opens the timeline stream of a session, resuming after lastEventID if it isn't empty.
+//Canceling the returned function disconnects
+func openStream(t *testing.T, listener *httptest.Server, token string, lastEventID string) (*bufio.Reader, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	request, _ := http.NewRequest("GET", listener.URL+"/timeline/stream", nil)
+	request = request.WithContext(ctx)
+	request.Header.Set("Authorization", "Bearer "+token)
+	if lastEventID != "" {
+		request.Header.Set("Last-Event-ID", lastEventID)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		cancel()
+		t.Fatalf("Unexpected error, %s", err.Error())
+	}
+	if response.StatusCode != http.StatusOK || response.Header.Get("Content-Type") != "text/event-stream" {
+		cancel()
+		t.Fatalf("Unexpected response %d %s", response.StatusCode, response.Header.Get("Content-Type"))
+	}
+	return bufio.NewReader(response.Body), func() {
+		cancel()
+		response.Body.Close()
+	}
+}
+
+type streamEvent struct {
+	ID      string
+	Event   string
+	Data    string
+	Comment string
+}
+
+//readEvent reads the next event of a stream, which may be just a comment
+func readEvent(t *testing.T, reader *bufio.Reader) streamEvent {
+	var event streamEvent
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Unexpected error, %s", err.Error())
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return event
+		}
+		switch {
+		case strings.HasPrefix(line, ":"):
+			event.Comment = strings.TrimSpace(line[1:])
+		case strings.HasPrefix(line, "id:"):
+			event.ID = line[len("id:"):]
+		case strings.HasPrefix(line, "event:"):
+			event.Event = line[len("event:"):]
+		case strings.HasPrefix(line, "data:"):
+			event.Data += line[len("data:"):]
+		}
+	}
+}
+
+//expectTweetEvent fails the test if the next event that isn't a comment isn't the given tweet
+func expectTweetEvent(t *testing.T, reader *bufio.Reader, expected tweetJSON) {
+	event := readEvent(t, reader)
+	for event.Comment != "" {
+		event = readEvent(t, reader)
+	}
+	var tweet tweetJSON
+	json.Unmarshal([]byte(event.Data), &tweet)
+	if event.Event != "tweet" || event.ID != strconv.Itoa(expected.ID) || tweet.ID != expected.ID || tweet.Text != expected.Text {
+		t.Errorf("Expected tweet %d but got %+v", expected.ID, event)
+	}
+}
+
+func TestStreamsNewTweetsOfFollowedUsers(t *testing.T) {
+	//Initialization
+	s, _, listener := newStreamServer()
+	defer listener.Close()
+	manu := registerAndLogin(t, s, "manu")
+	gonza := registerAndLogin(t, s, "gonza")
+	root := registerAndLogin(t, s, "root")
+	doRequest(s, "POST", "/following", manu, map[string]string{"name": "gonza"})
+	reader, disconnect := openStream(t, listener, manu, "")
+	defer disconnect()
+
+	//Operation
+	publish(t, s, root, "not followed")
+	followed := publish(t, s, gonza, "hola manu")
+	own := publish(t, s, manu, "mío")
+
+	//Validation
+	expectTweetEvent(t, reader, followed)
+	expectTweetEvent(t, reader, own)
+}
+
+func TestStreamResumesFromLastEventID(t *testing.T) {
+	//Initialization
+	s, _, listener := newStreamServer()
+	defer listener.Close()
+	manu := registerAndLogin(t, s, "manu")
+	gonza := registerAndLogin(t, s, "gonza")
+	doRequest(s, "POST", "/following", manu, map[string]string{"name": "gonza"})
+	seen := publish(t, s, gonza, "seen")
+	first := publish(t, s, gonza, "missed")
+	second := publish(t, s, gonza, "missed too")
+
+	//Operation
+	reader, disconnect := openStream(t, listener, manu, strconv.Itoa(seen.ID))
+	defer disconnect()
+	live := publish(t, s, gonza, "live")
+
+	//Validation
+	expectTweetEvent(t, reader, first)
+	expectTweetEvent(t, reader, second)
+	expectTweetEvent(t, reader, live)
+}
+
+func TestStreamSendsHeartbeats(t *testing.T) {
+	//Initialization
+	s, _, listener := newStreamServer()
+	defer listener.Close()
+	s.SetHeartbeatInterval(10 * time.Millisecond)
+	manu := registerAndLogin(t, s, "manu")
+
+	//Operation
+	reader, disconnect := openStream(t, listener, manu, "")
+	defer disconnect()
+
+	//Validation
+	if event := readEvent(t, reader); event.Comment != "heartbeat" {
+		t.Errorf("Expected a heartbeat but got %+v", event)
+	}
+}
+
+func TestDisconnectingUnsubscribesStream(t *testing.T) {
+	//Initialization
+	s, manager, listener := newStreamServer()
+	defer listener.Close()
+	manu := registerAndLogin(t, s, "manu")
+	_, disconnect := openStream(t, listener, manu, "")
+
+	//Operation
+	disconnect()
+
+	//Validation
+	deadline := time.Now().Add(time.Second)
+	for manager.EventBus().Subscribers() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("The stream is still subscribed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCantStreamWithoutSession(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+
+	//Operation
+	recorder := doRequest(s, "GET", "/timeline/stream", "", nil)
+
+	//Validation
+	expectStatus(t, recorder, http.StatusUnauthorized)
+}
+
+func TestCantResumeStreamFromInvalidID(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	manu := registerAndLogin(t, s, "manu")
+	request := httptest.NewRequest("GET", "/timeline/stream", nil)
+	request.Header.Set("Authorization", "Bearer "+manu)
+	request.Header.Set("Last-Event-ID", "not a number")
+	recorder := httptest.NewRecorder()
+
+	//Operation
+	s.ServeHTTP(recorder, request)
+
+	//Validation
+	expectStatus(t, recorder, http.StatusBadRequest)
+}
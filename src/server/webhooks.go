@@ -0,0 +1,80 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/service"
+	"github.com/gin-gonic/gin"
+)
+
+type webhookRequest struct {
+	URL string `json:"url"`
+}
+
+func webhookID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid webhook ID")
+		return 0, false
+	}
+	return id, true
+}
+
+func (s *Server) listWebhooks(c *gin.Context, token string, user domain.User) {
+	webhooks, err := s.webhooks.GetWebhooks(token)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if webhooks == nil {
+		webhooks = []service.Webhook{}
+	}
+	c.JSON(http.StatusOK, webhooks)
+}
+
+//addWebhook adds a webhook for the events of the user, responding with it and the secret that signs its payloads
+func (s *Server) addWebhook(c *gin.Context, token string, user domain.User) {
+	var request webhookRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondBadRequest(c, "Invalid request")
+		return
+	}
+	webhook, err := s.webhooks.AddWebhook(token, request.URL)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, webhook)
+}
+
+func (s *Server) removeWebhook(c *gin.Context, token string, user domain.User) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+	err := s.webhooks.RemoveWebhook(token, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+//webhookDeliveries responds with the deliveries to a webhook of the user, the latest first
+func (s *Server) webhookDeliveries(c *gin.Context, token string, user domain.User) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+	deliveries, err := s.webhooks.GetDeliveries(token, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if deliveries == nil {
+		deliveries = []service.Delivery{}
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
@@ -0,0 +1,63 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cursoGo/src/server"
+	"github.com/cursoGo/src/service"
+)
+
+func TestCanAddWebhookAndSeeDeliveriesThroughAPI(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	webhooks := service.NewWebhookDispatcher(&manager)
+	defer webhooks.Close()
+	s := server.NewServerWithWebhooks(&manager, webhooks)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer receiver.Close()
+	token := registerAndLogin(t, s, "manu")
+	expectStatus(t, doRequest(s, "POST", "/webhooks", token, map[string]string{"url": receiver.URL}), http.StatusBadRequest)
+	webhooks.AllowPrivateAddresses()
+	//Operation
+	recorder := doRequest(s, "POST", "/webhooks", token, map[string]string{"url": receiver.URL})
+	//Validation
+	if !expectStatus(t, recorder, http.StatusCreated) {
+		return
+	}
+	var webhook service.Webhook
+	json.Unmarshal(recorder.Body.Bytes(), &webhook)
+	if webhook.Secret == "" || webhook.URL != receiver.URL {
+		t.Errorf("Unexpected webhook %s", recorder.Body.String())
+	}
+	publish(t, s, token, "hola")
+	path := "/webhooks/" + strconv.Itoa(webhook.ID) + "/deliveries"
+	var deliveries []service.Delivery
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		json.Unmarshal(doRequest(s, "GET", path, token, nil).Body.Bytes(), &deliveries)
+		if len(deliveries) == 1 && deliveries[0].Status == service.DeliveryDelivered {
+			break
+		}
+	}
+	if len(deliveries) != 1 || deliveries[0].Status != service.DeliveryDelivered || deliveries[0].Payload[0] != '{' {
+		t.Errorf("Unexpected deliveries %+v", deliveries)
+	}
+	expectStatus(t, doRequest(s, "POST", "/webhooks", token, map[string]string{"url": "not a url"}), http.StatusBadRequest)
+	expectStatus(t, doRequest(s, "DELETE", "/webhooks/"+strconv.Itoa(webhook.ID), token, nil), http.StatusNoContent)
+	expectStatus(t, doRequest(s, "GET", path, token, nil), http.StatusNotFound)
+}
+
+func TestWebhookRoutesNeedDispatcher(t *testing.T) {
+	//Initialization
+	s := newTestServer()
+	token := registerAndLogin(t, s, "manu")
+	//Operation
+	recorder := doRequest(s, "GET", "/webhooks", token, nil)
+	//Validation
+	expectStatus(t, recorder, http.StatusNotFound)
+}
@@ -0,0 +1,313 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/service"
+	"github.com/cursoGo/src/utility"
+)
+
+//publishText publishes a text tweet of the user logged in with token, failing the test if it can't
+func publishText(t *testing.T, manager *service.TweetManager, token string, name string, text string) domain.Tweeter {
+	tweet, _ := manager.NewTextTweet(domain.NewUser(name, ""), text)
+	if err := manager.PublishTweet(token, tweet); err != nil {
+		t.Fatalf("Unexpected error, %s", err.Error())
+	}
+	return tweet
+}
+
+func TestBlockingRemovesFollowsBothWays(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	manager.FollowUser(tokens[0], "gonza")
+	manager.FollowUser(tokens[1], "manu")
+
+	//Operation
+	err := manager.BlockUser(tokens[0], "gonza")
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	following, _ := manager.GetFollowing(domain.NewUser("manu", ""))
+	expectUsers(t, following)
+	followers, _ := manager.GetFollowers(domain.NewUser("manu", ""))
+	expectUsers(t, followers)
+	blocked, _ := manager.GetBlockedUsers(tokens[0])
+	expectUsers(t, blocked, "gonza")
+}
+
+func TestBlockedUserCantFollow(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	manager.BlockUser(tokens[0], "gonza")
+
+	//Operation
+	err := manager.FollowUser(tokens[1], "manu")
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't follow user, You can't interact with that user")
+}
+
+func TestBlockedUserCantReplyQuoteNorMention(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	tweet := publishText(t, &manager, tokens[0], "manu", "hola")
+	manager.BlockUser(tokens[0], "gonza")
+
+	//Operation
+	_, replyErr := manager.ReplyToTweet(tokens[1], tweet.GetID(), "chau")
+	_, quoteErr := manager.QuoteTweet(tokens[1], tweet.GetID(), "mirá")
+	mention, _ := manager.NewTextTweet(domain.NewUser("gonza", ""), "hola @manu")
+	mentionErr := manager.PublishTweet(tokens[1], mention)
+
+	//Validation
+	utility.ValidateExpectedError(t, replyErr, "Couldn't reply, You can't interact with that user")
+	utility.ValidateExpectedError(t, quoteErr, "Couldn't quote, You can't interact with that user")
+	utility.ValidateExpectedError(t, mentionErr, "Couldn't mention @manu, You can't interact with that user")
+}
+
+func TestBlockerCantMentionBlockedUser(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	manager.BlockUser(tokens[0], "gonza")
+	mention, _ := manager.NewTextTweet(domain.NewUser("manu", ""), "hola @gonza")
+
+	//Operation
+	err := manager.PublishTweet(tokens[0], mention)
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't mention @gonza, You can't interact with that user")
+}
+
+func TestBlocksHideTweetsOfEachSide(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	manager.FollowUser(tokens[0], "root")
+	manager.FollowUser(tokens[1], "root")
+	manuTweet := publishText(t, &manager, tokens[0], "manu", "from manu")
+	gonzaTweet := publishText(t, &manager, tokens[1], "gonza", "from gonza")
+	retweet, _ := manager.Retweet(tokens[2], gonzaTweet.GetID())
+	quote, _ := manager.QuoteTweet(tokens[2], manuTweet.GetID(), "look")
+
+	//Operation
+	manager.BlockUser(tokens[0], "gonza")
+
+	//Validation
+	manuTimeline, _ := manager.GetTimeline(tokens[0])
+	expectTweets(t, manuTimeline, []domain.Tweeter{quote, manuTweet})
+	gonzaTimeline, _ := manager.GetTimeline(tokens[1])
+	expectTweets(t, gonzaTimeline, []domain.Tweeter{retweet, gonzaTweet})
+}
+
+func TestLikesAndRetweetsOfBlockedUsersAreHidden(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	tweet := publishText(t, &manager, tokens[1], "gonza", "from gonza")
+	manager.LikeTweet(tokens[2], tweet.GetID())
+	manager.BlockUser(tokens[1], "manu")
+
+	//Operation
+	_, likesErr := manager.GetLikes(tokens[0], tweet.GetID())
+	_, countErr := manager.GetRetweetCount(tokens[0], tweet.GetID())
+	likes, _ := manager.GetLikes("", tweet.GetID())
+
+	//Validation
+	utility.ValidateExpectedError(t, likesErr, "A tweet with that ID does not exist")
+	utility.ValidateExpectedError(t, countErr, "A tweet with that ID does not exist")
+	expectUsers(t, likes, "root")
+}
+
+func TestBookmarksOfBlockedUsersAreHidden(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	tweet := publishText(t, &manager, tokens[1], "gonza", "from gonza")
+	manager.BookmarkTweet(tokens[0], tweet.GetID())
+
+	//Operation
+	manager.BlockUser(tokens[1], "manu")
+
+	//Validation
+	if bookmarks, _ := manager.GetBookmarks(tokens[0]); len(bookmarks) != 0 {
+		t.Errorf("Expected no bookmarks but got %d", len(bookmarks))
+	}
+}
+
+func TestUnblockingAllowsFollowingAgain(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	manager.BlockUser(tokens[0], "gonza")
+
+	//Operation
+	err := manager.UnblockUser(tokens[0], "gonza")
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if err := manager.FollowUser(tokens[1], "manu"); err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+	}
+}
+
+func TestCantBlockYourself(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+
+	//Operation
+	err := manager.BlockUser(tokens[0], "manu")
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't block user, Can't block yourself")
+}
+
+func TestCantUnblockUserThatIsNotBlocked(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+
+	//Operation
+	err := manager.UnblockUser(tokens[0], "gonza")
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't unblock user, That user is not blocked")
+}
+
+func TestMutingHidesTweetsOnlyFromOwnTimeline(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	manager.FollowUser(tokens[0], "gonza")
+	manager.FollowUser(tokens[1], "manu")
+	manuTweet := publishText(t, &manager, tokens[0], "manu", "from manu")
+	gonzaTweet := publishText(t, &manager, tokens[1], "gonza", "from gonza")
+
+	//Operation
+	err := manager.MuteUser(tokens[0], "gonza")
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	manuTimeline, _ := manager.GetTimeline(tokens[0])
+	expectTweets(t, manuTimeline, []domain.Tweeter{manuTweet})
+	gonzaTimeline, _ := manager.GetTimeline(tokens[1])
+	expectTweets(t, gonzaTimeline, []domain.Tweeter{gonzaTweet, manuTweet})
+	if following, _ := manager.GetFollowing(domain.NewUser("manu", "")); len(following) != 1 {
+		t.Error("Muting should not remove follows")
+	}
+}
+
+func TestUnmutingShowsTweetsAgain(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	manager.FollowUser(tokens[0], "gonza")
+	gonzaTweet := publishText(t, &manager, tokens[1], "gonza", "from gonza")
+	manager.MuteUser(tokens[0], "gonza")
+
+	//Operation
+	err := manager.UnmuteUser(tokens[0], "gonza")
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	timeline, _ := manager.GetTimeline(tokens[0])
+	expectTweets(t, timeline, []domain.Tweeter{gonzaTweet})
+	if muted, _ := manager.GetMutedUsers(tokens[0]); len(muted) != 0 {
+		t.Errorf("Expected no muted users but got %v", muted)
+	}
+}
+
+func TestCantMuteTwice(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	manager.MuteUser(tokens[0], "gonza")
+
+	//Operation
+	err := manager.MuteUser(tokens[0], "gonza")
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't mute user, That user is already muted")
+}
+
+func TestBlocksAndMutesSurviveRestart(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+	var manager service.TweetManager
+	manager.InitializeManagerWithRepository(service.NewFileTweetRepository(path))
+	tokens := newSocialManager(&manager)
+	manager.BlockUser(tokens[0], "gonza")
+	manager.MuteUser(tokens[0], "root")
+
+	//Operation
+	var restarted service.TweetManager
+	restarted.InitializeManagerWithRepository(service.NewFileTweetRepository(path))
+	token, _ := restarted.Login(domain.NewUser("manu", "hunter2"))
+
+	//Validation
+	blocked, _ := restarted.GetBlockedUsers(token)
+	expectUsers(t, blocked, "gonza")
+	muted, _ := restarted.GetMutedUsers(token)
+	expectUsers(t, muted, "root")
+}
+
+func TestBlocksAreReplayedFromEventLog(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+	var manager service.TweetManager
+	repository := service.NewEventLogRepository(path, 0)
+	manager.InitializeManagerWithRepository(repository)
+	tokens := newSocialManager(&manager)
+	manager.FollowUser(tokens[1], "manu")
+	manager.BlockUser(tokens[0], "gonza")
+	manager.MuteUser(tokens[0], "root")
+	manager.UnmuteUser(tokens[0], "root")
+	repository.Close()
+
+	//Operation
+	var restarted service.TweetManager
+	restartedRepository := service.NewEventLogRepository(path, 0)
+	restarted.InitializeManagerWithRepository(restartedRepository)
+	defer restartedRepository.Close()
+	token, _ := restarted.Login(domain.NewUser("manu", "hunter2"))
+
+	//Validation
+	blocked, _ := restarted.GetBlockedUsers(token)
+	expectUsers(t, blocked, "gonza")
+	muted, _ := restarted.GetMutedUsers(token)
+	expectUsers(t, muted)
+	followers, _ := restarted.GetFollowers(domain.NewUser("manu", ""))
+	expectUsers(t, followers)
+}
@@ -0,0 +1,194 @@
+package service_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/service"
+)
+
+const (
+	concurrentUsers  = 8
+	tweetsPerUser    = 50
+	concurrentReads  = 20
+	concurrentWrites = 20
+)
+
+//registerConcurrentUsers registers n users and logs each of them in, returning their tokens
+func registerConcurrentUsers(t *testing.T, manager *service.TweetManager, n int) ([]domain.User, []string) {
+	var users []domain.User
+	var tokens []string
+	for i := 0; i < n; i++ {
+		user := domain.NewUser(fmt.Sprintf("user%d", i), "password")
+		manager.Register(user)
+		token, err := manager.Login(user)
+		if err != nil {
+			t.Fatalf("Unexpected error, %s", err.Error())
+		}
+		users = append(users, user)
+		tokens = append(tokens, token)
+	}
+	return users, tokens
+}
+
+func TestConcurrentPublishesDontLoseTweets(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	users, tokens := registerConcurrentUsers(t, &manager, concurrentUsers)
+
+	//Operation
+	var wg sync.WaitGroup
+	for i := range users {
+		wg.Add(1)
+		go func(user domain.User, token string) {
+			defer wg.Done()
+			for j := 0; j < tweetsPerUser; j++ {
+				tweet, _ := manager.NewTextTweet(user, fmt.Sprintf("tweet %d", j))
+				if err := manager.PublishTweet(token, tweet); err != nil {
+					t.Errorf("Unexpected error, %s", err.Error())
+				}
+				manager.GetTimeline(token)
+			}
+		}(users[i], tokens[i])
+	}
+	wg.Wait()
+
+	//Validation
+	ids := make(map[int]bool)
+	for _, user := range users {
+		tweets, _ := manager.GetTweetsFromUser(user)
+		if len(tweets) != tweetsPerUser {
+			t.Errorf("Expected size is %d but was %d", tweetsPerUser, len(tweets))
+		}
+		for _, tweet := range tweets {
+			if ids[tweet.GetID()] {
+				t.Errorf("Tweet ID %d was given twice", tweet.GetID())
+			}
+			ids[tweet.GetID()] = true
+		}
+	}
+}
+
+func TestConcurrentLoginsGetDifferentSessions(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	user := domain.NewUser("root", "root")
+	manager.Register(user)
+
+	//Operation
+	var wg sync.WaitGroup
+	tokens := make([]string, concurrentWrites)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tokens[i], _ = manager.Login(user)
+		}(i)
+	}
+	wg.Wait()
+
+	//Validation
+	seen := make(map[string]bool)
+	for _, token := range tokens {
+		if token == "" || seen[token] {
+			t.Errorf("Expected a new session but got %q", token)
+		}
+		seen[token] = true
+		if _, err := manager.GetLoggedInUser(token); err != nil {
+			t.Errorf("Unexpected error, %s", err.Error())
+		}
+	}
+}
+
+func TestConcurrentEditsAndReadsOfTheSameTweet(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	users, tokens := registerConcurrentUsers(t, &manager, 2)
+	manager.FollowUser(tokens[1], users[0].Name)
+	tweet, _ := manager.NewTextTweet(users[0], "original")
+	manager.PublishTweet(tokens[0], tweet)
+
+	//Operation
+	var wg sync.WaitGroup
+	for i := 0; i < concurrentWrites; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			manager.EditTweetTextByID(tokens[0], tweet.GetID(), fmt.Sprintf("edit %d", i))
+		}(i)
+	}
+	for i := 0; i < concurrentReads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			timeline, _ := manager.GetTimeline(tokens[1])
+			for _, tw := range timeline {
+				_ = tw.String()
+			}
+		}()
+	}
+	wg.Wait()
+
+	//Validation
+	edited, err := manager.GetTweetByID(tweet.GetID())
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if edited.GetText() == "original" {
+		t.Error("Expected the tweet to be edited")
+	}
+}
+
+func TestConcurrentDeletesAndFollows(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	users, tokens := registerConcurrentUsers(t, &manager, concurrentUsers)
+	var tweets []domain.Tweeter
+	for i := 0; i < tweetsPerUser; i++ {
+		tweet, _ := manager.NewTextTweet(users[0], fmt.Sprintf("tweet %d", i))
+		manager.PublishTweet(tokens[0], tweet)
+		tweets = append(tweets, tweet)
+	}
+
+	//Operation
+	var wg sync.WaitGroup
+	for _, tweet := range tweets {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			if err := manager.DeleteTweetByID(tokens[0], id); err != nil {
+				t.Errorf("Unexpected error, %s", err.Error())
+			}
+		}(tweet.GetID())
+	}
+	for i := 1; i < len(users); i++ {
+		wg.Add(1)
+		go func(token string) {
+			defer wg.Done()
+			if err := manager.FollowUser(token, users[0].Name); err != nil {
+				t.Errorf("Unexpected error, %s", err.Error())
+			}
+			manager.GetTimeline(token)
+		}(tokens[i])
+	}
+	wg.Wait()
+
+	//Validation
+	remaining, _ := manager.GetTweetsFromUser(users[0])
+	if len(remaining) != 0 {
+		t.Errorf("Expected size is 0 but was %d", len(remaining))
+	}
+	for i := 1; i < len(users); i++ {
+		user, _ := manager.GetLoggedInUser(tokens[i])
+		if len(user.Following) != 1 {
+			t.Errorf("Expected %s to follow 1 user but follows %d", user.Name, len(user.Following))
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cursoGo/src/domain"
+)
+
+//DefaultSchedulerInterval is how often a DraftManager looks for scheduled drafts that are due
+const DefaultSchedulerInterval = time.Second
+
+//DraftManager keeps the drafts of the users of a TweetManager and publishes the scheduled ones when
+//their time comes, whether their authors are logged in or not. It is safe for concurrent use
+type DraftManager struct {
+	tweets     *TweetManager
+	repository DraftRepository
+	clock      domain.Clock
+	mutex      sync.Mutex
+	once       sync.Once
+	done       chan struct{}
+	stopped    chan struct{}
+}
+
+//NewDraftManager returns a DraftManager for the users of tweets that keeps drafts in memory
+func NewDraftManager(tweets *TweetManager) *DraftManager {
+	return NewDraftManagerWith(tweets, NewMemoryDraftRepository(), domain.SystemClock{}, DefaultSchedulerInterval)
+}
+
+//NewDraftManagerWith returns a DraftManager for the users of tweets that stores drafts in repository,
+//tells the time with clock and looks for scheduled drafts that are due every interval
+func NewDraftManagerWith(tweets *TweetManager, repository DraftRepository, clock domain.Clock, interval time.Duration) *DraftManager {
+	m := &DraftManager{
+		tweets:     tweets,
+		repository: repository,
+		clock:      clock,
+		done:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+	go m.run(interval)
+	return m
+}
+
+//Close stops publishing scheduled drafts. They are kept, and published by no one until another
+//DraftManager with the same repository is made
+func (m *DraftManager) Close() {
+	m.once.Do(func() {
+		close(m.done)
+	})
+	<-m.stopped
+}
+
+func (m *DraftManager) run(interval time.Duration) {
+	defer close(m.stopped)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.PublishDue()
+		case <-m.done:
+			return
+		}
+	}
+}
+
+//SaveDraft saves a draft of the user logged in with a session. It is a draft of an image tweet
+//if it has an image URL, and of a text tweet if it doesn't
+func (m *DraftManager) SaveDraft(token string, text string, imageURL string) (*domain.Draft, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	draft, err := m.saveDraft(token, text, imageURL)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't save draft, %w", err)
+	}
+	return draft, nil
+}
+
+func (m *DraftManager) saveDraft(token string, text string, imageURL string) (*domain.Draft, error) {
+	user, err := m.tweets.GetLoggedInUser(token)
+	if err != nil {
+		return nil, err
+	}
+	draft, err := domain.NewDraft(0, *user, text, imageURL, m.clock.Now(), m.tweets.MaxTweetLength())
+	if err != nil {
+		return nil, err
+	}
+	draft.ID, err = m.repository.AddDraft(*draft)
+	if err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+//GetDrafts returns the drafts of the user logged in with a session that aren't scheduled, in the order they were saved
+func (m *DraftManager) GetDrafts(token string) ([]domain.Draft, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	drafts, err := m.draftsOf(token)
+	if err != nil {
+		return nil, err
+	}
+	var unscheduled []domain.Draft
+	for _, draft := range drafts {
+		if !draft.IsScheduled() {
+			unscheduled = append(unscheduled, draft)
+		}
+	}
+	return unscheduled, nil
+}
+
+//GetScheduledTweets returns the scheduled drafts of the user logged in with a session, the one published first first
+func (m *DraftManager) GetScheduledTweets(token string) ([]domain.Draft, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	drafts, err := m.draftsOf(token)
+	if err != nil {
+		return nil, err
+	}
+	var scheduled []domain.Draft
+	for _, draft := range drafts {
+		if draft.IsScheduled() {
+			scheduled = append(scheduled, draft)
+		}
+	}
+	sortByPublishDate(scheduled)
+	return scheduled, nil
+}
+
+func (m *DraftManager) draftsOf(token string) ([]domain.Draft, error) {
+	user, err := m.tweets.GetLoggedInUser(token)
+	if err != nil {
+		return nil, err
+	}
+	return m.repository.GetDraftsOf(user.ID), nil
+}
+
+//EditDraft changes the text and image URL of a draft of the user logged in with a session.
+//A scheduled draft stays scheduled
+func (m *DraftManager) EditDraft(token string, id int, text string, imageURL string) (*domain.Draft, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	draft, err := m.ownDraft(token, id)
+	if err == nil {
+		err = draft.SetContent(text, imageURL, m.tweets.MaxTweetLength())
+	}
+	if err == nil {
+		err = m.repository.UpdateDraft(*draft)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't edit draft, %w", err)
+	}
+	return draft, nil
+}
+
+//DeleteDraft deletes a draft of the user logged in with a session, which is never published if it was scheduled
+func (m *DraftManager) DeleteDraft(token string, id int) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	_, err := m.ownDraft(token, id)
+	if err == nil {
+		err = m.repository.RemoveDraft(id)
+	}
+	if err != nil {
+		return fmt.Errorf("Couldn't delete draft, %w", err)
+	}
+	return nil
+}
+
+//ScheduleDraft schedules a draft of the user logged in with a session to be published at a future time.
+//A scheduled draft can be scheduled again for another time
+func (m *DraftManager) ScheduleDraft(token string, id int, publishAt time.Time) (*domain.Draft, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	draft, err := m.ownDraft(token, id)
+	if err == nil {
+		err = m.schedule(draft, publishAt)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't schedule tweet, %w", err)
+	}
+	return draft, nil
+}
+
+//ScheduleTweet saves a draft of the user logged in with a session and schedules it to be published at a future time
+func (m *DraftManager) ScheduleTweet(token string, text string, imageURL string, publishAt time.Time) (*domain.Draft, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if !publishAt.After(m.clock.Now()) {
+		return nil, fmt.Errorf("Couldn't schedule tweet, %w", ErrScheduleInThePast)
+	}
+	draft, err := m.saveDraft(token, text, imageURL)
+	if err == nil {
+		err = m.schedule(draft, publishAt)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't schedule tweet, %w", err)
+	}
+	return draft, nil
+}
+
+func (m *DraftManager) schedule(draft *domain.Draft, publishAt time.Time) error {
+	if !publishAt.After(m.clock.Now()) {
+		return ErrScheduleInThePast
+	}
+	draft.PublishAt = &publishAt
+	draft.Error = ""
+	return m.repository.UpdateDraft(*draft)
+}
+
+//UnscheduleDraft makes a scheduled draft of the user logged in with a session a plain draft again
+func (m *DraftManager) UnscheduleDraft(token string, id int) (*domain.Draft, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	draft, err := m.ownDraft(token, id)
+	if err == nil {
+		draft.PublishAt = nil
+		err = m.repository.UpdateDraft(*draft)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't unschedule tweet, %w", err)
+	}
+	return draft, nil
+}
+
+//PublishDraft publishes a draft of the user logged in with a session right away, and deletes it
+func (m *DraftManager) PublishDraft(token string, id int) (domain.Tweeter, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	draft, err := m.ownDraft(token, id)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't publish draft, %w", err)
+	}
+	return m.publish(*draft)
+}
+
+//ownDraft returns the draft with that ID, if it belongs to the user logged in with a session.
+//The drafts of others are never found
+func (m *DraftManager) ownDraft(token string, id int) (*domain.Draft, error) {
+	user, err := m.tweets.GetLoggedInUser(token)
+	if err != nil {
+		return nil, err
+	}
+	draft, err := m.repository.GetDraft(id)
+	if err != nil {
+		return nil, err
+	}
+	if draft.Author.ID != user.ID {
+		return nil, ErrDraftNotFound
+	}
+	return draft, nil
+}
+
+//PublishDue publishes the scheduled drafts that are due, the ones scheduled earlier first, and returns the
+//published tweets. Drafts that can't be published anymore are kept unscheduled, with the reason why.
+//It is called every interval, but can be called anytime
+func (m *DraftManager) PublishDue() []domain.Tweeter {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	now := m.clock.Now()
+	var due []domain.Draft
+	for _, draft := range m.repository.GetScheduledDrafts() {
+		if draft.IsDue(now) {
+			due = append(due, draft)
+		}
+	}
+	sortByPublishDate(due)
+	var published []domain.Tweeter
+	for _, draft := range due {
+		tweet, err := m.publish(draft)
+		if err != nil {
+			draft.PublishAt = nil
+			draft.Error = err.Error()
+			if err := m.repository.UpdateDraft(draft); err != nil {
+				//It stays scheduled, so publishing it is tried again the next time
+				log.Printf("Couldn't unschedule draft %d, %s", draft.ID, err.Error())
+			}
+			continue
+		}
+		published = append(published, tweet)
+	}
+	return published
+}
+
+//publish publishes a draft as a tweet of its author, and deletes it. Once the tweet is published it isn't
+//a failure if the draft can't be deleted, as publishing it again would duplicate the tweet: it is kept
+//unscheduled instead, so that the scheduler doesn't publish it again
+func (m *DraftManager) publish(draft domain.Draft) (domain.Tweeter, error) {
+	tweet, err := m.tweets.publishDraft(draft)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't publish draft, %w", err)
+	}
+	if err := m.repository.RemoveDraft(draft.ID); err != nil {
+		log.Printf("Couldn't delete published draft %d, %s", draft.ID, err.Error())
+		if draft.IsScheduled() {
+			draft.PublishAt = nil
+			if err := m.repository.UpdateDraft(draft); err != nil {
+				log.Printf("Couldn't unschedule published draft %d, %s", draft.ID, err.Error())
+			}
+		}
+	}
+	return tweet, nil
+}
+
+//sortByPublishDate sorts scheduled drafts, the one published first first
+func sortByPublishDate(drafts []domain.Draft) {
+	sort.SliceStable(drafts, func(i, j int) bool {
+		first, second := drafts[i].PublishAt, drafts[j].PublishAt
+		if !first.Equal(*second) {
+			return first.Before(*second)
+		}
+		return drafts[i].ID < drafts[j].ID
+	})
+}
+
+//publishDraft publishes a text or image tweet of the author of a draft, as if they published it themselves.
+//It doesn't need them to be logged in
+func (m *TweetManager) publishDraft(draft domain.Draft) (domain.Tweeter, error) {
+	defer m.publishEvents()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	author, err := m.repository.GetUserByID(draft.Author.ID)
+	if err != nil {
+		return nil, err
+	}
+	var tweet domain.Tweeter
+	if draft.ImageURL != "" {
+		tweet, err = m.tweets.NewImageTweet(*author, draft.Text, draft.ImageURL)
+	} else {
+		tweet, err = m.tweets.NewTextTweet(*author, draft.Text)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := m.publishTweet(tweet); err != nil {
+		return nil, err
+	}
+	return tweet, nil
+}
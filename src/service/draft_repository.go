@@ -0,0 +1,84 @@
+package service
+
+import (
+	"sort"
+
+	"github.com/cursoGo/src/domain"
+)
+
+//DraftRepository is where the DraftManager stores the drafts of every user, scheduled or not
+type DraftRepository interface {
+	AddDraft(domain.Draft) (int, error)
+	GetDraft(id int) (*domain.Draft, error)
+	UpdateDraft(domain.Draft) error
+	RemoveDraft(id int) error
+	GetDraftsOf(user int) []domain.Draft
+	GetScheduledDrafts() []domain.Draft
+}
+
+//MemoryDraftRepository is a DraftRepository that keeps everything in memory
+type MemoryDraftRepository struct {
+	drafts map[int]domain.Draft
+	lastID int
+}
+
+//NewMemoryDraftRepository returns a new empty MemoryDraftRepository
+func NewMemoryDraftRepository() *MemoryDraftRepository {
+	return &MemoryDraftRepository{drafts: make(map[int]domain.Draft), lastID: -1}
+}
+
+//AddDraft stores a draft and returns the ID given to it
+func (r *MemoryDraftRepository) AddDraft(draft domain.Draft) (int, error) {
+	r.lastID++
+	draft.ID = r.lastID
+	r.drafts[draft.ID] = draft
+	return draft.ID, nil
+}
+
+//GetDraft returns the draft with that ID
+func (r *MemoryDraftRepository) GetDraft(id int) (*domain.Draft, error) {
+	draft, ok := r.drafts[id]
+	if !ok {
+		return nil, ErrDraftNotFound
+	}
+	return &draft, nil
+}
+
+//UpdateDraft replaces the stored draft that has the ID of draft
+func (r *MemoryDraftRepository) UpdateDraft(draft domain.Draft) error {
+	if _, ok := r.drafts[draft.ID]; !ok {
+		return ErrDraftNotFound
+	}
+	r.drafts[draft.ID] = draft
+	return nil
+}
+
+//RemoveDraft removes the draft with that ID
+func (r *MemoryDraftRepository) RemoveDraft(id int) error {
+	if _, ok := r.drafts[id]; !ok {
+		return ErrDraftNotFound
+	}
+	delete(r.drafts, id)
+	return nil
+}
+
+//GetDraftsOf returns the drafts of a user, scheduled or not, in the order they were added
+func (r *MemoryDraftRepository) GetDraftsOf(user int) []domain.Draft {
+	return r.filter(func(draft domain.Draft) bool { return draft.Author.ID == user })
+}
+
+//GetScheduledDrafts returns the scheduled drafts of every user, in the order they were added
+func (r *MemoryDraftRepository) GetScheduledDrafts() []domain.Draft {
+	return r.filter(domain.Draft.IsScheduled)
+}
+
+func (r *MemoryDraftRepository) filter(keep func(domain.Draft) bool) []domain.Draft {
+	var drafts []domain.Draft
+	for _, draft := range r.drafts {
+		if keep(draft) {
+			drafts = append(drafts, draft)
+		}
+	}
+	sort.Slice(drafts, func(i, j int) bool { return drafts[i].ID < drafts[j].ID })
+	return drafts
+}
@@ -0,0 +1,250 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/service"
+	"github.com/cursoGo/src/utility"
+)
+
+//newDraftManager returns a DraftManager for the users of newSocialManager that looks for due drafts every interval,
+//the fake clock it shares with the manager and their tokens
+func newDraftManager(manager *service.TweetManager, interval time.Duration) (*service.DraftManager, *domain.FixedClock, []string) {
+	clock := domain.NewFixedClock(time.Date(2018, time.March, 1, 12, 0, 0, 0, time.UTC))
+	manager.InitializeManagerWith(service.NewMemoryTweetRepository(), domain.NewSequentialIDGenerator(), clock)
+	tokens := newSocialManager(manager)
+	return service.NewDraftManagerWith(manager, service.NewMemoryDraftRepository(), clock, interval), clock, tokens
+}
+
+//expectDrafts fails the test if the texts of the drafts aren't the expected ones, in order
+func expectDrafts(t *testing.T, got []domain.Draft, expected ...string) {
+	if len(got) != len(expected) {
+		t.Errorf("Expected %d drafts but got %d", len(expected), len(got))
+		return
+	}
+	for i, draft := range got {
+		if draft.Text != expected[i] {
+			t.Errorf("Expected draft %q but got %q", expected[i], draft.Text)
+		}
+	}
+}
+
+func TestDraftsCanBeSavedEditedAndDeleted(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	drafts, _, tokens := newDraftManager(&manager, time.Hour)
+	defer drafts.Close()
+	first, _ := drafts.SaveDraft(tokens[0], "hola", "")
+	second, _ := drafts.SaveDraft(tokens[0], "una foto", "http://img")
+	drafts.SaveDraft(tokens[1], "de gonza", "")
+
+	//Operation
+	edited, err := drafts.EditDraft(tokens[0], second.ID, "otra foto", "http://img2")
+	if err == nil {
+		err = drafts.DeleteDraft(tokens[0], first.ID)
+	}
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if edited.ImageURL != "http://img2" {
+		t.Errorf("Expected the image of the draft to be edited but got %s", edited.ImageURL)
+	}
+	own, _ := drafts.GetDrafts(tokens[0])
+	expectDrafts(t, own, "otra foto")
+	tweets, _ := manager.GetTweetsFromUser(domain.NewUser("manu", ""))
+	if len(tweets) != 0 {
+		t.Error("Drafts should not be published")
+	}
+}
+
+func TestDraftsOfOthersAreNotFound(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	drafts, _, tokens := newDraftManager(&manager, time.Hour)
+	defer drafts.Close()
+	draft, _ := drafts.SaveDraft(tokens[0], "hola", "")
+
+	//Operation
+	_, err := drafts.EditDraft(tokens[1], draft.ID, "chau", "")
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't edit draft, A draft with that ID does not exist")
+	utility.ValidateExpectedError(t, drafts.DeleteDraft(tokens[1], draft.ID), "Couldn't delete draft, A draft with that ID does not exist")
+}
+
+//undeletableDraftRepository is a MemoryDraftRepository where drafts can't be removed
+type undeletableDraftRepository struct {
+	*service.MemoryDraftRepository
+}
+
+func (undeletableDraftRepository) RemoveDraft(id int) error {
+	return errors.New("read-only")
+}
+
+func TestDraftIsPublishedRightAway(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	drafts, _, tokens := newDraftManager(&manager, time.Hour)
+	defer drafts.Close()
+	draft, _ := drafts.SaveDraft(tokens[0], "una foto", "http://img")
+
+	//Operation
+	tweet, err := drafts.PublishDraft(tokens[0], draft.ID)
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if image, ok := tweet.(*domain.ImageTweet); !ok || image.GetURL() != "http://img" {
+		t.Errorf("Expected an image tweet but got %s", tweet)
+	}
+	left, _ := drafts.GetDrafts(tokens[0])
+	expectDrafts(t, left)
+}
+
+func TestCantScheduleTweetInThePast(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	drafts, clock, tokens := newDraftManager(&manager, time.Hour)
+	defer drafts.Close()
+
+	//Operation
+	_, err := drafts.ScheduleTweet(tokens[0], "hola", "", clock.Now())
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't schedule tweet, A tweet can only be scheduled for the future")
+	left, _ := drafts.GetDrafts(tokens[0])
+	expectDrafts(t, left)
+}
+
+func TestScheduledTweetIsPublishedWhenDueEvenIfLoggedOut(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	drafts, clock, tokens := newDraftManager(&manager, time.Hour)
+	defer drafts.Close()
+	drafts.ScheduleTweet(tokens[0], "en dos horas", "", clock.Now().Add(2*time.Hour))
+	drafts.ScheduleTweet(tokens[0], "en una hora", "", clock.Now().Add(time.Hour))
+	scheduled, _ := drafts.GetScheduledTweets(tokens[0])
+	manager.Logout(tokens[0])
+
+	//Operation
+	clock.Advance(30 * time.Minute)
+	early := drafts.PublishDue()
+	clock.Advance(2 * time.Hour)
+	published := drafts.PublishDue()
+
+	//Validation
+	expectDrafts(t, scheduled, "en una hora", "en dos horas")
+	if len(early) != 0 {
+		t.Errorf("Expected no tweets to be due yet but got %d", len(early))
+	}
+	if len(published) != 2 || published[0].GetText() != "en una hora" || published[1].GetText() != "en dos horas" {
+		t.Errorf("Expected the scheduled tweets in order but got %v", published)
+		return
+	}
+	if published[1].GetUser().Name != "manu" || !published[1].GetDate().Equal(clock.Now()) {
+		t.Errorf("Unexpected tweet %s published at %s", published[1], published[1].GetDate())
+	}
+	tweets, _ := manager.GetTweetsFromUser(domain.NewUser("manu", ""))
+	if len(tweets) != 2 || !manager.TweetExists(published[0]) || !manager.TweetExists(published[1]) {
+		t.Error("The scheduled tweets should be published")
+	}
+}
+
+func TestUnscheduledDraftIsNotPublished(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	drafts, clock, tokens := newDraftManager(&manager, time.Hour)
+	defer drafts.Close()
+	draft, _ := drafts.ScheduleTweet(tokens[0], "hola", "", clock.Now().Add(time.Hour))
+
+	//Operation
+	drafts.UnscheduleDraft(tokens[0], draft.ID)
+	clock.Advance(2 * time.Hour)
+	published := drafts.PublishDue()
+
+	//Validation
+	if len(published) != 0 {
+		t.Errorf("Expected no tweets to be published but got %d", len(published))
+	}
+	left, _ := drafts.GetDrafts(tokens[0])
+	expectDrafts(t, left, "hola")
+}
+
+func TestScheduledTweetThatCantBePublishedGoesBackToDrafts(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	drafts, clock, tokens := newDraftManager(&manager, time.Hour)
+	defer drafts.Close()
+	drafts.ScheduleTweet(tokens[0], "hola @gonza", "", clock.Now().Add(time.Hour))
+	manager.BlockUser(tokens[1], "manu")
+
+	//Operation
+	clock.Advance(time.Hour)
+	published := drafts.PublishDue()
+
+	//Validation
+	if len(published) != 0 {
+		t.Errorf("Expected no tweets to be published but got %d", len(published))
+	}
+	left, _ := drafts.GetDrafts(tokens[0])
+	expectDrafts(t, left, "hola @gonza")
+	if len(left) == 1 && left[0].Error != "Couldn't publish draft, Couldn't mention @gonza, You can't interact with that user" {
+		t.Errorf("Unexpected error %q", left[0].Error)
+	}
+}
+
+func TestSchedulerPublishesDueTweetsInTheBackground(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	drafts, clock, tokens := newDraftManager(&manager, time.Millisecond)
+	defer drafts.Close()
+	drafts.ScheduleTweet(tokens[0], "hola", "", clock.Now().Add(time.Minute))
+
+	//Operation
+	clock.Advance(time.Minute)
+
+	//Validation
+	eventually(t, func() bool {
+		tweets, _ := manager.GetTweetsFromUser(domain.NewUser("manu", ""))
+		return len(tweets) == 1
+	})
+}
+
+func TestScheduledTweetThatCantBeDeletedIsPublishedOnce(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	clock := domain.NewFixedClock(time.Date(2018, time.March, 1, 12, 0, 0, 0, time.UTC))
+	manager.InitializeManagerWith(service.NewMemoryTweetRepository(), domain.NewSequentialIDGenerator(), clock)
+	tokens := newSocialManager(&manager)
+	repository := undeletableDraftRepository{service.NewMemoryDraftRepository()}
+	drafts := service.NewDraftManagerWith(&manager, repository, clock, time.Hour)
+	defer drafts.Close()
+	draft, _ := drafts.ScheduleTweet(tokens[0], "hola", "", clock.Now().Add(time.Hour))
+
+	//Operation
+	clock.Advance(time.Hour)
+	published := drafts.PublishDue()
+	clock.Advance(time.Hour)
+	drafts.PublishDue()
+
+	//Validation
+	if len(published) != 1 {
+		t.Errorf("Expected the tweet to be published but got %d tweets", len(published))
+	}
+	left, _ := repository.GetDraft(draft.ID)
+	if left.Error != "" || left.IsScheduled() {
+		t.Errorf("Expected the draft to be unscheduled without an error but got %+v", left)
+	}
+	tweets, _ := manager.GetTweetsFromUser(domain.NewUser("manu", ""))
+	if len(tweets) != 1 {
+		t.Errorf("Expected 1 tweet but got %d", len(tweets))
+	}
+}
@@ -0,0 +1,48 @@
+package service
+
+import "github.com/cursoGo/src/domain"
+
+//entityIndex maps the hashtags or mentions of the stored tweets to the IDs of the tweets that have them
+type entityIndex struct {
+	tweets   map[string][]int
+	entities map[int][]string
+	extract  func(text string) []string
+}
+
+//newEntityIndex returns an empty entityIndex that takes the entities of a text with extract
+func newEntityIndex(extract func(text string) []string) *entityIndex {
+	return &entityIndex{
+		tweets:   make(map[string][]int),
+		entities: make(map[int][]string),
+		extract:  extract,
+	}
+}
+
+//add indexes a tweet by the entities of its current text, forgetting the ones of its previous text
+func (i *entityIndex) add(tweet domain.Tweeter) {
+	id := tweet.GetID()
+	i.remove(id)
+	entities := i.extract(tweet.GetText())
+	for _, entity := range entities {
+		i.tweets[entity] = append(i.tweets[entity], id)
+	}
+	if len(entities) > 0 {
+		i.entities[id] = entities
+	}
+}
+
+//remove forgets the entities of the tweet with that ID
+func (i *entityIndex) remove(id int) {
+	for _, entity := range i.entities[id] {
+		i.tweets[entity] = removeID(i.tweets[entity], id)
+		if len(i.tweets[entity]) == 0 {
+			delete(i.tweets, entity)
+		}
+	}
+	delete(i.entities, id)
+}
+
+//get returns the IDs of the tweets that have an entity, in the order they were indexed
+func (i *entityIndex) get(entity string) []int {
+	return append([]int(nil), i.tweets[entity]...)
+}
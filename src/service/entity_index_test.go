@@ -0,0 +1,164 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/service"
+	"github.com/cursoGo/src/utility"
+)
+
+func TestCanGetTweetsByHashtagNewestFirst(t *testing.T) {
+	//Initialization
+	manager, _, _ := newTimelineManager(t, 0)
+	gonza := domain.NewUser("gonza", "hunter3")
+	token, _ := manager.Login(gonza)
+	first, _ := manager.NewTextTweet(gonza, "aprendiendo #Go")
+	second, _ := manager.NewTextTweet(gonza, "sin hashtag")
+	third, _ := manager.NewTextTweet(gonza, "más #go")
+	manager.PublishTweet(token, first)
+	manager.PublishTweet(token, second)
+	manager.PublishTweet(token, third)
+
+	//Operation
+	tweets := manager.GetTweetsWithHashtag("#GO")
+
+	//Validation
+	expectTweets(t, tweets, []domain.Tweeter{third, first})
+}
+
+func TestCanGetMentions(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	manuToken, gonzaToken, _, _ := newRetweetManager(t, &manager)
+	mention, _ := manager.NewTextTweet(domain.NewUser("gonza", ""), "hola @manu")
+	manager.PublishTweet(gonzaToken, mention)
+
+	//Operation
+	mentions, err := manager.GetMentions(manuToken)
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	expectTweets(t, mentions, []domain.Tweeter{mention})
+}
+
+func TestCantMentionUnregisteredUser(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	_, gonzaToken, _, _ := newRetweetManager(t, &manager)
+	mention, _ := manager.NewTextTweet(domain.NewUser("gonza", ""), "hola @nadie")
+
+	//Operation
+	err := manager.PublishTweet(gonzaToken, mention)
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't mention @nadie, That user is not registered")
+}
+
+func TestEditingTweetUpdatesIndexes(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	manuToken, gonzaToken, _, _ := newRetweetManager(t, &manager)
+	tweet, _ := manager.NewTextTweet(domain.NewUser("gonza", ""), "#viejo @manu")
+	manager.PublishTweet(gonzaToken, tweet)
+
+	//Operation
+	err := manager.EditTweetTextByID(gonzaToken, tweet.GetID(), "#nuevo @root")
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if old := manager.GetTweetsWithHashtag("viejo"); len(old) != 0 {
+		t.Errorf("Expected no tweets with the old hashtag but got %d", len(old))
+	}
+	expectTweets(t, manager.GetTweetsWithHashtag("nuevo"), []domain.Tweeter{tweet})
+	if mentions, _ := manager.GetMentions(manuToken); len(mentions) != 0 {
+		t.Errorf("Expected no mentions but got %d", len(mentions))
+	}
+	mentions, _ := manager.GetTweetsMentioning(domain.NewUser("root", ""))
+	expectTweets(t, mentions, []domain.Tweeter{tweet})
+}
+
+func TestCantEditTweetToMentionUnregisteredUser(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	_, gonzaToken, _, _ := newRetweetManager(t, &manager)
+	tweet, _ := manager.NewTextTweet(domain.NewUser("gonza", ""), "#viejo")
+	manager.PublishTweet(gonzaToken, tweet)
+
+	//Operation
+	err := manager.EditTweetTextByID(gonzaToken, tweet.GetID(), "hola @nadie")
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Coudln't edit tweet, Couldn't mention @nadie, That user is not registered")
+	if tweet.GetText() != "#viejo" {
+		t.Errorf("The tweet should not change, but was %s", tweet)
+	}
+}
+
+func TestDeletingTweetRemovesItFromIndexes(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	manuToken, gonzaToken, _, _ := newRetweetManager(t, &manager)
+	tweet, _ := manager.NewTextTweet(domain.NewUser("gonza", ""), "#chau @manu")
+	manager.PublishTweet(gonzaToken, tweet)
+
+	//Operation
+	manager.DeleteTweetByID(gonzaToken, tweet.GetID())
+
+	//Validation
+	if tweets := manager.GetTweetsWithHashtag("chau"); len(tweets) != 0 {
+		t.Errorf("Expected no tweets but got %d", len(tweets))
+	}
+	if mentions, _ := manager.GetMentions(manuToken); len(mentions) != 0 {
+		t.Errorf("Expected no mentions but got %d", len(mentions))
+	}
+}
+
+func TestRetweetsAreNotIndexed(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	_, gonzaToken, rootToken, _ := newRetweetManager(t, &manager)
+	tweet, _ := manager.NewTextTweet(domain.NewUser("root", ""), "#compartir")
+	manager.PublishTweet(rootToken, tweet)
+
+	//Operation
+	manager.Retweet(gonzaToken, tweet.GetID())
+
+	//Validation
+	expectTweets(t, manager.GetTweetsWithHashtag("compartir"), []domain.Tweeter{tweet})
+}
+
+func TestIndexesAreRebuiltAfterRestart(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+	var manager service.TweetManager
+	manager.InitializeManagerWithRepository(service.NewFileTweetRepository(path))
+	_, gonzaToken, _, _ := newRetweetManager(t, &manager)
+	tweet, _ := manager.NewTextTweet(domain.NewUser("gonza", ""), "#persistente @root")
+	manager.PublishTweet(gonzaToken, tweet)
+
+	//Operation
+	var restarted service.TweetManager
+	restarted.InitializeManagerWithRepository(service.NewFileTweetRepository(path))
+
+	//Validation
+	if tweets := restarted.GetTweetsWithHashtag("persistente"); len(tweets) != 1 {
+		t.Errorf("Expected 1 tweet but got %d", len(tweets))
+	}
+	if mentions, _ := restarted.GetTweetsMentioning(domain.NewUser("root", "")); len(mentions) != 1 {
+		t.Errorf("Expected 1 mention but got %d", len(mentions))
+	}
+}
@@ -0,0 +1,52 @@
+package service
+
+import "errors"
+
+//Errors returned by the manager and its repositories, that callers can tell apart with errors.Is
+var (
+	ErrInvalidName           = errors.New("Invalid name")
+	ErrInvalidPassword       = errors.New("Invalid password")
+	ErrAlreadyRegistered     = errors.New("The user is already registered")
+	ErrInvalidLogin          = errors.New("The user is not registered")
+	ErrWrongPassword         = errors.New("Wrong password")
+	ErrNotLoggedIn           = errors.New("Not logged in")
+	ErrSessionExpired        = errors.New("Session expired")
+	ErrNoUserLoggedIn        = errors.New("No user logged in")
+	ErrMustBeLoggedInToTweet = errors.New("You must be logged in to tweet")
+	ErrUserNotRegistered     = errors.New("That user is not registered")
+	ErrUserNotFound          = errors.New("User not registered")
+	ErrTweetNotFound         = errors.New("A tweet with that ID does not exist")
+	ErrCantDeleteOthersTweet = errors.New("You can't delete a tweet that you didn't publish")
+	ErrCantEditOthersTweet   = errors.New("You can't edit a tweet that you didn't publish")
+	ErrCantFollowYourself    = errors.New("Can't follow yourself")
+	ErrAlreadyFollowing      = errors.New("Can't follow same user twice")
+	ErrNotFollowing          = errors.New("You don't follow that user")
+	ErrCantBlockYourself     = errors.New("Can't block yourself")
+	ErrAlreadyBlocked        = errors.New("That user is already blocked")
+	ErrNotBlocked            = errors.New("That user is not blocked")
+	ErrBlocked               = errors.New("You can't interact with that user")
+	ErrCantMuteYourself      = errors.New("Can't mute yourself")
+	ErrAlreadyMuted          = errors.New("That user is already muted")
+	ErrNotMuted              = errors.New("That user is not muted")
+	ErrAlreadyRequested      = errors.New("You already asked to follow that user")
+	ErrNoFollowRequest       = errors.New("That user didn't ask to follow you")
+	ErrProtectedAccount      = errors.New("That account is protected")
+	ErrNoRecipients          = errors.New("A message needs at least one recipient")
+	ErrCantMessageYourself   = errors.New("Can't send a message to yourself")
+	ErrConversationNotFound  = errors.New("A conversation with that ID does not exist")
+	ErrConversationExists    = errors.New("There already is a conversation between those users")
+	ErrNotInConversation     = errors.New("You are not part of that conversation")
+	ErrInvalidWebhookURL     = errors.New("A webhook needs an absolute http or https URL")
+	ErrWebhookNotFound       = errors.New("A webhook with that ID does not exist")
+	ErrPrivateWebhookURL     = errors.New("A webhook can't point to a local or private address")
+	ErrWebhookHostNotFound   = errors.New("The host of the webhook couldn't be found")
+	ErrDraftNotFound         = errors.New("A draft with that ID does not exist")
+	ErrScheduleInThePast     = errors.New("A tweet can only be scheduled for the future")
+	ErrCantRetweetOwnTweet   = errors.New("You can't retweet your own tweet")
+	ErrAlreadyRetweeted      = errors.New("You already retweeted that tweet")
+	ErrNotRetweeted          = errors.New("You haven't retweeted that tweet")
+	ErrAlreadyLiked          = errors.New("You already liked that tweet")
+	ErrNotLiked              = errors.New("You haven't liked that tweet")
+	ErrAlreadyBookmarked     = errors.New("That tweet is already bookmarked")
+	ErrNotBookmarked         = errors.New("That tweet is not bookmarked")
+)
@@ -0,0 +1,44 @@
+package service
+
+import "github.com/cursoGo/src/events"
+
+//EventBus returns the bus to which the manager publishes what happens to its users and tweets,
+//so that other components can subscribe to it. Events are published once the operation that caused
+//them is finished and the manager is unlocked, so handlers can use the manager. They are published
+//in the order they happened: the events of operations made by handlers come after the one being handled.
+//Synchronous handlers run before the method that caused the event returns, unless another goroutine
+//is publishing at the time. That goroutine publishes the event once it's done with the earlier ones,
+//so the method may return before the handlers run
+func (m *TweetManager) EventBus() *events.Bus {
+	return m.bus
+}
+
+//emit keeps an event of the operation in progress until it can be published. The mutex must be locked
+func (m *TweetManager) emit(event events.Event) {
+	m.pending = append(m.pending, event)
+}
+
+//publishEvents publishes the events of the operations that already finished.
+//It is deferred before locking the mutex by every method that can emit events.
+//Only one goroutine publishes at a time, and it publishes the events that others emit in the meantime
+//too, so that an event is never published before the ones that happened earlier. The others don't wait
+//for it, since a handler that uses the manager would then wait for itself
+func (m *TweetManager) publishEvents() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.publishing {
+		return
+	}
+	m.publishing = true
+	for len(m.pending) > 0 {
+		pending := m.pending
+		m.pending = nil
+		bus := m.bus
+		m.mutex.Unlock()
+		for _, event := range pending {
+			bus.Publish(event)
+		}
+		m.mutex.Lock()
+	}
+	m.publishing = false
+}
@@ -0,0 +1,178 @@
+package service_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/events"
+	"github.com/cursoGo/src/service"
+)
+
+//kinds returns the kinds of the events, in order
+func kinds(received []events.Event) []string {
+	var kinds []string
+	for _, event := range received {
+		kinds = append(kinds, event.Kind())
+	}
+	return kinds
+}
+
+func TestManagerPublishesLifecycleEvents(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	var received []events.Event
+	manager.EventBus().Subscribe(func(event events.Event) { received = append(received, event) })
+
+	//Operation
+	tokens := newSocialManager(&manager)
+	tweet := publishText(t, &manager, tokens[0], "manu", "hola")
+	manager.EditTweetTextByID(tokens[0], tweet.GetID(), "chau")
+	manager.FollowUser(tokens[1], "manu")
+	manager.DeleteTweetByID(tokens[0], tweet.GetID())
+
+	//Validation
+	expected := []string{"UserRegistered", "UserRegistered", "UserRegistered", "TweetPublished", "TweetEdited", "UserFollowed", "TweetDeleted"}
+	got := kinds(received)
+	if len(got) != len(expected) {
+		t.Errorf("Expected %v but got %v", expected, got)
+		return
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Expected %v but got %v", expected, got)
+			return
+		}
+	}
+	if user := received[0].(events.UserRegistered).User; user.Name != "manu" || user.Password != "" {
+		t.Errorf("Unexpected user %+v", user)
+	}
+	if edited := received[4].(events.TweetEdited).Tweet; edited.GetText() != "chau" {
+		t.Errorf("Unexpected text %s", edited.GetText())
+	}
+	followed := received[5].(events.UserFollowed)
+	if followed.Follower.Name != "gonza" || followed.Followed.Name != "manu" {
+		t.Errorf("Unexpected follow %+v", followed)
+	}
+}
+
+func TestFailedOperationsPublishNoEvents(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	var received []events.Event
+	manager.EventBus().Subscribe(func(event events.Event) { received = append(received, event) })
+
+	//Operation
+	manager.Register(domain.NewUser("manu", "again"))
+	manager.PublishTweet("", &domain.TextTweet{})
+	manager.FollowUser(tokens[0], "nobody")
+
+	//Validation
+	if len(received) != 0 {
+		t.Errorf("Expected no events but got %v", kinds(received))
+	}
+}
+
+func TestHandlersCanUseTheManager(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	var timeline []domain.Tweeter
+	manager.EventBus().Subscribe(func(event events.Event) {
+		if _, ok := event.(events.TweetPublished); ok {
+			timeline, _ = manager.GetTimeline(tokens[0])
+		}
+	})
+
+	//Operation
+	tweet := publishText(t, &manager, tokens[0], "manu", "hola")
+
+	//Validation
+	expectTweets(t, timeline, []domain.Tweeter{tweet})
+}
+
+func TestEventsOfHandlersArePublishedInOrder(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	manager.EventBus().Subscribe(func(event events.Event) {
+		if published, ok := event.(events.TweetPublished); ok {
+			manager.DeleteTweetByID(tokens[0], published.Tweet.GetID())
+		}
+	})
+	var received []events.Event
+	manager.EventBus().Subscribe(func(event events.Event) { received = append(received, event) })
+
+	//Operation
+	publishText(t, &manager, tokens[0], "manu", "hola")
+
+	//Validation
+	if got := kinds(received); len(got) != 2 || got[0] != "TweetPublished" || got[1] != "TweetDeleted" {
+		t.Errorf("Expected [TweetPublished TweetDeleted] but got %v", got)
+	}
+}
+
+func TestEventsOfOtherOperationsArePublishedByTheBusyPublisher(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	started, release := make(chan bool), make(chan bool)
+	var mutex sync.Mutex
+	var received []string
+	manager.EventBus().Subscribe(func(event events.Event) {
+		published, ok := event.(events.TweetPublished)
+		if ok && published.Tweet.GetText() == "primero" {
+			close(started)
+			<-release
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		received = append(received, event.(events.TweetPublished).Tweet.GetText())
+	})
+	first, _ := manager.NewTextTweet(domain.NewUser("manu", ""), "primero")
+	go manager.PublishTweet(tokens[0], first)
+	<-started
+
+	//Operation
+	publishText(t, &manager, tokens[1], "gonza", "segundo")
+
+	//Validation
+	mutex.Lock()
+	if len(received) != 0 {
+		t.Errorf("Expected no events before the first one is handled but got %v", received)
+	}
+	mutex.Unlock()
+	close(release)
+	eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(received) == 2
+	})
+	if received[0] != "primero" || received[1] != "segundo" {
+		t.Errorf("Expected [primero segundo] but got %v", received)
+	}
+}
+
+func TestApprovingRequestPublishesFollow(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens, _ := newProtectedManager(t, &manager)
+	var received []events.Event
+	manager.EventBus().Subscribe(func(event events.Event) { received = append(received, event) })
+	manager.FollowUser(tokens[0], "gonza")
+
+	//Operation
+	manager.ApproveFollowRequest(tokens[1], "manu")
+
+	//Validation
+	if len(received) != 1 || received[0].Kind() != "UserFollowed" {
+		t.Errorf("Expected a follow but got %v", kinds(received))
+	}
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/cursoGo/src/domain"
+)
+
+//Kinds of events written to the event log
+const (
+	UserRegisteredEvent = "UserRegistered"
+	TweetPublishedEvent = "TweetPublished"
+	TweetEditedEvent    = "TweetEdited"
+	TweetDeletedEvent   = "TweetDeleted"
+	UserFollowedEvent   = "UserFollowed"
+	UserUnfollowedEvent = "UserUnfollowed"
+	//CredentialsSetEvent holds a new password hash of a user. The first one is in UserRegisteredEvent,
+	//next to the user and not in it
+	CredentialsSetEvent  = "CredentialsSet"
+	TweetLikedEvent      = "TweetLiked"
+	TweetUnlikedEvent    = "TweetUnliked"
+	TweetBookmarkedEvent = "TweetBookmarked"
+	BookmarkRemovedEvent = "BookmarkRemoved"
+	UserBlockedEvent     = "UserBlocked"
+	UserUnblockedEvent   = "UserUnblocked"
+	UserMutedEvent       = "UserMuted"
+	UserUnmutedEvent     = "UserUnmuted"
+	//ProtectionChangedEvent holds if the user with UserID is protected from then on
+	ProtectionChangedEvent    = "ProtectionChanged"
+	FollowRequestedEvent      = "FollowRequested"
+	FollowRequestRemovedEvent = "FollowRequestRemoved"
+)
+
+//LogEvent is a change made to the tweets, as it is saved in the event log
+type LogEvent struct {
+	Seq         int64               `json:"seq"`
+	Type        string              `json:"type"`
+	Time        time.Time           `json:"time"`
+	User        *domain.User        `json:"user,omitempty"`
+	Credentials *domain.Credentials `json:"credentials,omitempty"`
+	Tweet       *domain.TweetRecord `json:"tweet,omitempty"`
+	TweetID     int                 `json:"tweetID"`
+	Text        string              `json:"text,omitempty"`
+	FollowerID  int                 `json:"followerID,omitempty"`
+	FollowedID  int                 `json:"followedID,omitempty"`
+	UserID      int                 `json:"userID,omitempty"`
+	TargetID    int                 `json:"targetID,omitempty"`
+	Protected   bool                `json:"protected,omitempty"`
+	Name        string              `json:"name,omitempty"`
+}
+
+//EventLog is an append-only file of LogEvents.
+//Every event is written on its own line, preceded by the CRC32 of its JSON
+type EventLog struct {
+	file    *os.File
+	size    int64
+	lastSeq int64
+}
+
+//OpenEventLog opens the event log at path, creating it if it doesn't exist.
+//A torn record at the end of the file, left by a crash while appending, is truncated
+func OpenEventLog(path string) (*EventLog, error) {
+	return openEventLog(path, 0, 0, nil)
+}
+
+//openEventLog opens the event log at path knowing that the event with sequence number seq ends at offset.
+//Only the events after it are read, passing them to visit if it isn't nil
+func openEventLog(path string, offset, seq int64, visit func(LogEvent) error) (*EventLog, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't open event log, %s", err.Error())
+	}
+	log := &EventLog{file: file, lastSeq: seq}
+	size, err := log.readFrom(offset, func(event LogEvent) error {
+		log.lastSeq = event.Seq
+		if visit == nil {
+			return nil
+		}
+		return visit(event)
+	})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	err = file.Truncate(size)
+	if err == nil {
+		_, err = file.Seek(size, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("Couldn't open event log, %s", err.Error())
+	}
+	log.size = size
+	return log, nil
+}
+
+//Append writes an event at the end of the log, giving it the next sequence number
+func (l *EventLog) Append(event LogEvent) (LogEvent, error) {
+	event.Seq = l.lastSeq + 1
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return event, err
+	}
+	record := fmt.Sprintf("%08x %s\n", crc32.ChecksumIEEE(payload), payload)
+	_, err = l.file.WriteString(record)
+	if err == nil {
+		err = l.file.Sync()
+	}
+	if err != nil {
+		//Leave the log as it was, so that the next append doesn't follow a torn record
+		l.truncate(l.size, l.lastSeq)
+		return event, fmt.Errorf("Couldn't write to event log, %s", err.Error())
+	}
+	l.size += int64(len(record))
+	l.lastSeq = event.Seq
+	return event, nil
+}
+
+//truncate takes every event after the one with sequence number seq, which ends at size, out of the log
+func (l *EventLog) truncate(size, seq int64) error {
+	err := l.file.Truncate(size)
+	if err == nil {
+		_, err = l.file.Seek(size, io.SeekStart)
+	}
+	if err == nil {
+		err = l.file.Sync()
+	}
+	if err != nil {
+		return fmt.Errorf("Couldn't truncate event log, %s", err.Error())
+	}
+	l.size = size
+	l.lastSeq = seq
+	return nil
+}
+
+//Size returns the size in bytes of the valid records of the log
+func (l *EventLog) Size() int64 {
+	return l.size
+}
+
+//LastSeq returns the sequence number of the last event in the log
+func (l *EventLog) LastSeq() int64 {
+	return l.lastSeq
+}
+
+//ReadFrom calls visit with every event written from offset onwards, in order
+func (l *EventLog) ReadFrom(offset int64, visit func(LogEvent) error) error {
+	_, err := l.readFrom(offset, visit)
+	l.file.Seek(l.size, io.SeekStart)
+	return err
+}
+
+//Close closes the log file
+func (l *EventLog) Close() error {
+	return l.file.Close()
+}
+
+//readFrom reads the events from offset and returns where the valid records end.
+//Only the last record can be invalid, anything else means that the log is corrupt
+func (l *EventLog) readFrom(offset int64, visit func(LogEvent) error) (int64, error) {
+	_, err := l.file.Seek(offset, io.SeekStart)
+	if err != nil {
+		return offset, fmt.Errorf("Couldn't read event log, %s", err.Error())
+	}
+	reader := bufio.NewReader(l.file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF && len(line) == 0 {
+			return offset, nil
+		}
+		if err != nil && err != io.EOF {
+			return offset, fmt.Errorf("Couldn't read event log, %s", err.Error())
+		}
+
+		event, ok := decodeLogRecord(line)
+		if !ok {
+			if _, peekErr := reader.Peek(1); peekErr == io.EOF {
+				return offset, nil
+			}
+			return offset, fmt.Errorf("Event log is corrupt at offset %d", offset)
+		}
+		err = visit(event)
+		if err != nil {
+			return offset, err
+		}
+		offset += int64(len(line))
+	}
+}
+
+//decodeLogRecord parses a record line, checking that it is complete and that its checksum matches
+func decodeLogRecord(line []byte) (LogEvent, bool) {
+	var event LogEvent
+	if len(line) < 10 || line[len(line)-1] != '\n' || line[8] != ' ' {
+		return event, false
+	}
+	checksum, err := strconv.ParseUint(string(line[:8]), 16, 32)
+	payload := bytes.TrimSuffix(line[9:], []byte("\n"))
+	if err != nil || uint32(checksum) != crc32.ChecksumIEEE(payload) {
+		return event, false
+	}
+	return event, json.Unmarshal(payload, &event) == nil
+}
+
+//ReadEventLog returns every event in the log at path, as an audit trail of what happened
+func ReadEventLog(path string) ([]LogEvent, error) {
+	log, err := OpenEventLog(path)
+	if err != nil {
+		return nil, err
+	}
+	defer log.Close()
+	var events []LogEvent
+	err = log.ReadFrom(0, func(event LogEvent) error {
+		events = append(events, event)
+		return nil
+	})
+	return events, err
+}
+
+//ReplayEventLog rebuilds the state that the log at path described at a given time
+func ReplayEventLog(path string, at time.Time) (*MemoryTweetRepository, error) {
+	log, err := OpenEventLog(path)
+	if err != nil {
+		return nil, err
+	}
+	defer log.Close()
+	memory := NewMemoryTweetRepository()
+	err = log.ReadFrom(0, func(event LogEvent) error {
+		if event.Time.After(at) {
+			return nil
+		}
+		return applyLogEvent(memory, event)
+	})
+	return memory, err
+}
+
+//applyLogEvent makes the change described by an event to the repository
+func applyLogEvent(memory *MemoryTweetRepository, event LogEvent) error {
+	var err error
+	switch event.Type {
+	case UserRegisteredEvent:
+		if event.Credentials != nil {
+			err = memory.AddUserWithCredentials(*event.User, *event.Credentials)
+		} else {
+			err = memory.AddUser(*event.User)
+		}
+	case TweetPublishedEvent:
+		var tweet domain.Tweeter
+		tweet, err = event.Tweet.Restore(func(id int) domain.Tweeter {
+			stored, _ := memory.GetTweetByID(id)
+			return stored
+		})
+		if err == nil {
+			err = memory.AddTweet(tweet)
+		}
+	case TweetEditedEvent:
+		var tweet domain.Tweeter
+		tweet, err = memory.GetTweetByID(event.TweetID)
+		if err == nil {
+			err = domain.RestoreText(tweet, event.Text)
+		}
+		if err == nil {
+			err = memory.UpdateTweet(tweet)
+		}
+	case TweetDeletedEvent:
+		var tweet domain.Tweeter
+		tweet, err = memory.GetTweetByID(event.TweetID)
+		if err == nil {
+			err = memory.DeleteTweet(tweet)
+		}
+	case UserFollowedEvent:
+		err = memory.AddFollow(event.FollowerID, event.FollowedID)
+	case UserUnfollowedEvent:
+		err = memory.RemoveFollow(event.FollowerID, event.FollowedID)
+	case CredentialsSetEvent:
+		err = memory.SetCredentials(*event.Credentials)
+	case TweetLikedEvent:
+		err = memory.AddLike(event.Name, event.TweetID)
+	case TweetUnlikedEvent:
+		err = memory.RemoveLike(event.Name, event.TweetID)
+	case TweetBookmarkedEvent:
+		err = memory.AddBookmark(event.Name, event.TweetID)
+	case BookmarkRemovedEvent:
+		err = memory.RemoveBookmark(event.Name, event.TweetID)
+	case ProtectionChangedEvent:
+		err = memory.SetProtected(event.UserID, event.Protected)
+	case FollowRequestedEvent:
+		err = memory.AddFollowRequest(event.FollowerID, event.FollowedID)
+	case FollowRequestRemovedEvent:
+		err = memory.RemoveFollowRequest(event.FollowerID, event.FollowedID)
+	case UserBlockedEvent:
+		err = memory.AddBlock(event.UserID, event.TargetID)
+	case UserUnblockedEvent:
+		err = memory.RemoveBlock(event.UserID, event.TargetID)
+	case UserMutedEvent:
+		err = memory.AddMute(event.UserID, event.TargetID)
+	case UserUnmutedEvent:
+		err = memory.RemoveMute(event.UserID, event.TargetID)
+	default:
+		err = fmt.Errorf("Unknown event type %q", event.Type)
+	}
+	if err != nil {
+		return fmt.Errorf("Couldn't replay event %d, %s", event.Seq, err.Error())
+	}
+	return nil
+}
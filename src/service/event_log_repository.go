@@ -0,0 +1,295 @@
+package service
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/cursoGo/src/domain"
+)
+
+//EventLogRepository is a TweetRepository that records every change in an EventLog
+//and rebuilds its contents by replaying it. Every snapshotEvery events it also saves
+//a snapshot of everything, so that only the events after it have to be replayed
+type EventLogRepository struct {
+	*MemoryTweetRepository
+	path          string
+	snapshotEvery int
+	log           *EventLog
+	sinceSnapshot int
+}
+
+//eventLogSnapshot is the state of the repository after the event with sequence number Seq,
+//which ends at Offset in the log
+type eventLogSnapshot struct {
+	Seq    int64              `json:"seq"`
+	Offset int64              `json:"offset"`
+	Data   fileRepositoryData `json:"data"`
+}
+
+//NewEventLogRepository returns an EventLogRepository that uses the log at path,
+//taking a snapshot every snapshotEvery events (never if it is 0). Its contents are read when calling Load
+func NewEventLogRepository(path string, snapshotEvery int) *EventLogRepository {
+	return &EventLogRepository{
+		MemoryTweetRepository: NewMemoryTweetRepository(),
+		path:                  path,
+		snapshotEvery:         snapshotEvery,
+	}
+}
+
+func (r *EventLogRepository) snapshotPath() string {
+	return r.path + ".snapshot"
+}
+
+//Load rebuilds the repository from the last snapshot and the events logged after it
+func (r *EventLogRepository) Load() error {
+	r.Close()
+	snapshot, err := r.readSnapshot()
+	if err != nil {
+		return err
+	}
+
+	memory := NewMemoryTweetRepository()
+	var offset, seq int64
+	if info, statErr := os.Stat(r.path); snapshot != nil && statErr == nil && snapshot.Offset <= info.Size() {
+		memory, err = snapshot.Data.restore(nil)
+		if err != nil {
+			return fmt.Errorf("Couldn't read snapshot, %s", err.Error())
+		}
+		offset, seq = snapshot.Offset, snapshot.Seq
+	}
+
+	log, err := openEventLog(r.path, offset, seq, func(event LogEvent) error {
+		return applyLogEvent(memory, event)
+	})
+	if err != nil {
+		return err
+	}
+	r.MemoryTweetRepository = memory
+	r.log = log
+	r.sinceSnapshot = 0
+	return nil
+}
+
+//readSnapshot returns the saved snapshot, or nil if there is none
+func (r *EventLogRepository) readSnapshot() (*eventLogSnapshot, error) {
+	contents, err := ioutil.ReadFile(r.snapshotPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't read snapshot, %s", err.Error())
+	}
+	var snapshot eventLogSnapshot
+	err = json.Unmarshal(contents, &snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't read snapshot, %s", err.Error())
+	}
+	return &snapshot, nil
+}
+
+//Snapshot saves everything stored up to the last logged event
+func (r *EventLogRepository) Snapshot() error {
+	if r.log == nil {
+		return fmt.Errorf("The event log is not loaded")
+	}
+	data, err := newFileRepositoryData(r.MemoryTweetRepository)
+	if err != nil {
+		return err
+	}
+	snapshot := eventLogSnapshot{Seq: r.log.LastSeq(), Offset: r.log.Size(), Data: data}
+	contents, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	err = writeFileAtomically(r.snapshotPath(), contents)
+	if err != nil {
+		return fmt.Errorf("Couldn't save snapshot, %s", err.Error())
+	}
+	r.sinceSnapshot = 0
+	return nil
+}
+
+//Close closes the event log
+func (r *EventLogRepository) Close() error {
+	if r.log == nil {
+		return nil
+	}
+	err := r.log.Close()
+	r.log = nil
+	return err
+}
+
+//apply logs an event and only then makes its change, so that nothing is changed without being logged.
+//If change fails, the event is taken back out of the log. A snapshot is taken when it's due
+func (r *EventLogRepository) apply(event LogEvent, change func() error) error {
+	if r.log == nil {
+		return fmt.Errorf("The event log is not loaded")
+	}
+	size, seq := r.log.Size(), r.log.LastSeq()
+	_, err := r.log.Append(event)
+	if err != nil {
+		return err
+	}
+	err = change()
+	if err != nil {
+		if truncateErr := r.log.truncate(size, seq); truncateErr != nil {
+			return fmt.Errorf("%w, and %s", err, truncateErr.Error())
+		}
+		return err
+	}
+	r.sinceSnapshot++
+	if r.snapshotEvery > 0 && r.sinceSnapshot >= r.snapshotEvery {
+		//The event is already logged, so a failed snapshot is only retried with the next one
+		if err := r.Snapshot(); err != nil {
+			log.Printf("Couldn't take snapshot of event log, %s", err.Error())
+		}
+	}
+	return nil
+}
+
+//AddUser stores a new user and logs it
+func (r *EventLogRepository) AddUser(user domain.User) error {
+	if user.ID == 0 {
+		user.ID = r.lastUserID + 1
+	}
+	logged := domain.User{ID: user.ID, Name: user.Name}
+	return r.apply(LogEvent{Type: UserRegisteredEvent, User: &logged}, func() error {
+		return r.MemoryTweetRepository.AddUser(user)
+	})
+}
+
+//AddUserWithCredentials stores a new user with its credentials and logs both in the same event
+func (r *EventLogRepository) AddUserWithCredentials(user domain.User, credentials domain.Credentials) error {
+	if user.ID == 0 {
+		user.ID = r.lastUserID + 1
+	}
+	logged := domain.User{ID: user.ID, Name: user.Name}
+	return r.apply(LogEvent{Type: UserRegisteredEvent, User: &logged, Credentials: &credentials}, func() error {
+		return r.MemoryTweetRepository.AddUserWithCredentials(user, credentials)
+	})
+}
+
+//SetCredentials stores the credentials of a user and logs them
+func (r *EventLogRepository) SetCredentials(credentials domain.Credentials) error {
+	return r.apply(LogEvent{Type: CredentialsSetEvent, Credentials: &credentials}, func() error {
+		return r.MemoryTweetRepository.SetCredentials(credentials)
+	})
+}
+
+//AddTweet stores a tweet and logs it
+func (r *EventLogRepository) AddTweet(tweet domain.Tweeter) error {
+	record, err := domain.NewTweetRecord(tweet)
+	if err != nil {
+		return err
+	}
+	return r.apply(LogEvent{Type: TweetPublishedEvent, Tweet: &record, TweetID: tweet.GetID()}, func() error {
+		return r.MemoryTweetRepository.AddTweet(tweet)
+	})
+}
+
+//UpdateTweet saves the new text of a stored tweet and logs it
+func (r *EventLogRepository) UpdateTweet(tweet domain.Tweeter) error {
+	return r.apply(LogEvent{Type: TweetEditedEvent, TweetID: tweet.GetID(), Text: tweet.GetText()}, func() error {
+		return r.MemoryTweetRepository.UpdateTweet(tweet)
+	})
+}
+
+//DeleteTweet removes a stored tweet and logs it
+func (r *EventLogRepository) DeleteTweet(tweet domain.Tweeter) error {
+	return r.apply(LogEvent{Type: TweetDeletedEvent, TweetID: tweet.GetID()}, func() error {
+		return r.MemoryTweetRepository.DeleteTweet(tweet)
+	})
+}
+
+//AddFollow stores a follow and logs it
+func (r *EventLogRepository) AddFollow(follower, followed int) error {
+	return r.apply(LogEvent{Type: UserFollowedEvent, FollowerID: follower, FollowedID: followed}, func() error {
+		return r.MemoryTweetRepository.AddFollow(follower, followed)
+	})
+}
+
+//RemoveFollow forgets a follow and logs it
+func (r *EventLogRepository) RemoveFollow(follower, followed int) error {
+	return r.apply(LogEvent{Type: UserUnfollowedEvent, FollowerID: follower, FollowedID: followed}, func() error {
+		return r.MemoryTweetRepository.RemoveFollow(follower, followed)
+	})
+}
+
+//AddLike stores a like and logs it
+func (r *EventLogRepository) AddLike(name string, id int) error {
+	return r.apply(LogEvent{Type: TweetLikedEvent, TweetID: id, Name: name}, func() error {
+		return r.MemoryTweetRepository.AddLike(name, id)
+	})
+}
+
+//RemoveLike forgets a like and logs it
+func (r *EventLogRepository) RemoveLike(name string, id int) error {
+	return r.apply(LogEvent{Type: TweetUnlikedEvent, TweetID: id, Name: name}, func() error {
+		return r.MemoryTweetRepository.RemoveLike(name, id)
+	})
+}
+
+//AddBookmark stores a bookmark and logs it
+func (r *EventLogRepository) AddBookmark(name string, id int) error {
+	return r.apply(LogEvent{Type: TweetBookmarkedEvent, TweetID: id, Name: name}, func() error {
+		return r.MemoryTweetRepository.AddBookmark(name, id)
+	})
+}
+
+//RemoveBookmark forgets a bookmark and logs it
+func (r *EventLogRepository) RemoveBookmark(name string, id int) error {
+	return r.apply(LogEvent{Type: BookmarkRemovedEvent, TweetID: id, Name: name}, func() error {
+		return r.MemoryTweetRepository.RemoveBookmark(name, id)
+	})
+}
+
+//AddBlock stores a block and logs it
+func (r *EventLogRepository) AddBlock(blocker, blocked int) error {
+	return r.apply(LogEvent{Type: UserBlockedEvent, UserID: blocker, TargetID: blocked}, func() error {
+		return r.MemoryTweetRepository.AddBlock(blocker, blocked)
+	})
+}
+
+//RemoveBlock forgets a block and logs it
+func (r *EventLogRepository) RemoveBlock(blocker, blocked int) error {
+	return r.apply(LogEvent{Type: UserUnblockedEvent, UserID: blocker, TargetID: blocked}, func() error {
+		return r.MemoryTweetRepository.RemoveBlock(blocker, blocked)
+	})
+}
+
+//AddMute stores a mute and logs it
+func (r *EventLogRepository) AddMute(muter, muted int) error {
+	return r.apply(LogEvent{Type: UserMutedEvent, UserID: muter, TargetID: muted}, func() error {
+		return r.MemoryTweetRepository.AddMute(muter, muted)
+	})
+}
+
+//RemoveMute forgets a mute and logs it
+func (r *EventLogRepository) RemoveMute(muter, muted int) error {
+	return r.apply(LogEvent{Type: UserUnmutedEvent, UserID: muter, TargetID: muted}, func() error {
+		return r.MemoryTweetRepository.RemoveMute(muter, muted)
+	})
+}
+
+//SetProtected changes if a user is protected and logs it
+func (r *EventLogRepository) SetProtected(id int, protected bool) error {
+	return r.apply(LogEvent{Type: ProtectionChangedEvent, UserID: id, Protected: protected}, func() error {
+		return r.MemoryTweetRepository.SetProtected(id, protected)
+	})
+}
+
+//AddFollowRequest stores a follow request and logs it
+func (r *EventLogRepository) AddFollowRequest(requester, owner int) error {
+	return r.apply(LogEvent{Type: FollowRequestedEvent, FollowerID: requester, FollowedID: owner}, func() error {
+		return r.MemoryTweetRepository.AddFollowRequest(requester, owner)
+	})
+}
+
+//RemoveFollowRequest forgets a follow request and logs it
+func (r *EventLogRepository) RemoveFollowRequest(requester, owner int) error {
+	return r.apply(LogEvent{Type: FollowRequestRemovedEvent, FollowerID: requester, FollowedID: owner}, func() error {
+		return r.MemoryTweetRepository.RemoveFollowRequest(requester, owner)
+	})
+}
@@ -0,0 +1,372 @@
+package service_test
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/service"
+	"github.com/cursoGo/src/utility"
+)
+
+//publishLoggedTweets registers root, publishes texts and returns the published tweets
+func publishLoggedTweets(manager *service.TweetManager, texts ...string) []domain.Tweeter {
+	user := domain.NewUser("root", "root")
+	manager.Register(user)
+	token, _ := manager.Login(user)
+	var tweets []domain.Tweeter
+	for _, text := range texts {
+		tweet, _ := domain.NewTextTweet(user, text)
+		manager.PublishTweet(token, tweet)
+		tweets = append(tweets, tweet)
+	}
+	manager.Logout(token)
+	return tweets
+}
+
+func TestEventLogRebuildsStateAfterRestart(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+
+	var manager service.TweetManager
+	repository := service.NewEventLogRepository(path, 0)
+	manager.InitializeManagerWithRepository(repository)
+	tweets := publishLoggedTweets(&manager, "first", "second", "third")
+	user := domain.NewUser("root", "root")
+	token, _ := manager.Login(user)
+	manager.EditTweetTextByID(token, tweets[0].GetID(), "edited")
+	manager.DeleteTweetByID(token, tweets[1].GetID())
+	repository.Close()
+
+	//Operation
+	var restarted service.TweetManager
+	restartedRepository := service.NewEventLogRepository(path, 0)
+	err := restarted.InitializeManagerWithRepository(restartedRepository)
+	defer restartedRepository.Close()
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	restored, _ := restarted.GetTweetsFromUser(user)
+	if len(restored) != 2 {
+		t.Errorf("Expected size is 2 but was %d", len(restored))
+		return
+	}
+	if restored[0].GetText() != "edited" || restored[1].GetText() != "third" {
+		t.Errorf("Unexpected tweets %s and %s", restored[0], restored[1])
+	}
+}
+
+func TestEventLogReplaysEditsLongerThanTheDefaultLimit(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+	var manager service.TweetManager
+	repository := service.NewEventLogRepository(path, 0)
+	manager.InitializeManagerWith(repository, domain.NewSequentialIDGenerator(), domain.SystemClock{}, domain.WithMaxTweetLength(200))
+	tweets := publishLoggedTweets(&manager, "first")
+	token, _ := manager.Login(domain.NewUser("root", "root"))
+	long := strings.Repeat("a", 150)
+	if err := manager.EditTweetTextByID(token, tweets[0].GetID(), long); err != nil {
+		t.Fatalf("Unexpected error, %s", err.Error())
+	}
+	repository.Close()
+
+	//Operation
+	var restarted service.TweetManager
+	restartedRepository := service.NewEventLogRepository(path, 0)
+	err := restarted.InitializeManagerWith(restartedRepository, domain.NewSequentialIDGenerator(), domain.SystemClock{}, domain.WithMaxTweetLength(200))
+	defer restartedRepository.Close()
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if restored, _ := restarted.GetTweetByID(tweets[0].GetID()); restored == nil || restored.GetText() != long {
+		t.Errorf("The long edit was not replayed, got %v", restored)
+	}
+}
+
+func TestEventLogKeepsAuditTrail(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+
+	var manager service.TweetManager
+	repository := service.NewEventLogRepository(path, 0)
+	manager.InitializeManagerWithRepository(repository)
+	publishLoggedTweets(&manager, "first")
+	repository.Close()
+
+	//Operation
+	events, err := service.ReadEventLog(path)
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if len(events) != 2 {
+		t.Errorf("Expected size is 2 but was %d", len(events))
+		return
+	}
+	if events[0].Type != service.UserRegisteredEvent || events[1].Type != service.TweetPublishedEvent {
+		t.Errorf("Unexpected events %s and %s", events[0].Type, events[1].Type)
+	}
+	if events[0].Seq != 1 || events[1].Seq != 2 {
+		t.Errorf("Unexpected sequence numbers %d and %d", events[0].Seq, events[1].Seq)
+	}
+	if events[0].User.Password != "" {
+		t.Error("The password should not be logged")
+	}
+	if events[0].Credentials == nil || events[0].Credentials.Name != "root" {
+		t.Error("The credentials should be logged with the user")
+	}
+}
+
+func TestEventLogChangesNothingThatIsntLogged(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+
+	var manager service.TweetManager
+	repository := service.NewEventLogRepository(path, 0)
+	manager.InitializeManagerWithRepository(repository)
+	publishLoggedTweets(&manager, "first")
+	repository.Close()
+
+	//Operation
+	err := repository.AddUser(domain.NewUser("manu", ""))
+
+	//Validation
+	if err == nil {
+		t.Error("Expected error")
+	}
+	if _, err := repository.GetUserByName("manu"); err == nil {
+		t.Error("The user should not be stored without being logged")
+	}
+}
+
+func TestEventLogDoesntEditTweetThatIsntLogged(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+
+	var manager service.TweetManager
+	repository := service.NewEventLogRepository(path, 0)
+	manager.InitializeManagerWithRepository(repository)
+	tweets := publishLoggedTweets(&manager, "first")
+	token, _ := manager.Login(domain.NewUser("root", "root"))
+	repository.Close()
+
+	//Operation
+	err := manager.EditTweetTextByID(token, tweets[0].GetID(), "edited")
+
+	//Validation
+	if err == nil {
+		t.Error("Expected error")
+	}
+	if tweet, _ := repository.GetTweetByID(tweets[0].GetID()); tweet.GetText() != "first" {
+		t.Errorf("Expected the text of the tweet to stay first but got %s", tweet.GetText())
+	}
+}
+
+func TestEventLogDoesntKeepEventsOfFailedChanges(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+
+	var manager service.TweetManager
+	repository := service.NewEventLogRepository(path, 0)
+	manager.InitializeManagerWithRepository(repository)
+	tweets := publishLoggedTweets(&manager, "first")
+
+	//Operation
+	err := repository.AddUser(domain.NewUser("root", ""))
+	repository.AddLike("root", tweets[0].GetID()+1)
+	repository.AddUser(domain.NewUser("manu", ""))
+	repository.Close()
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "The user is already registered")
+	events, err := service.ReadEventLog(path)
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if len(events) != 3 || events[2].Type != service.UserRegisteredEvent || events[2].Seq != 3 {
+		t.Errorf("Unexpected events %+v", events)
+		return
+	}
+	var restarted service.TweetManager
+	restartedRepository := service.NewEventLogRepository(path, 0)
+	defer restartedRepository.Close()
+	if err := restarted.InitializeManagerWithRepository(restartedRepository); err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+	}
+}
+
+func TestEventLogKeepsChangesWhenSnapshotFails(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+
+	var manager service.TweetManager
+	repository := service.NewEventLogRepository(path, 1)
+	manager.InitializeManagerWithRepository(repository)
+	//The snapshot can't be written over a directory
+	os.Mkdir(path+".snapshot", 0755)
+
+	//Operation
+	err := repository.AddUser(domain.NewUser("manu", ""))
+	repository.Close()
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	os.Remove(path + ".snapshot")
+	var restarted service.TweetManager
+	restartedRepository := service.NewEventLogRepository(path, 1)
+	defer restartedRepository.Close()
+	restarted.InitializeManagerWithRepository(restartedRepository)
+	if _, err := restartedRepository.GetUserByName("manu"); err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+	}
+}
+
+func TestEventLogTruncatesTornRecord(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+
+	var manager service.TweetManager
+	repository := service.NewEventLogRepository(path, 0)
+	manager.InitializeManagerWithRepository(repository)
+	publishLoggedTweets(&manager, "first")
+	repository.Close()
+
+	file, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	file.WriteString(`0badc0de {"seq":3,"type":"Tweet`)
+	file.Close()
+
+	//Operation
+	var restarted service.TweetManager
+	restartedRepository := service.NewEventLogRepository(path, 0)
+	err := restarted.InitializeManagerWithRepository(restartedRepository)
+	publishLoggedTweets(&restarted, "second")
+	restartedRepository.Close()
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	events, err := service.ReadEventLog(path)
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if len(events) != 3 || events[2].Seq != 3 {
+		t.Errorf("Expected the torn record to be replaced, got %d events", len(events))
+	}
+}
+
+func TestEventLogFailsWithCorruptRecordInTheMiddle(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+
+	var manager service.TweetManager
+	repository := service.NewEventLogRepository(path, 0)
+	manager.InitializeManagerWithRepository(repository)
+	publishLoggedTweets(&manager, "first")
+	repository.Close()
+
+	contents, _ := ioutil.ReadFile(path)
+	contents[20] = 'X'
+	ioutil.WriteFile(path, contents, 0644)
+
+	//Operation
+	var restarted service.TweetManager
+	err := restarted.InitializeManagerWithRepository(service.NewEventLogRepository(path, 0))
+
+	//Validation
+	if err == nil {
+		t.Error("Expected error")
+	}
+}
+
+func TestEventLogReplaysFromSnapshot(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+
+	var manager service.TweetManager
+	repository := service.NewEventLogRepository(path, 2)
+	manager.InitializeManagerWithRepository(repository)
+	publishLoggedTweets(&manager, "first", "second", "third", "fourth")
+	repository.Close()
+
+	//The snapshot must be enough to restore the events before it
+	contents, _ := ioutil.ReadFile(path)
+	for i := 0; i < 10; i++ {
+		contents[i] = 'X'
+	}
+	ioutil.WriteFile(path, contents, 0644)
+
+	//Operation
+	var restarted service.TweetManager
+	restartedRepository := service.NewEventLogRepository(path, 2)
+	err := restarted.InitializeManagerWithRepository(restartedRepository)
+	defer restartedRepository.Close()
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	restored, _ := restarted.GetTweetsFromUser(domain.NewUser("root", "root"))
+	if len(restored) != 4 {
+		t.Errorf("Expected size is 4 but was %d", len(restored))
+	}
+}
+
+func TestEventLogCanReplayUntilAGivenTime(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+
+	var manager service.TweetManager
+	repository := service.NewEventLogRepository(path, 0)
+	manager.InitializeManagerWithRepository(repository)
+	publishLoggedTweets(&manager, "first")
+	between := time.Now()
+	time.Sleep(time.Millisecond)
+	user := domain.NewUser("root", "root")
+	token, _ := manager.Login(user)
+	second, _ := domain.NewTextTweet(user, "second")
+	manager.PublishTweet(token, second)
+	repository.Close()
+
+	//Operation
+	past, err := service.ReplayEventLog(path, between)
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	tweets, _ := past.GetTweetsFromUser(user.Name)
+	if len(tweets) != 1 || tweets[0].GetText() != "first" {
+		t.Errorf("Expected only the first tweet, got %d tweets", len(tweets))
+	}
+}
@@ -0,0 +1,352 @@
+package service
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/cursoGo/src/domain"
+	jsoniter "github.com/json-iterator/go"
+)
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+//FileTweetRepository is a TweetRepository that saves everything to a JSON file,
+//so that it survives restarts
+type FileTweetRepository struct {
+	*MemoryTweetRepository
+	path string
+	//saved is what was last read from or written to the file
+	saved fileRepositoryData
+}
+
+//fileRepositoryData is what gets written to the file
+type fileRepositoryData struct {
+	Users       []domain.User        `json:"users"`
+	Credentials []domain.Credentials `json:"credentials"`
+	Follows     []Follow             `json:"follows,omitempty"`
+	Requests    []Follow             `json:"requests,omitempty"`
+	Tweets      []domain.TweetRecord `json:"tweets"`
+	Tombstones  []domain.Tombstone   `json:"tombstones,omitempty"`
+	Likes       map[string][]int     `json:"likes,omitempty"`
+	Bookmarks   map[string][]int     `json:"bookmarks,omitempty"`
+	Blocked     map[int][]int        `json:"blocked,omitempty"`
+	Muted       map[int][]int        `json:"muted,omitempty"`
+}
+
+//NewFileTweetRepository returns a FileTweetRepository that uses the file at path.
+//Its contents are read when calling Load
+func NewFileTweetRepository(path string) *FileTweetRepository {
+	return &FileTweetRepository{MemoryTweetRepository: NewMemoryTweetRepository(), path: path}
+}
+
+//Load reads the users, follows and tweets saved in the file. A missing file is an empty repository
+func (r *FileTweetRepository) Load() error {
+	contents, err := ioutil.ReadFile(r.path)
+	if os.IsNotExist(err) {
+		r.MemoryTweetRepository = NewMemoryTweetRepository()
+		r.saved = fileRepositoryData{}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Couldn't read %s, %s", r.path, err.Error())
+	}
+
+	var data fileRepositoryData
+	err = json.Unmarshal(contents, &data)
+	if err != nil {
+		return fmt.Errorf("Couldn't read %s, %s", r.path, err.Error())
+	}
+	memory, err := data.restore(nil)
+	if err != nil {
+		return fmt.Errorf("Couldn't read %s, %s", r.path, err.Error())
+	}
+	r.MemoryTweetRepository = memory
+	r.saved = data
+	return nil
+}
+
+//restore rebuilds the repository that was saved as data. The tweets in existing are kept as they are
+//instead of being rebuilt, so that the ones already handed out are still the stored ones
+func (data fileRepositoryData) restore(existing map[int]domain.Tweeter) (*MemoryTweetRepository, error) {
+	memory := NewMemoryTweetRepository()
+	for _, user := range data.Users {
+		err := memory.AddUser(user)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, credentials := range data.Credentials {
+		err := memory.SetCredentials(credentials)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, follow := range data.Follows {
+		memory.AddFollow(follow.Follower, follow.Followed)
+	}
+	for _, request := range data.Requests {
+		memory.AddFollowRequest(request.Follower, request.Followed)
+	}
+
+	records := make(map[int]domain.TweetRecord)
+	for _, record := range data.Tweets {
+		records[record.ID] = record
+	}
+	restored := make(map[int]domain.Tweeter)
+	var find func(id int) domain.Tweeter
+	find = func(id int) domain.Tweeter {
+		if tweet, ok := restored[id]; ok {
+			return tweet
+		}
+		record, ok := records[id]
+		if !ok {
+			return nil
+		}
+		if tweet, ok := existing[id]; ok {
+			restored[id] = tweet
+			return tweet
+		}
+		tweet, err := record.Restore(find)
+		if err != nil {
+			return nil
+		}
+		restored[id] = tweet
+		return tweet
+	}
+
+	for _, record := range data.Tweets {
+		tweet := find(record.ID)
+		if tweet == nil {
+			return nil, fmt.Errorf("invalid tweet %d", record.ID)
+		}
+		err := memory.AddTweet(tweet)
+		if err != nil {
+			return nil, err
+		}
+	}
+	memory.tombstones = append(memory.tombstones, data.Tombstones...)
+	for _, user := range data.Users {
+		for _, blocked := range data.Blocked[user.ID] {
+			memory.AddBlock(user.ID, blocked)
+		}
+		for _, muted := range data.Muted[user.ID] {
+			memory.AddMute(user.ID, muted)
+		}
+	}
+	for name, ids := range data.Likes {
+		for _, id := range ids {
+			memory.AddLike(name, id)
+		}
+	}
+	for name, ids := range data.Bookmarks {
+		for _, id := range ids {
+			memory.AddBookmark(name, id)
+		}
+	}
+	//The counters of existing tweets may still count what was undone. Retweets share the ones of their tweet
+	for _, tweet := range restored {
+		if _, ok := tweet.(*domain.Retweet); !ok {
+			memory.refreshCounters(tweet)
+		}
+	}
+	return memory, nil
+}
+
+//newFileRepositoryData copies everything stored in memory into a fileRepositoryData
+func newFileRepositoryData(memory *MemoryTweetRepository) (fileRepositoryData, error) {
+	data := fileRepositoryData{
+		Likes:     make(map[string][]int),
+		Bookmarks: make(map[string][]int),
+		Blocked:   make(map[int][]int),
+		Muted:     make(map[int][]int),
+	}
+	for _, user := range memory.users {
+		data.Users = append(data.Users, domain.User{ID: user.ID, Name: user.Name, Protected: user.Protected})
+		if credentials, err := memory.GetCredentials(user.Name); err == nil {
+			data.Credentials = append(data.Credentials, *credentials)
+		}
+		for _, followed := range memory.GetFollowing(user.ID) {
+			data.Follows = append(data.Follows, Follow{Follower: user.ID, Followed: followed})
+		}
+		for _, requester := range memory.GetFollowRequests(user.ID) {
+			data.Requests = append(data.Requests, Follow{Follower: requester, Followed: user.ID})
+		}
+		if liked := memory.GetLikedTweets(user.Name); len(liked) > 0 {
+			data.Likes[user.Name] = liked
+		}
+		if bookmarks := memory.GetBookmarks(user.Name); len(bookmarks) > 0 {
+			data.Bookmarks[user.Name] = bookmarks
+		}
+		if blocked := memory.GetBlocked(user.ID); len(blocked) > 0 {
+			data.Blocked[user.ID] = blocked
+		}
+		if muted := memory.GetMuted(user.ID); len(muted) > 0 {
+			data.Muted[user.ID] = muted
+		}
+	}
+	for _, tweet := range memory.GetTweets() {
+		record, err := domain.NewTweetRecord(tweet)
+		if err != nil {
+			return data, err
+		}
+		data.Tweets = append(data.Tweets, record)
+	}
+	data.Tombstones = memory.GetTombstones()
+	return data, nil
+}
+
+//save writes the whole repository to the file
+func (r *FileTweetRepository) save() error {
+	data, err := newFileRepositoryData(r.MemoryTweetRepository)
+	if err != nil {
+		return err
+	}
+	contents, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = writeFileAtomically(r.path, contents)
+	if err != nil {
+		return err
+	}
+	r.saved = data
+	return nil
+}
+
+//writeFileAtomically replaces the file at path only once the new contents have been fully written
+func writeFileAtomically(path string, contents []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(contents)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+//saveAfter makes a change to the repository and saves it, if the change succeeded. If it can't be saved,
+//the repository goes back to what is in the file, so that a change that failed doesn't take effect later
+func (r *FileTweetRepository) saveAfter(change func() error) error {
+	tweets := make(map[int]domain.Tweeter, len(r.byID))
+	for id, tweet := range r.byID {
+		tweets[id] = tweet
+	}
+	err := change()
+	if err != nil {
+		return err
+	}
+	err = r.save()
+	if err != nil {
+		if memory, restoreErr := r.saved.restore(tweets); restoreErr == nil {
+			r.MemoryTweetRepository = memory
+		}
+		return fmt.Errorf("Couldn't save to %s, %s", r.path, err.Error())
+	}
+	return nil
+}
+
+//AddUser stores a new user and saves the file
+func (r *FileTweetRepository) AddUser(user domain.User) error {
+	return r.saveAfter(func() error { return r.MemoryTweetRepository.AddUser(user) })
+}
+
+//AddUserWithCredentials stores a new user with its credentials and saves the file
+func (r *FileTweetRepository) AddUserWithCredentials(user domain.User, credentials domain.Credentials) error {
+	return r.saveAfter(func() error { return r.MemoryTweetRepository.AddUserWithCredentials(user, credentials) })
+}
+
+//SetCredentials stores the credentials of a user and saves the file
+func (r *FileTweetRepository) SetCredentials(credentials domain.Credentials) error {
+	return r.saveAfter(func() error { return r.MemoryTweetRepository.SetCredentials(credentials) })
+}
+
+//AddTweet stores a tweet and saves the file
+func (r *FileTweetRepository) AddTweet(tweet domain.Tweeter) error {
+	return r.saveAfter(func() error { return r.MemoryTweetRepository.AddTweet(tweet) })
+}
+
+//UpdateTweet saves the changes made to a stored tweet to the file
+func (r *FileTweetRepository) UpdateTweet(tweet domain.Tweeter) error {
+	return r.saveAfter(func() error { return r.MemoryTweetRepository.UpdateTweet(tweet) })
+}
+
+//DeleteTweet removes a stored tweet and saves the file
+func (r *FileTweetRepository) DeleteTweet(tweet domain.Tweeter) error {
+	return r.saveAfter(func() error { return r.MemoryTweetRepository.DeleteTweet(tweet) })
+}
+
+//AddFollow stores a follow and saves the file
+func (r *FileTweetRepository) AddFollow(follower, followed int) error {
+	return r.saveAfter(func() error { return r.MemoryTweetRepository.AddFollow(follower, followed) })
+}
+
+//SetProtected changes if a user is protected and saves the file
+func (r *FileTweetRepository) SetProtected(id int, protected bool) error {
+	return r.saveAfter(func() error { return r.MemoryTweetRepository.SetProtected(id, protected) })
+}
+
+//AddFollowRequest stores a follow request and saves the file
+func (r *FileTweetRepository) AddFollowRequest(requester, owner int) error {
+	return r.saveAfter(func() error { return r.MemoryTweetRepository.AddFollowRequest(requester, owner) })
+}
+
+//RemoveFollowRequest forgets a follow request and saves the file
+func (r *FileTweetRepository) RemoveFollowRequest(requester, owner int) error {
+	return r.saveAfter(func() error { return r.MemoryTweetRepository.RemoveFollowRequest(requester, owner) })
+}
+
+//RemoveFollow forgets a follow and saves the file
+func (r *FileTweetRepository) RemoveFollow(follower, followed int) error {
+	return r.saveAfter(func() error { return r.MemoryTweetRepository.RemoveFollow(follower, followed) })
+}
+
+//AddLike stores a like and saves the file
+func (r *FileTweetRepository) AddLike(name string, id int) error {
+	return r.saveAfter(func() error { return r.MemoryTweetRepository.AddLike(name, id) })
+}
+
+//RemoveLike forgets a like and saves the file
+func (r *FileTweetRepository) RemoveLike(name string, id int) error {
+	return r.saveAfter(func() error { return r.MemoryTweetRepository.RemoveLike(name, id) })
+}
+
+//AddBookmark stores a bookmark and saves the file
+func (r *FileTweetRepository) AddBookmark(name string, id int) error {
+	return r.saveAfter(func() error { return r.MemoryTweetRepository.AddBookmark(name, id) })
+}
+
+//RemoveBookmark forgets a bookmark and saves the file
+func (r *FileTweetRepository) RemoveBookmark(name string, id int) error {
+	return r.saveAfter(func() error { return r.MemoryTweetRepository.RemoveBookmark(name, id) })
+}
+
+//AddBlock stores a block and saves the file
+func (r *FileTweetRepository) AddBlock(blocker, blocked int) error {
+	return r.saveAfter(func() error { return r.MemoryTweetRepository.AddBlock(blocker, blocked) })
+}
+
+//RemoveBlock forgets a block and saves the file
+func (r *FileTweetRepository) RemoveBlock(blocker, blocked int) error {
+	return r.saveAfter(func() error { return r.MemoryTweetRepository.RemoveBlock(blocker, blocked) })
+}
+
+//AddMute stores a mute and saves the file
+func (r *FileTweetRepository) AddMute(muter, muted int) error {
+	return r.saveAfter(func() error { return r.MemoryTweetRepository.AddMute(muter, muted) })
+}
+
+//RemoveMute forgets a mute and saves the file
+func (r *FileTweetRepository) RemoveMute(muter, muted int) error {
+	return r.saveAfter(func() error { return r.MemoryTweetRepository.RemoveMute(muter, muted) })
+}
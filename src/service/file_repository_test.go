@@ -0,0 +1,231 @@
+package service_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/service"
+)
+
+func tempDataFile(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "tweeter")
+	if err != nil {
+		t.Fatalf("Couldn't create temp dir, %s", err.Error())
+	}
+	return filepath.Join(dir, "tweeter.json"), func() { os.RemoveAll(dir) }
+}
+
+func TestFileRepositorySurvivesRestart(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+
+	var manager service.TweetManager
+	manager.InitializeManagerWithRepository(service.NewFileTweetRepository(path))
+	user := domain.NewUser("manu", "hunter2")
+	secondUser := domain.NewUser("gonza", "hunter3")
+	manager.Register(user)
+	manager.Register(secondUser)
+
+	token, _ := manager.Login(secondUser)
+	quoted, _ := domain.NewTextTweet(secondUser, "quote me")
+	manager.PublishTweet(token, quoted)
+	manager.Logout(token)
+
+	token, _ = manager.Login(user)
+	image, _ := domain.NewImageTweet(user, "look", "https://google.com.ar")
+	quote, _ := domain.NewQuoteTweet(user, "nice", quoted)
+	manager.PublishTweet(token, image)
+	manager.PublishTweet(token, quote)
+	manager.FollowUser(token, secondUser.Name)
+	manager.Logout(token)
+
+	//Operation
+	var restarted service.TweetManager
+	err := restarted.InitializeManagerWithRepository(service.NewFileTweetRepository(path))
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	token, _ = restarted.Login(user)
+	timeline, _ := restarted.GetTimeline(token)
+	if len(timeline) != 3 {
+		t.Errorf("Expected size is 3 but was %d", len(timeline))
+		return
+	}
+	restoredImage, ok := timeline[1].(*domain.ImageTweet)
+	if !ok || restoredImage.GetURL() != image.GetURL() || restoredImage.GetID() != image.GetID() {
+		t.Errorf("Image tweet was not restored, got %s", timeline[1])
+	}
+	restoredQuote, ok := timeline[0].(*domain.QuoteTweet)
+	if !ok || restoredQuote.GetQuotedTweet().GetText() != quoted.GetText() {
+		t.Errorf("Quote tweet was not restored, got %s", timeline[0])
+		return
+	}
+	restoredQuoted, _ := restarted.GetTweetByID(quoted.GetID())
+	if restoredQuote.GetQuotedTweet() != restoredQuoted {
+		t.Error("Quoted tweet should be the same one that was restored")
+	}
+}
+
+func TestFileRepositoryRestoresCurrentID(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+
+	var manager service.TweetManager
+	manager.InitializeManagerWithRepository(service.NewFileTweetRepository(path))
+	user := domain.NewUser("root", "root")
+	manager.Register(user)
+	token, _ := manager.Login(user)
+	first, _ := domain.NewTextTweet(user, "first")
+	second, _ := domain.NewTextTweet(user, "second")
+	manager.PublishTweet(token, first)
+	manager.PublishTweet(token, second)
+
+	//Operation
+	var restarted service.TweetManager
+	restarted.InitializeManagerWithRepository(service.NewFileTweetRepository(path))
+	third, _ := domain.NewTextTweet(user, "third")
+
+	//Validation
+	if third.GetID() <= second.GetID() {
+		t.Errorf("Expected an ID greater than %d but was %d", second.GetID(), third.GetID())
+	}
+}
+
+func TestFileRepositorySavesEdits(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+
+	var manager service.TweetManager
+	manager.InitializeManagerWithRepository(service.NewFileTweetRepository(path))
+	user := domain.NewUser("root", "root")
+	manager.Register(user)
+	token, _ := manager.Login(user)
+	tweet, _ := domain.NewTextTweet(user, "sample")
+	manager.PublishTweet(token, tweet)
+
+	//Operation
+	manager.EditTweetTextByID(token, tweet.GetID(), "modified sample")
+
+	//Validation
+	repository := service.NewFileTweetRepository(path)
+	repository.Load()
+	restored, err := repository.GetTweetByID(tweet.GetID())
+	if err != nil || restored.GetText() != "modified sample" {
+		t.Error("Edit was not saved")
+	}
+}
+
+func TestFileRepositoryUndoesChangesThatCantBeSaved(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+
+	var manager service.TweetManager
+	manager.InitializeManagerWithRepository(service.NewFileTweetRepository(path))
+	tokens := newSocialManager(&manager)
+	tweet := publishText(t, &manager, tokens[1], "gonza", "sample")
+	contents, _ := ioutil.ReadFile(path)
+	os.RemoveAll(filepath.Dir(path))
+
+	//Operation
+	followErr := manager.FollowUser(tokens[0], "gonza")
+	likeErr := manager.LikeTweet(tokens[0], tweet.GetID())
+	deleteErr := manager.DeleteTweetByID(tokens[1], tweet.GetID())
+	os.MkdirAll(filepath.Dir(path), 0755)
+	ioutil.WriteFile(path, contents, 0644)
+	manager.FollowUser(tokens[2], "gonza")
+
+	//Validation
+	if followErr == nil || likeErr == nil || deleteErr == nil {
+		t.Errorf("Expected errors but got %v, %v and %v", followErr, likeErr, deleteErr)
+	}
+	var restarted service.TweetManager
+	restarted.InitializeManagerWithRepository(service.NewFileTweetRepository(path))
+	for _, m := range []*service.TweetManager{&manager, &restarted} {
+		followers, _ := m.GetFollowers(domain.NewUser("gonza", ""))
+		expectUsers(t, followers, "root")
+		restored, err := m.GetTweetByID(tweet.GetID())
+		if err != nil || restored.GetCounters().Likes != 0 {
+			t.Errorf("Expected the tweet without likes but got %v, %v", restored, err)
+		}
+	}
+	if restored, _ := manager.GetTweetByID(tweet.GetID()); restored != tweet {
+		t.Error("Expected the tweet to still be the one that was published")
+	}
+}
+
+func TestFailedRegistrationCanBeRetried(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+
+	var manager service.TweetManager
+	manager.InitializeManagerWithRepository(service.NewFileTweetRepository(path))
+	user := domain.NewUser("manu", "hunter2")
+	os.RemoveAll(filepath.Dir(path))
+
+	//Operation
+	failedErr := manager.Register(user)
+	os.MkdirAll(filepath.Dir(path), 0755)
+	err := manager.Register(user)
+
+	//Validation
+	if failedErr == nil {
+		t.Error("Expected error")
+	}
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if _, err := manager.Login(user); err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+	}
+}
+
+func TestFileRepositoryFailsWithCorruptFile(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+	ioutil.WriteFile(path, []byte("{not json"), 0644)
+
+	//Operation
+	var manager service.TweetManager
+	err := manager.InitializeManagerWithRepository(service.NewFileTweetRepository(path))
+
+	//Validation
+	if err == nil {
+		t.Error("Expected error")
+	}
+}
+
+func TestFileRepositoryUpgradesPlainTextPasswords(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+	ioutil.WriteFile(path, []byte(`{"users":[{"Name":"root","Password":"root"}],"following":{},"tweets":[]}`), 0644)
+	var manager service.TweetManager
+	manager.InitializeManagerWithRepository(service.NewFileTweetRepository(path))
+
+	//Operation
+	_, err := manager.Login(domain.NewUser("root", "root"))
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	contents, _ := ioutil.ReadFile(path)
+	if strings.Contains(string(contents), `"Password"`) || strings.Contains(string(contents), "plain$") {
+		t.Errorf("The password was not hashed, file is %s", contents)
+	}
+}
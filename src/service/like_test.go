@@ -0,0 +1,312 @@
+package service_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/service"
+	"github.com/cursoGo/src/utility"
+)
+
+func TestCanLikeTweet(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	manuToken, gonzaToken, _, tweet := newRetweetManager(t, &manager)
+
+	//Operation
+	err := manager.LikeTweet(gonzaToken, tweet.GetID())
+	manager.LikeTweet(manuToken, tweet.GetID())
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	users, _ := manager.GetLikes(gonzaToken, tweet.GetID())
+	if len(users) != 2 || users[0].Name != "gonza" || users[1].Name != "manu" {
+		t.Errorf("Unexpected likes %v", users)
+	}
+	if !strings.HasSuffix(tweet.String(), "(2 likes)") {
+		t.Errorf("Expected the likes in %q", tweet.String())
+	}
+}
+
+func TestCantLikeTweetTwice(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	_, gonzaToken, _, tweet := newRetweetManager(t, &manager)
+	manager.LikeTweet(gonzaToken, tweet.GetID())
+
+	//Operation
+	err := manager.LikeTweet(gonzaToken, tweet.GetID())
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't like tweet, You already liked that tweet")
+}
+
+func TestCanUnlikeTweet(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	_, gonzaToken, _, tweet := newRetweetManager(t, &manager)
+	manager.LikeTweet(gonzaToken, tweet.GetID())
+
+	//Operation
+	err := manager.UnlikeTweet(gonzaToken, tweet.GetID())
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if users, _ := manager.GetLikes(gonzaToken, tweet.GetID()); len(users) != 0 {
+		t.Errorf("Expected no likes but got %v", users)
+	}
+	if tweet.GetCounters().Likes != 0 {
+		t.Errorf("Expected no likes in %s", tweet)
+	}
+}
+
+func TestCantUnlikeTweetThatWasNotLiked(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	_, gonzaToken, _, tweet := newRetweetManager(t, &manager)
+
+	//Operation
+	err := manager.UnlikeTweet(gonzaToken, tweet.GetID())
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't unlike tweet, You haven't liked that tweet")
+}
+
+func TestCantLikeWithoutLoggingIn(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	_, _, _, tweet := newRetweetManager(t, &manager)
+
+	//Operation
+	err := manager.LikeTweet("", tweet.GetID())
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't like tweet, Not logged in")
+}
+
+func TestLikingRetweetLikesItsTweet(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	manuToken, gonzaToken, _, tweet := newRetweetManager(t, &manager)
+	retweet, _ := manager.Retweet(gonzaToken, tweet.GetID())
+
+	//Operation
+	manager.LikeTweet(manuToken, retweet.GetID())
+
+	//Validation
+	if users, _ := manager.GetLikes(gonzaToken, tweet.GetID()); len(users) != 1 || users[0].Name != "manu" {
+		t.Errorf("Unexpected likes %v", users)
+	}
+	if !strings.HasSuffix(retweet.String(), "(1 like, 1 retweet)") {
+		t.Errorf("Expected the counters in %q", retweet.String())
+	}
+}
+
+func TestCanGetLikedTweetsNewestLikeFirst(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	_, gonzaToken, rootToken, tweet := newRetweetManager(t, &manager)
+	other, _ := manager.NewTextTweet(domain.NewUser("root", ""), "me too")
+	manager.PublishTweet(rootToken, other)
+
+	//Operation
+	manager.LikeTweet(gonzaToken, other.GetID())
+	manager.LikeTweet(gonzaToken, tweet.GetID())
+	liked, err := manager.GetLikedTweets("", domain.NewUser("gonza", ""))
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	expectTweets(t, liked, []domain.Tweeter{tweet, other})
+}
+
+func TestLikedTweetsHideTweetsTheViewerCantSee(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens, protected := newProtectedManager(t, &manager)
+	public := publishText(t, &manager, tokens[0], "manu", "for everyone")
+	manager.FollowUser(tokens[2], "gonza")
+	manager.ApproveFollowRequest(tokens[1], "root")
+	manager.LikeTweet(tokens[2], protected.GetID())
+	manager.LikeTweet(tokens[2], public.GetID())
+
+	//Operation
+	seenByManu, err := manager.GetLikedTweets(tokens[0], domain.NewUser("root", ""))
+	seenByRoot, _ := manager.GetLikedTweets(tokens[2], domain.NewUser("root", ""))
+	manager.BlockUser(tokens[0], "gonza")
+	seenByGonza, _ := manager.GetLikedTweets(tokens[1], domain.NewUser("root", ""))
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	expectTweets(t, seenByManu, []domain.Tweeter{public})
+	expectTweets(t, seenByRoot, []domain.Tweeter{public, protected})
+	expectTweets(t, seenByGonza, []domain.Tweeter{protected})
+}
+
+func TestCantSeeLikedTweetsOfProtectedUser(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens, _ := newProtectedManager(t, &manager)
+
+	//Operation
+	_, err := manager.GetLikedTweets(tokens[0], domain.NewUser("gonza", ""))
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "That account is protected")
+}
+
+func TestLikesSurviveEdits(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	_, gonzaToken, rootToken, tweet := newRetweetManager(t, &manager)
+	manager.LikeTweet(gonzaToken, tweet.GetID())
+
+	//Operation
+	err := manager.EditTweetTextByID(rootToken, tweet.GetID(), "edited")
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	edited, _ := manager.GetTweetByID(tweet.GetID())
+	if users, _ := manager.GetLikes(gonzaToken, tweet.GetID()); len(users) != 1 || edited.GetCounters().Likes != 1 {
+		t.Errorf("The likes were lost after editing %s", edited)
+	}
+}
+
+func TestDeletingTweetRemovesItsLikesAndBookmarks(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	_, gonzaToken, rootToken, tweet := newRetweetManager(t, &manager)
+	manager.LikeTweet(gonzaToken, tweet.GetID())
+	manager.BookmarkTweet(gonzaToken, tweet.GetID())
+
+	//Operation
+	manager.DeleteTweetByID(rootToken, tweet.GetID())
+
+	//Validation
+	if liked, _ := manager.GetLikedTweets("", domain.NewUser("gonza", "")); len(liked) != 0 {
+		t.Errorf("Expected no liked tweets but got %d", len(liked))
+	}
+	if bookmarks, _ := manager.GetBookmarks(gonzaToken); len(bookmarks) != 0 {
+		t.Errorf("Expected no bookmarks but got %d", len(bookmarks))
+	}
+}
+
+func TestCanBookmarkTweets(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	manuToken, gonzaToken, _, tweet := newRetweetManager(t, &manager)
+
+	//Operation
+	err := manager.BookmarkTweet(gonzaToken, tweet.GetID())
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	bookmarks, _ := manager.GetBookmarks(gonzaToken)
+	expectTweets(t, bookmarks, []domain.Tweeter{tweet})
+	if others, _ := manager.GetBookmarks(manuToken); len(others) != 0 {
+		t.Error("Bookmarks should be private")
+	}
+	if tweet.GetCounters() != (domain.Counters{}) {
+		t.Errorf("Bookmarks should not be counted, got %s", tweet)
+	}
+}
+
+func TestCanRemoveBookmark(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	_, gonzaToken, _, tweet := newRetweetManager(t, &manager)
+	manager.BookmarkTweet(gonzaToken, tweet.GetID())
+
+	//Operation
+	err := manager.RemoveBookmark(gonzaToken, tweet.GetID())
+	again := manager.RemoveBookmark(gonzaToken, tweet.GetID())
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	utility.ValidateExpectedError(t, again, "Couldn't remove bookmark, That tweet is not bookmarked")
+}
+
+func TestLikesAndBookmarksSurviveRestart(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+	var manager service.TweetManager
+	manager.InitializeManagerWithRepository(service.NewFileTweetRepository(path))
+	_, gonzaToken, _, tweet := newRetweetManager(t, &manager)
+	manager.LikeTweet(gonzaToken, tweet.GetID())
+	manager.BookmarkTweet(gonzaToken, tweet.GetID())
+
+	//Operation
+	var restarted service.TweetManager
+	restarted.InitializeManagerWithRepository(service.NewFileTweetRepository(path))
+	gonzaToken, _ = restarted.Login(domain.NewUser("gonza", "hunter3"))
+
+	//Validation
+	restored, _ := restarted.GetTweetByID(tweet.GetID())
+	if restored == nil || restored.GetCounters().Likes != 1 {
+		t.Errorf("The likes were lost, got %s", restored)
+	}
+	if bookmarks, _ := restarted.GetBookmarks(gonzaToken); len(bookmarks) != 1 {
+		t.Errorf("Expected 1 bookmark but got %d", len(bookmarks))
+	}
+}
+
+func TestLikesAreReplayedFromEventLog(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+	var manager service.TweetManager
+	repository := service.NewEventLogRepository(path, 0)
+	manager.InitializeManagerWithRepository(repository)
+	manuToken, gonzaToken, _, tweet := newRetweetManager(t, &manager)
+	manager.LikeTweet(gonzaToken, tweet.GetID())
+	manager.LikeTweet(manuToken, tweet.GetID())
+	manager.UnlikeTweet(gonzaToken, tweet.GetID())
+	repository.Close()
+
+	//Operation
+	var restarted service.TweetManager
+	restartedRepository := service.NewEventLogRepository(path, 0)
+	restarted.InitializeManagerWithRepository(restartedRepository)
+	defer restartedRepository.Close()
+
+	//Validation
+	users, _ := restarted.GetLikes("", tweet.GetID())
+	if len(users) != 1 || users[0].Name != "manu" {
+		t.Errorf("Unexpected likes %v", users)
+	}
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/events"
+)
+
+//Sizes of a TimelineStream
+const (
+	//MaxMissedTweets is how many of the tweets missed since the last one seen a resumed stream gets, the newest ones
+	MaxMissedTweets = 100
+	//timelineStreamBuffer is how many new tweets wait for the watcher. When more arrive, the oldest are dropped
+	timelineStreamBuffer = 64
+)
+
+//TimelineStream gets the new tweets of the timeline of a user as they are published
+type TimelineStream struct {
+	//Missed are the tweets published while the watcher wasn't watching, oldest first, when the stream was resumed
+	Missed []domain.Tweeter
+	//Tweets receives the new tweets. It is never closed, so watchers stop reading it after closing the stream
+	Tweets <-chan domain.Tweeter
+
+	tweets       chan domain.Tweeter
+	subscription *events.Subscription
+	//missed are the IDs of the Missed tweets, which aren't received again if they were published as the stream began
+	missed map[int]bool
+	done   chan struct{}
+	once   sync.Once
+}
+
+//Close stops the stream, which can't be used anymore
+func (s *TimelineStream) Close() {
+	s.once.Do(func() {
+		close(s.done)
+		s.subscription.Unsubscribe()
+	})
+}
+
+//WatchTimeline returns a stream of the tweets that appear in the timeline of the user logged in with a session from now on
+func (m *TweetManager) WatchTimeline(token string) (*TimelineStream, error) {
+	return m.watchTimeline(token, nil)
+}
+
+//WatchTimelineAfter is WatchTimeline for a watcher that already saw the tweet with that ID, resuming the stream
+//from it: the tweets of the timeline published after it are given first as the Missed ones
+func (m *TweetManager) WatchTimelineAfter(token string, id int) (*TimelineStream, error) {
+	return m.watchTimeline(token, &id)
+}
+
+func (m *TweetManager) watchTimeline(token string, lastSeen *int) (*TimelineStream, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	user, err := m.loggedInUser(token)
+	if err != nil {
+		return nil, ErrNoUserLoggedIn
+	}
+	tweets := make(chan domain.Tweeter)
+	stream := &TimelineStream{Tweets: tweets, tweets: tweets, missed: make(map[int]bool), done: make(chan struct{})}
+	if lastSeen != nil {
+		stream.Missed, err = m.missedTweets(*user, *lastSeen)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, tweet := range stream.Missed {
+		stream.missed[tweet.GetID()] = true
+	}
+	name := user.Name
+	stream.subscription = m.bus.SubscribeAsync(func(event events.Event) {
+		published, ok := event.(events.TweetPublished)
+		if !ok || stream.missed[published.Tweet.GetID()] || !m.inTimelineOf(name, published.Tweet) {
+			return
+		}
+		select {
+		case stream.tweets <- published.Tweet:
+		case <-stream.done:
+		}
+	}, timelineStreamBuffer, events.DropOldest)
+	return stream, nil
+}
+
+//missedTweets returns the newest MaxMissedTweets tweets of the timeline of user that come before the one with that ID,
+//oldest first. The timeline is in date order, which may not be the order of the IDs. If the tweet isn't in the timeline
+//anymore, as when it was deleted, every tweet of the timeline was missed
+func (m *TweetManager) missedTweets(user domain.User, id int) ([]domain.Tweeter, error) {
+	authors, err := m.getTimelineAuthors(user)
+	if err != nil {
+		return nil, err
+	}
+	var missed []domain.Tweeter
+	merge := newTimelineMerge(authors)
+	for tweet, ok := merge.next(); ok && tweet.GetID() != id && len(missed) < MaxMissedTweets; tweet, ok = merge.next() {
+		missed = append(missed, tweet)
+	}
+	for i, j := 0, len(missed)-1; i < j; i, j = i+1, j-1 {
+		missed[i], missed[j] = missed[j], missed[i]
+	}
+	return missed, nil
+}
+
+//inTimelineOf returns if a tweet goes in the timeline of the user with that name: if it is theirs
+//or of someone they follow, and they can see it
+func (m *TweetManager) inTimelineOf(name string, tweet domain.Tweeter) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	user, err := m.repository.GetUserByName(name)
+	if err != nil {
+		return false
+	}
+	author, err := m.repository.GetUserByName(tweet.GetUser().Name)
+	if err != nil || (author.ID != user.ID && !m.repository.IsFollowing(user.ID, author.ID)) {
+		return false
+	}
+	return !m.hiddenFrom(user, m.repository.GetMuted(user.ID))(tweet)
+}
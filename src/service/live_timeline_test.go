@@ -0,0 +1,136 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/service"
+)
+
+//nextStreamed returns the next tweet of a stream, or nil if there is none within a short while
+func nextStreamed(stream *service.TimelineStream) domain.Tweeter {
+	select {
+	case tweet := <-stream.Tweets:
+		return tweet
+	case <-time.After(100 * time.Millisecond):
+		return nil
+	}
+}
+
+func TestStreamSkipsMutedAndBlockedUsers(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	manager.FollowUser(tokens[0], "gonza")
+	manager.FollowUser(tokens[0], "root")
+	manager.MuteUser(tokens[0], "root")
+	stream, err := manager.WatchTimeline(tokens[0])
+	if err != nil {
+		t.Fatalf("Unexpected error, %s", err.Error())
+	}
+	defer stream.Close()
+
+	//Operation
+	publishText(t, &manager, tokens[2], "root", "muted")
+	tweet := publishText(t, &manager, tokens[1], "gonza", "hola")
+
+	//Validation
+	if streamed := nextStreamed(stream); streamed == nil || streamed.GetID() != tweet.GetID() {
+		t.Errorf("Expected tweet %d but got %v", tweet.GetID(), streamed)
+	}
+}
+
+func TestResumedStreamHasMissedTweetsOldestFirst(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	manager.FollowUser(tokens[0], "gonza")
+	seen := publishText(t, &manager, tokens[1], "gonza", "seen")
+	first := publishText(t, &manager, tokens[1], "gonza", "first")
+	second := publishText(t, &manager, tokens[0], "manu", "second")
+
+	//Operation
+	stream, err := manager.WatchTimelineAfter(tokens[0], seen.GetID())
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	defer stream.Close()
+	expectTweets(t, stream.Missed, []domain.Tweeter{first, second})
+}
+
+func TestResumedStreamFollowsDatesInsteadOfIDs(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	clock := domain.NewFixedClock(time.Date(2018, time.March, 1, 12, 0, 0, 0, time.UTC))
+	manager.InitializeManagerWith(service.NewMemoryTweetRepository(), domain.NewFixedIDGenerator(50, 10, 40, 20), clock)
+	tokens := newSocialManager(&manager)
+	manager.FollowUser(tokens[0], "gonza")
+	var tweets []domain.Tweeter
+	for _, text := range []string{"old", "seen", "first", "second"} {
+		tweets = append(tweets, publishText(t, &manager, tokens[1], "gonza", text))
+		clock.Advance(time.Minute)
+	}
+
+	//Operation
+	stream, err := manager.WatchTimelineAfter(tokens[0], tweets[1].GetID())
+	deleted, _ := manager.WatchTimelineAfter(tokens[0], 30)
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	defer stream.Close()
+	defer deleted.Close()
+	expectTweets(t, stream.Missed, []domain.Tweeter{tweets[2], tweets[3]})
+	expectTweets(t, deleted.Missed, tweets)
+}
+
+func TestStreamGetsTweetsCreatedBeforeItBegan(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	manager.FollowUser(tokens[0], "gonza")
+	late, _ := manager.NewTextTweet(domain.NewUser("gonza", ""), "late")
+	seen := publishText(t, &manager, tokens[1], "gonza", "seen")
+	stream, err := manager.WatchTimelineAfter(tokens[0], seen.GetID())
+	if err != nil {
+		t.Fatalf("Unexpected error, %s", err.Error())
+	}
+	defer stream.Close()
+
+	//Operation
+	manager.PublishTweet(tokens[1], late)
+
+	//Validation
+	if streamed := nextStreamed(stream); streamed == nil || streamed.GetID() != late.GetID() {
+		t.Errorf("Expected tweet %d but got %v", late.GetID(), streamed)
+	}
+}
+
+func TestClosedStreamGetsNoTweets(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	stream, _ := manager.WatchTimeline(tokens[0])
+
+	//Operation
+	stream.Close()
+	publishText(t, &manager, tokens[0], "manu", "hola")
+
+	//Validation
+	if streamed := nextStreamed(stream); streamed != nil {
+		t.Errorf("Expected no tweets but got %v", streamed)
+	}
+	if manager.EventBus().Subscribers() != 0 {
+		t.Error("The stream is still subscribed")
+	}
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cursoGo/src/domain"
+)
+
+//Conversation is a conversation as seen by one of its members
+type Conversation struct {
+	ID int
+	//Members are the other members of the conversation
+	Members     []domain.User
+	LastMessage domain.Message
+	//Unread is how many messages of the other members weren't read yet
+	Unread int
+}
+
+//String returns a formatted string of the Conversation, with its last message
+func (c Conversation) String() string {
+	names := make([]string, 0, len(c.Members))
+	for _, member := range c.Members {
+		names = append(names, "@"+member.Name)
+	}
+	unread := ""
+	if c.Unread > 0 {
+		unread = fmt.Sprintf(" (%d unread)", c.Unread)
+	}
+	return fmt.Sprintf("[%d] %s%s\n  %s", c.ID, strings.Join(names, ", "), unread, c.LastMessage)
+}
+
+//MessagePage is a page of a conversation, oldest messages first
+type MessagePage struct {
+	Messages []domain.Message
+	HasOlder bool
+	HasNewer bool
+}
+
+//Older asks for the page that precedes this one, with older messages
+func (p MessagePage) Older(limit int) PageRequest {
+	if len(p.Messages) == 0 {
+		return FirstPage(limit)
+	}
+	return PageBefore(p.Messages[0].ID, limit)
+}
+
+//Newer asks for the page that follows this one, with newer messages
+func (p MessagePage) Newer(limit int) PageRequest {
+	if len(p.Messages) == 0 {
+		return FirstPage(limit)
+	}
+	return PageAfter(p.Messages[len(p.Messages)-1].ID, limit)
+}
+
+//pageMessages returns the page of messages, which are oldest first, asked for by request.
+//Without a cursor, it is the page with the newest messages
+func pageMessages(messages []domain.Message, request PageRequest) MessagePage {
+	low, high := 0, len(messages)
+	if request.HasBefore {
+		high = sort.Search(len(messages), func(i int) bool { return messages[i].ID >= request.Before })
+	}
+	if request.HasAfter {
+		low = sort.Search(len(messages), func(i int) bool { return messages[i].ID > request.After })
+	}
+	start, end := high-request.limit(), high
+	if request.HasAfter {
+		start, end = low, low+request.limit()
+	}
+	if start < low {
+		start = low
+	}
+	if end > high {
+		end = high
+	}
+	if start > end {
+		start = end
+	}
+	return MessagePage{
+		Messages: append([]domain.Message(nil), messages[start:end]...),
+		HasOlder: start > 0,
+		HasNewer: end < len(messages),
+	}
+}
+
+//MessageManager sends direct messages between the users of a TweetManager, which it uses to know
+//who is logged in and who blocked whom. It is safe for concurrent use
+type MessageManager struct {
+	tweets     *TweetManager
+	repository MessageRepository
+	ids        *domain.SequentialIDGenerator
+	clock      domain.Clock
+	mutex      sync.RWMutex
+}
+
+//NewMessageManager returns a MessageManager for the users of tweets that keeps messages in memory
+func NewMessageManager(tweets *TweetManager) *MessageManager {
+	return NewMessageManagerWith(tweets, NewMemoryMessageRepository(), domain.SystemClock{})
+}
+
+//NewMessageManagerWith returns a MessageManager for the users of tweets that stores messages
+//in repository and tells the time with clock
+func NewMessageManagerWith(tweets *TweetManager, repository MessageRepository, clock domain.Clock) *MessageManager {
+	ids := domain.NewSequentialIDGenerator()
+	ids.Restore(repository.GetLastMessageID())
+	return &MessageManager{tweets: tweets, repository: repository, ids: ids, clock: clock}
+}
+
+//SendMessage sends a message from the user logged in with a session to the users with those names.
+//Messages to the same users go to the same conversation, a group one if there are many of them.
+//Users that blocked or were blocked by the sender can't be messaged
+func (m *MessageManager) SendMessage(token string, names []string, text string) (*domain.Message, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	sender, err := m.tweets.GetLoggedInUser(token)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't send message, %w", err)
+	}
+	members, err := m.recipients(*sender, names)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't send message, %w", err)
+	}
+	conversation, err := m.repository.FindConversation(members)
+	if errors.Is(err, ErrConversationNotFound) {
+		conversation, err = m.repository.AddConversation(members)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't send message, %w", err)
+	}
+	message, err := domain.NewMessage(m.ids.NextID(), conversation, *sender, text, m.clock.Now())
+	if err == nil {
+		err = m.repository.AddMessage(*message)
+	}
+	if err == nil {
+		err = m.repository.SetLastRead(conversation, sender.ID, message.ID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't send message, %w", err)
+	}
+	return message, nil
+}
+
+//recipients returns the IDs of the sender and of the users with those names, if sender can message them
+func (m *MessageManager) recipients(sender domain.User, names []string) ([]int, error) {
+	if len(names) == 0 {
+		return nil, ErrNoRecipients
+	}
+	members := []int{sender.ID}
+	for _, name := range names {
+		recipient, err := m.tweets.GetUserByName(strings.TrimPrefix(name, "@"))
+		if err != nil {
+			return nil, err
+		}
+		if recipient.ID == sender.ID {
+			return nil, ErrCantMessageYourself
+		}
+		if m.tweets.AreBlocked(sender, *recipient) {
+			return nil, ErrBlocked
+		}
+		if !containsID(members, recipient.ID) {
+			members = append(members, recipient.ID)
+		}
+	}
+	return members, nil
+}
+
+//GetInbox returns the conversations of the user logged in with a session, the one with the latest message first
+func (m *MessageManager) GetInbox(token string) ([]Conversation, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	user, err := m.tweets.GetLoggedInUser(token)
+	if err != nil {
+		return nil, err
+	}
+	var inbox []Conversation
+	for _, id := range m.repository.GetConversationsOf(user.ID) {
+		conversation, err := m.conversation(*user, id)
+		if err != nil {
+			return nil, err
+		}
+		inbox = append(inbox, conversation)
+	}
+	sort.SliceStable(inbox, func(i, j int) bool {
+		first, second := inbox[i].LastMessage, inbox[j].LastMessage
+		if !first.Date.Equal(second.Date) {
+			return first.Date.After(second.Date)
+		}
+		return first.ID > second.ID
+	})
+	return inbox, nil
+}
+
+//conversation returns the conversation with that ID as seen by user
+func (m *MessageManager) conversation(user domain.User, id int) (Conversation, error) {
+	members, err := m.repository.GetMembers(id)
+	if err != nil {
+		return Conversation{}, err
+	}
+	messages, err := m.repository.GetMessages(id)
+	if err != nil {
+		return Conversation{}, err
+	}
+	conversation := Conversation{ID: id}
+	for _, member := range members {
+		if member == user.ID {
+			continue
+		}
+		if other, err := m.tweets.GetUserByID(member); err == nil {
+			conversation.Members = append(conversation.Members, domain.User{ID: other.ID, Name: other.Name})
+		}
+	}
+	if len(messages) > 0 {
+		conversation.LastMessage = messages[len(messages)-1]
+	}
+	lastRead := m.repository.GetLastRead(id, user.ID)
+	for _, message := range messages {
+		if message.ID > lastRead && message.Sender.ID != user.ID {
+			conversation.Unread++
+		}
+	}
+	return conversation, nil
+}
+
+//memberOf returns the user logged in with a session, if they are a member of the conversation with that ID
+func (m *MessageManager) memberOf(token string, id int) (*domain.User, error) {
+	user, err := m.tweets.GetLoggedInUser(token)
+	if err != nil {
+		return nil, err
+	}
+	members, err := m.repository.GetMembers(id)
+	if err != nil {
+		return nil, err
+	}
+	if !containsID(members, user.ID) {
+		return nil, ErrNotInConversation
+	}
+	return user, nil
+}
+
+//ReadConversation returns a page of the conversation with that ID, if the user logged in with a session is one of its members.
+//It doesn't mark the messages as read, see MarkAsRead
+func (m *MessageManager) ReadConversation(token string, id int, request PageRequest) (MessagePage, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	if _, err := m.memberOf(token, id); err != nil {
+		return MessagePage{}, fmt.Errorf("Couldn't read conversation, %w", err)
+	}
+	messages, err := m.repository.GetMessages(id)
+	if err != nil {
+		return MessagePage{}, fmt.Errorf("Couldn't read conversation, %w", err)
+	}
+	return pageMessages(messages, request), nil
+}
+
+//MarkAsRead marks every message of the conversation with that ID as read by the user logged in with a session
+func (m *MessageManager) MarkAsRead(token string, id int) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	user, err := m.memberOf(token, id)
+	if err != nil {
+		return fmt.Errorf("Couldn't mark conversation as read, %w", err)
+	}
+	messages, err := m.repository.GetMessages(id)
+	if err != nil || len(messages) == 0 {
+		return err
+	}
+	return m.repository.SetLastRead(id, user.ID, messages[len(messages)-1].ID)
+}
+
+//GetUnreadCount returns how many messages the user logged in with a session didn't read, in all their conversations
+func (m *MessageManager) GetUnreadCount(token string) (int, error) {
+	inbox, err := m.GetInbox(token)
+	if err != nil {
+		return 0, err
+	}
+	unread := 0
+	for _, conversation := range inbox {
+		unread += conversation.Unread
+	}
+	return unread, nil
+}
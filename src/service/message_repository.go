@@ -0,0 +1,157 @@
+package service
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cursoGo/src/domain"
+)
+
+//MessageRepository is where the MessageManager stores conversations and their messages.
+//Members of conversations are kept by user ID
+type MessageRepository interface {
+	AddConversation(members []int) (int, error)
+	FindConversation(members []int) (int, error)
+	GetMembers(conversation int) ([]int, error)
+	GetConversationsOf(user int) []int
+
+	AddMessage(domain.Message) error
+	GetMessages(conversation int) ([]domain.Message, error)
+	GetLastMessageID() int
+	SetLastRead(conversation int, user int, id int) error
+	GetLastRead(conversation int, user int) int
+}
+
+//memoryConversation is a conversation as the MemoryMessageRepository keeps it
+type memoryConversation struct {
+	members  []int
+	messages []domain.Message
+	lastRead map[int]int
+}
+
+//MemoryMessageRepository is a MessageRepository that keeps everything in memory
+type MemoryMessageRepository struct {
+	conversations []*memoryConversation
+	byMembers     map[string]int
+	byUser        map[int][]int
+	lastMessageID int
+}
+
+//NewMemoryMessageRepository returns a new empty MemoryMessageRepository
+func NewMemoryMessageRepository() *MemoryMessageRepository {
+	return &MemoryMessageRepository{
+		byMembers:     make(map[string]int),
+		byUser:        make(map[int][]int),
+		lastMessageID: -1,
+	}
+}
+
+//membersKey returns the same key for the same members, whatever their order
+func membersKey(members []int) string {
+	sorted := append([]int(nil), members...)
+	sort.Ints(sorted)
+	ids := make([]string, 0, len(sorted))
+	for _, id := range sorted {
+		ids = append(ids, strconv.Itoa(id))
+	}
+	return strings.Join(ids, ",")
+}
+
+//AddConversation stores a new conversation between members and returns its ID.
+//There can only be one conversation between the same members
+func (r *MemoryMessageRepository) AddConversation(members []int) (int, error) {
+	key := membersKey(members)
+	if _, ok := r.byMembers[key]; ok {
+		return 0, ErrConversationExists
+	}
+	id := len(r.conversations)
+	r.conversations = append(r.conversations, &memoryConversation{
+		members:  append([]int(nil), members...),
+		lastRead: make(map[int]int),
+	})
+	r.byMembers[key] = id
+	for _, member := range members {
+		r.byUser[member] = append(r.byUser[member], id)
+	}
+	return id, nil
+}
+
+//FindConversation returns the ID of the conversation between members
+func (r *MemoryMessageRepository) FindConversation(members []int) (int, error) {
+	id, ok := r.byMembers[membersKey(members)]
+	if !ok {
+		return 0, ErrConversationNotFound
+	}
+	return id, nil
+}
+
+func (r *MemoryMessageRepository) conversation(id int) (*memoryConversation, error) {
+	if id < 0 || id >= len(r.conversations) {
+		return nil, ErrConversationNotFound
+	}
+	return r.conversations[id], nil
+}
+
+//GetMembers returns the IDs of the members of a conversation
+func (r *MemoryMessageRepository) GetMembers(conversation int) ([]int, error) {
+	found, err := r.conversation(conversation)
+	if err != nil {
+		return nil, err
+	}
+	return append([]int(nil), found.members...), nil
+}
+
+//GetConversationsOf returns the IDs of the conversations a user is a member of, in the order they began
+func (r *MemoryMessageRepository) GetConversationsOf(user int) []int {
+	return append([]int(nil), r.byUser[user]...)
+}
+
+//AddMessage stores a message in its conversation
+func (r *MemoryMessageRepository) AddMessage(message domain.Message) error {
+	found, err := r.conversation(message.ConversationID)
+	if err != nil {
+		return err
+	}
+	found.messages = append(found.messages, message)
+	if message.ID > r.lastMessageID {
+		r.lastMessageID = message.ID
+	}
+	return nil
+}
+
+//GetMessages returns the messages of a conversation, oldest first
+func (r *MemoryMessageRepository) GetMessages(conversation int) ([]domain.Message, error) {
+	found, err := r.conversation(conversation)
+	if err != nil {
+		return nil, err
+	}
+	return append([]domain.Message(nil), found.messages...), nil
+}
+
+//GetLastMessageID returns the ID of the last stored message, or -1 if there are none
+func (r *MemoryMessageRepository) GetLastMessageID() int {
+	return r.lastMessageID
+}
+
+//SetLastRead stores that a user read a conversation up to the message with that ID
+func (r *MemoryMessageRepository) SetLastRead(conversation int, user int, id int) error {
+	found, err := r.conversation(conversation)
+	if err != nil {
+		return err
+	}
+	found.lastRead[user] = id
+	return nil
+}
+
+//GetLastRead returns the ID of the last message of a conversation that a user read, or -1 if they read none
+func (r *MemoryMessageRepository) GetLastRead(conversation int, user int) int {
+	found, err := r.conversation(conversation)
+	if err != nil {
+		return -1
+	}
+	if id, ok := found.lastRead[user]; ok {
+		return id
+	}
+	return -1
+}
@@ -0,0 +1,198 @@
+package service_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/service"
+	"github.com/cursoGo/src/utility"
+)
+
+//newMessageManager returns a MessageManager for the users of newSocialManager, whose clock moves a minute
+//every time it is asked, and their tokens
+func newMessageManager(manager *service.TweetManager) (*service.MessageManager, []string) {
+	manager.InitializeManager()
+	tokens := newSocialManager(manager)
+	clock := domain.NewFixedClock(time.Date(2018, time.March, 1, 12, 0, 0, 0, time.UTC))
+	return service.NewMessageManagerWith(manager, service.NewMemoryMessageRepository(), tickingClock{clock}), tokens
+}
+
+//tickingClock is a FixedClock that advances a minute after telling the time
+type tickingClock struct {
+	*domain.FixedClock
+}
+
+func (c tickingClock) Now() time.Time {
+	now := c.FixedClock.Now()
+	c.Advance(time.Minute)
+	return now
+}
+
+//sendMessage sends a message, failing the test if it can't
+func sendMessage(t *testing.T, messages *service.MessageManager, token string, text string, names ...string) *domain.Message {
+	message, err := messages.SendMessage(token, names, text)
+	if err != nil {
+		t.Fatalf("Unexpected error, %s", err.Error())
+	}
+	return message
+}
+
+func TestMessagesToTheSameUsersShareConversation(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	messages, tokens := newMessageManager(&manager)
+
+	//Operation
+	first := sendMessage(t, messages, tokens[0], "hola", "gonza")
+	second := sendMessage(t, messages, tokens[1], "hola manu", "manu")
+	group := sendMessage(t, messages, tokens[0], "hola a todos", "gonza", "root")
+
+	//Validation
+	if first.ConversationID != second.ConversationID {
+		t.Errorf("Expected one conversation but got %d and %d", first.ConversationID, second.ConversationID)
+	}
+	if group.ConversationID == first.ConversationID {
+		t.Error("The group should have its own conversation")
+	}
+}
+
+func TestInboxIsOrderedByLatestMessage(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	messages, tokens := newMessageManager(&manager)
+	withGonza := sendMessage(t, messages, tokens[0], "hola", "gonza")
+	withRoot := sendMessage(t, messages, tokens[0], "hola", "root")
+	sendMessage(t, messages, tokens[1], "que tal?", "manu")
+
+	//Operation
+	inbox, err := messages.GetInbox(tokens[0])
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if len(inbox) != 2 || inbox[0].ID != withGonza.ConversationID || inbox[1].ID != withRoot.ConversationID {
+		t.Errorf("Unexpected inbox %v", inbox)
+		return
+	}
+	expectUsers(t, inbox[0].Members, "gonza")
+	if inbox[0].LastMessage.Text != "que tal?" || inbox[0].Unread != 1 || inbox[1].Unread != 0 {
+		t.Errorf("Unexpected inbox %v", inbox)
+	}
+}
+
+func TestMarkingAsReadClearsUnreadCount(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	messages, tokens := newMessageManager(&manager)
+	message := sendMessage(t, messages, tokens[0], "hola", "gonza")
+	sendMessage(t, messages, tokens[2], "hola", "gonza")
+	sendMessage(t, messages, tokens[0], "estás?", "gonza")
+
+	//Operation
+	before, _ := messages.GetUnreadCount(tokens[1])
+	err := messages.MarkAsRead(tokens[1], message.ConversationID)
+	after, _ := messages.GetUnreadCount(tokens[1])
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if before != 3 || after != 1 {
+		t.Errorf("Expected 3 unread messages and then 1 but got %d and %d", before, after)
+	}
+}
+
+func TestConversationIsReadInPages(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	messages, tokens := newMessageManager(&manager)
+	var sent []*domain.Message
+	for i := 0; i < 5; i++ {
+		sent = append(sent, sendMessage(t, messages, tokens[i%2], fmt.Sprintf("message %d", i), []string{"gonza", "manu"}[i%2]))
+	}
+	id := sent[0].ConversationID
+
+	//Operation
+	newest, err := messages.ReadConversation(tokens[0], id, service.FirstPage(2))
+	older, _ := messages.ReadConversation(tokens[0], id, newest.Older(2))
+	oldest, _ := messages.ReadConversation(tokens[0], id, older.Older(2))
+	newer, _ := messages.ReadConversation(tokens[0], id, oldest.Newer(2))
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	expectMessages(t, newest, sent[3], sent[4])
+	expectMessages(t, older, sent[1], sent[2])
+	expectMessages(t, oldest, sent[0])
+	expectMessages(t, newer, sent[1], sent[2])
+	if !newest.HasOlder || newest.HasNewer || oldest.HasOlder || !oldest.HasNewer {
+		t.Error("Unexpected pages")
+	}
+}
+
+//expectMessages fails the test if the page doesn't have the expected messages, in order
+func expectMessages(t *testing.T, page service.MessagePage, expected ...*domain.Message) {
+	if len(page.Messages) != len(expected) {
+		t.Errorf("Expected %d messages but got %v", len(expected), page.Messages)
+		return
+	}
+	for i, message := range expected {
+		if page.Messages[i].ID != message.ID {
+			t.Errorf("Expected %v but got %v", expected, page.Messages)
+			return
+		}
+	}
+}
+
+func TestOnlyMembersCanReadConversation(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	messages, tokens := newMessageManager(&manager)
+	message := sendMessage(t, messages, tokens[0], "secreto", "gonza")
+
+	//Operation
+	_, err := messages.ReadConversation(tokens[2], message.ConversationID, service.FirstPage(10))
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't read conversation, You are not part of that conversation")
+}
+
+func TestCantMessageBlockedUsers(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	messages, tokens := newMessageManager(&manager)
+	manager.BlockUser(tokens[1], "manu")
+
+	//Operation
+	_, err := messages.SendMessage(tokens[0], []string{"gonza"}, "hola")
+	_, groupErr := messages.SendMessage(tokens[0], []string{"root", "gonza"}, "hola")
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't send message, You can't interact with that user")
+	utility.ValidateExpectedError(t, groupErr, "Couldn't send message, You can't interact with that user")
+}
+
+func TestCantMessageYourselfNorNobody(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	messages, tokens := newMessageManager(&manager)
+
+	//Operation
+	_, yourselfErr := messages.SendMessage(tokens[0], []string{"manu"}, "hola")
+	_, nobodyErr := messages.SendMessage(tokens[0], nil, "hola")
+	_, loggedOutErr := messages.SendMessage("", []string{"gonza"}, "hola")
+
+	//Validation
+	utility.ValidateExpectedError(t, yourselfErr, "Couldn't send message, Can't send a message to yourself")
+	utility.ValidateExpectedError(t, nobodyErr, "Couldn't send message, A message needs at least one recipient")
+	if loggedOutErr == nil {
+		t.Error("Logged out users should not send messages")
+	}
+}
@@ -0,0 +1,43 @@
+package service
+
+import "github.com/cursoGo/src/domain"
+
+//NotificationRepository is where the TweetManager stores the notifications of each user, by user ID
+type NotificationRepository interface {
+	AddNotification(domain.Notification) (int, error)
+	GetNotifications(user int) []domain.Notification
+	MarkNotificationsRead(user int) error
+}
+
+//MemoryNotificationRepository is a NotificationRepository that keeps everything in memory.
+//It is the only one there is, so notifications are lost on restart even when tweets are saved in a file or event log
+type MemoryNotificationRepository struct {
+	notifications map[int][]domain.Notification
+	lastID        int
+}
+
+//NewMemoryNotificationRepository returns a new empty MemoryNotificationRepository
+func NewMemoryNotificationRepository() *MemoryNotificationRepository {
+	return &MemoryNotificationRepository{notifications: make(map[int][]domain.Notification), lastID: -1}
+}
+
+//AddNotification stores a notification for its recipient and returns the ID given to it
+func (r *MemoryNotificationRepository) AddNotification(notification domain.Notification) (int, error) {
+	r.lastID++
+	notification.ID = r.lastID
+	r.notifications[notification.Recipient] = append(r.notifications[notification.Recipient], notification)
+	return notification.ID, nil
+}
+
+//GetNotifications returns the notifications of a user, in the order they were added
+func (r *MemoryNotificationRepository) GetNotifications(user int) []domain.Notification {
+	return append([]domain.Notification(nil), r.notifications[user]...)
+}
+
+//MarkNotificationsRead marks every notification of a user as read
+func (r *MemoryNotificationRepository) MarkNotificationsRead(user int) error {
+	for i := range r.notifications[user] {
+		r.notifications[user][i].Read = true
+	}
+	return nil
+}
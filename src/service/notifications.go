@@ -0,0 +1,200 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cursoGo/src/domain"
+)
+
+//NotificationGroup is a group of similar notifications, such as the likes of the same tweet.
+//Only likes, retweets and follows are grouped, and only with others that have the same read state
+type NotificationGroup struct {
+	Kind    domain.NotificationKind
+	TweetID int
+	//Actors are the users that did it, the latest one first and each of them once
+	Actors []domain.User
+	Date   time.Time
+	Unread bool
+}
+
+//String returns a formatted string of the NotificationGroup, like "@manu and 3 others liked your tweet 7"
+func (g NotificationGroup) String() string {
+	marker := ""
+	if g.Unread {
+		marker = "(new) "
+	}
+	return fmt.Sprintf("%s%s %s", marker, g.actors(), g.action())
+}
+
+func (g NotificationGroup) actors() string {
+	switch len(g.Actors) {
+	case 1:
+		return "@" + g.Actors[0].Name
+	case 2:
+		return fmt.Sprintf("@%s and @%s", g.Actors[0].Name, g.Actors[1].Name)
+	}
+	return fmt.Sprintf("@%s and %d others", g.Actors[0].Name, len(g.Actors)-1)
+}
+
+func (g NotificationGroup) action() string {
+	switch g.Kind {
+	case domain.FollowNotification:
+		return "followed you"
+	case domain.MentionNotification:
+		return fmt.Sprintf("mentioned you in tweet %d", g.TweetID)
+	case domain.ReplyNotification:
+		return fmt.Sprintf("replied to you in tweet %d", g.TweetID)
+	case domain.QuoteNotification:
+		return fmt.Sprintf("quoted you in tweet %d", g.TweetID)
+	case domain.LikeNotification:
+		return fmt.Sprintf("liked your tweet %d", g.TweetID)
+	case domain.RetweetNotification:
+		return fmt.Sprintf("retweeted your tweet %d", g.TweetID)
+	case domain.ApproveNotification:
+		return "approved your follow request"
+	}
+	return string(g.Kind)
+}
+
+//groupable returns if notifications of that kind are grouped together
+func groupable(kind domain.NotificationKind) bool {
+	return kind == domain.FollowNotification || kind == domain.LikeNotification || kind == domain.RetweetNotification
+}
+
+//groupKey identifies the group that a notification belongs to
+type groupKey struct {
+	kind    domain.NotificationKind
+	tweetID int
+	read    bool
+}
+
+//groupNotifications groups notifications, which are oldest first, into the groups that the user sees, the latest one first
+func groupNotifications(notifications []domain.Notification) []NotificationGroup {
+	var groups []NotificationGroup
+	index := make(map[groupKey]int)
+	for i := len(notifications) - 1; i >= 0; i-- {
+		notification := notifications[i]
+		key := groupKey{notification.Kind, notification.TweetID, notification.Read}
+		position, ok := index[key]
+		if !ok || !groupable(notification.Kind) {
+			index[key] = len(groups)
+			groups = append(groups, NotificationGroup{
+				Kind:    notification.Kind,
+				TweetID: notification.TweetID,
+				Actors:  []domain.User{notification.Actor},
+				Date:    notification.Date,
+				Unread:  !notification.Read,
+			})
+			continue
+		}
+		if !containsUser(groups[position].Actors, notification.Actor) {
+			groups[position].Actors = append(groups[position].Actors, notification.Actor)
+		}
+	}
+	return groups
+}
+
+//containsUser returns if a user is in a list
+func containsUser(users []domain.User, user domain.User) bool {
+	for _, other := range users {
+		if other.Equals(user) {
+			return true
+		}
+	}
+	return false
+}
+
+//GetNotifications returns the notifications of the user logged in with a session, grouped and the latest first
+func (m *TweetManager) GetNotifications(token string) ([]NotificationGroup, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	user, err := m.loggedInUser(token)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't retrieve notifications, %w", err)
+	}
+	return groupNotifications(m.notifications.GetNotifications(user.ID)), nil
+}
+
+//GetUnreadNotificationCount returns how many notifications the user logged in with a session didn't read
+func (m *TweetManager) GetUnreadNotificationCount(token string) (int, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	user, err := m.loggedInUser(token)
+	if err != nil {
+		return 0, fmt.Errorf("Couldn't retrieve notifications, %w", err)
+	}
+	unread := 0
+	for _, notification := range m.notifications.GetNotifications(user.ID) {
+		if !notification.Read {
+			unread++
+		}
+	}
+	return unread, nil
+}
+
+//MarkNotificationsAsRead marks every notification of the user logged in with a session as read
+func (m *TweetManager) MarkNotificationsAsRead(token string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	user, err := m.loggedInUser(token)
+	if err != nil {
+		return fmt.Errorf("Couldn't mark notifications as read, %w", err)
+	}
+	return m.notifications.MarkNotificationsRead(user.ID)
+}
+
+//notify tells recipient that actor did something that involves them, unless it was to themselves or recipient muted actor.
+//Notifications are a side effect of what actor did, so they are given on a best-effort basis and never make it fail
+func (m *TweetManager) notify(kind domain.NotificationKind, actor domain.User, recipient domain.User, tweetID int) {
+	from, err := m.repository.GetUserByName(actor.Name)
+	if err != nil {
+		return
+	}
+	to, err := m.repository.GetUserByName(recipient.Name)
+	if err != nil || from.ID == to.ID || containsID(m.repository.GetMuted(to.ID), from.ID) {
+		return
+	}
+	m.notifications.AddNotification(domain.NewNotification(kind, to.ID, *from, tweetID, m.clock.Now()))
+}
+
+//notifyPublished tells the users involved in a tweet that was just published about it:
+//the authors of the tweets it replies to, quotes or retweets, and the users it mentions
+func (m *TweetManager) notifyPublished(tweet domain.Tweeter) {
+	user := tweet.GetUser()
+	notified := []string{user.Name}
+	if retweet, ok := tweet.(*domain.Retweet); ok {
+		original := domain.GetOriginal(retweet.GetRetweeted())
+		m.notify(domain.RetweetNotification, user, original.GetUser(), original.GetID())
+		return
+	}
+	if parentID, ok := domain.GetParentID(tweet); ok {
+		if parent, err := m.repository.GetTweetByID(parentID); err == nil {
+			m.notify(domain.ReplyNotification, user, parent.GetUser(), tweet.GetID())
+			notified = append(notified, parent.GetUser().Name)
+		}
+	}
+	if quote, ok := tweet.(*domain.QuoteTweet); ok && quote.GetQuotedTweet() != nil {
+		quoted := quote.GetQuotedTweet().GetUser()
+		if !containsName(notified, quoted.Name) {
+			m.notify(domain.QuoteNotification, user, quoted, tweet.GetID())
+			notified = append(notified, quoted.Name)
+		}
+	}
+	for _, name := range domain.ExtractMentions(tweet.GetText()) {
+		if !containsName(notified, name) {
+			m.notify(domain.MentionNotification, user, domain.NewUser(name, ""), tweet.GetID())
+			notified = append(notified, name)
+		}
+	}
+}
+
+//containsName returns if a name is in a list
+func containsName(names []string, name string) bool {
+	for _, other := range names {
+		if other == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package service_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/service"
+)
+
+//expectNotifications fails the test if the notifications, as strings, aren't the expected ones, in order
+func expectNotifications(t *testing.T, got []service.NotificationGroup, expected ...string) {
+	if len(got) != len(expected) {
+		t.Errorf("Expected %v but got %v", expected, got)
+		return
+	}
+	for i, text := range expected {
+		if got[i].String() != text {
+			t.Errorf("Expected %v but got %v", expected, got)
+			return
+		}
+	}
+}
+
+func TestFollowingNotifiesFollowedUser(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+
+	//Operation
+	manager.FollowUser(tokens[0], "gonza")
+	manager.FollowUser(tokens[2], "gonza")
+
+	//Validation
+	notifications, err := manager.GetNotifications(tokens[1])
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	expectNotifications(t, notifications, "(new) @root and @manu followed you")
+	if own, _ := manager.GetNotifications(tokens[0]); len(own) != 0 {
+		t.Errorf("Expected no notifications but got %v", own)
+	}
+}
+
+func TestTweetsNotifyRepliedQuotedAndMentionedUsers(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	tweet := publishText(t, &manager, tokens[0], "manu", "hola")
+
+	//Operation
+	reply, _ := manager.ReplyToTweet(tokens[1], tweet.GetID(), "hola @manu y @root")
+	quote, _ := manager.QuoteTweet(tokens[2], tweet.GetID(), "mirá")
+	retweet, _ := manager.Retweet(tokens[1], tweet.GetID())
+
+	//Validation
+	notifications, _ := manager.GetNotifications(tokens[0])
+	if len(notifications) != 3 || retweet == nil {
+		t.Errorf("Unexpected notifications %v", notifications)
+		return
+	}
+	expected := []service.NotificationGroup{
+		{Kind: domain.RetweetNotification, TweetID: tweet.GetID()},
+		{Kind: domain.QuoteNotification, TweetID: quote.GetID()},
+		{Kind: domain.ReplyNotification, TweetID: reply.GetID()},
+	}
+	for i, group := range expected {
+		if notifications[i].Kind != group.Kind || notifications[i].TweetID != group.TweetID {
+			t.Errorf("Expected %+v but got %+v", group, notifications[i])
+		}
+	}
+	mentions, _ := manager.GetNotifications(tokens[2])
+	if len(mentions) != 1 || mentions[0].Kind != domain.MentionNotification || mentions[0].TweetID != reply.GetID() {
+		t.Errorf("Unexpected notifications %v", mentions)
+	}
+}
+
+func TestLikesOfTheSameTweetAreGrouped(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	for _, name := range []string{"ana", "beto", "carla"} {
+		manager.Register(domain.NewUser(name, "secret"))
+		token, _ := manager.Login(domain.NewUser(name, "secret"))
+		tokens = append(tokens, token)
+	}
+	liked := publishText(t, &manager, tokens[0], "manu", "likeame")
+	other := publishText(t, &manager, tokens[0], "manu", "a mí no")
+
+	//Operation
+	for _, token := range tokens[1:] {
+		manager.LikeTweet(token, liked.GetID())
+	}
+	manager.LikeTweet(tokens[1], other.GetID())
+
+	//Validation
+	notifications, _ := manager.GetNotifications(tokens[0])
+	expectNotifications(t, notifications,
+		"(new) @gonza liked your tweet "+strconv.Itoa(other.GetID()),
+		"(new) @carla and 4 others liked your tweet "+strconv.Itoa(liked.GetID()))
+}
+
+func TestReadNotificationsAreNotGroupedWithNewOnes(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	tweet := publishText(t, &manager, tokens[0], "manu", "hola")
+	manager.LikeTweet(tokens[1], tweet.GetID())
+
+	//Operation
+	before, _ := manager.GetUnreadNotificationCount(tokens[0])
+	err := manager.MarkNotificationsAsRead(tokens[0])
+	manager.LikeTweet(tokens[2], tweet.GetID())
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if after, _ := manager.GetUnreadNotificationCount(tokens[0]); before != 1 || after != 1 {
+		t.Errorf("Expected 1 unread notification before and after but got %d and %d", before, after)
+	}
+	notifications, _ := manager.GetNotifications(tokens[0])
+	expectNotifications(t, notifications,
+		"(new) @root liked your tweet "+strconv.Itoa(tweet.GetID()),
+		"@gonza liked your tweet "+strconv.Itoa(tweet.GetID()))
+}
+
+func TestMutedUsersDontNotify(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	tweet := publishText(t, &manager, tokens[0], "manu", "hola")
+	manager.MuteUser(tokens[0], "gonza")
+
+	//Operation
+	manager.LikeTweet(tokens[1], tweet.GetID())
+	manager.FollowUser(tokens[1], "manu")
+
+	//Validation
+	if count, _ := manager.GetUnreadNotificationCount(tokens[0]); count != 0 {
+		t.Errorf("Expected no notifications but got %d", count)
+	}
+}
+
+func TestFollowRequestsDontNotify(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens, _ := newProtectedManager(t, &manager)
+
+	//Operation
+	manager.FollowUser(tokens[0], "gonza")
+
+	//Validation
+	if count, _ := manager.GetUnreadNotificationCount(tokens[1]); count != 0 {
+		t.Errorf("Expected no notifications but got %d", count)
+	}
+}
+
+func TestApprovedFollowRequestsNotify(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens, _ := newProtectedManager(t, &manager)
+	manager.FollowUser(tokens[0], "gonza")
+
+	//Operation
+	err := manager.ApproveFollowRequest(tokens[1], "manu")
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	owner, _ := manager.GetNotifications(tokens[1])
+	expectNotifications(t, owner, "(new) @manu followed you")
+	requester, _ := manager.GetNotifications(tokens[0])
+	expectNotifications(t, requester, "(new) @gonza approved your follow request")
+}
@@ -0,0 +1,296 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/service"
+	"github.com/cursoGo/src/utility"
+)
+
+//newProtectedManager returns the tokens of newSocialManager, with gonza protected and a tweet of them
+func newProtectedManager(t *testing.T, manager *service.TweetManager) ([]string, domain.Tweeter) {
+	tokens := newSocialManager(manager)
+	tweet := publishText(t, manager, tokens[1], "gonza", "only for friends")
+	if err := manager.SetProtected(tokens[1], true); err != nil {
+		t.Fatalf("Unexpected error, %s", err.Error())
+	}
+	return tokens, tweet
+}
+
+func TestFollowingProtectedUserCreatesRequest(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens, _ := newProtectedManager(t, &manager)
+
+	//Operation
+	err := manager.FollowUser(tokens[0], "gonza")
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	following, _ := manager.GetFollowing(domain.NewUser("manu", ""))
+	expectUsers(t, following)
+	requests, _ := manager.GetFollowRequests(tokens[1])
+	expectUsers(t, requests, "manu")
+}
+
+func TestCantRequestToFollowTwice(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens, _ := newProtectedManager(t, &manager)
+	manager.FollowUser(tokens[0], "gonza")
+
+	//Operation
+	err := manager.FollowUser(tokens[0], "gonza")
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't follow user, You already asked to follow that user")
+}
+
+func TestTweetsOfProtectedUserAreHidden(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens, tweet := newProtectedManager(t, &manager)
+	manager.FollowUser(tokens[0], "gonza")
+
+	//Operation
+	_, byIDErr := manager.GetVisibleTweetByID(tokens[0], tweet.GetID())
+	_, fromUserErr := manager.GetVisibleTweetsFromUser(tokens[0], domain.NewUser("gonza", ""))
+	_, anonymousErr := manager.GetTweetByID(tweet.GetID())
+	_, replyErr := manager.ReplyToTweet(tokens[0], tweet.GetID(), "let me in")
+
+	//Validation
+	utility.ValidateExpectedError(t, byIDErr, "A tweet with that ID does not exist")
+	utility.ValidateExpectedError(t, fromUserErr, "That account is protected")
+	utility.ValidateExpectedError(t, anonymousErr, "A tweet with that ID does not exist")
+	utility.ValidateExpectedError(t, replyErr, "Couldn't reply, A tweet with that ID does not exist")
+	if timeline, _ := manager.GetTimeline(tokens[0]); len(timeline) != 0 {
+		t.Errorf("Expected an empty timeline but got %d tweets", len(timeline))
+	}
+}
+
+func TestLikesAndRetweetsOfProtectedTweetsAreHidden(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens, tweet := newProtectedManager(t, &manager)
+	manager.LikeTweet(tokens[1], tweet.GetID())
+
+	//Operation
+	_, anonymousErr := manager.GetLikes("", tweet.GetID())
+	_, countErr := manager.GetRetweetCount(tokens[0], tweet.GetID())
+	likes, ownErr := manager.GetLikes(tokens[1], tweet.GetID())
+
+	//Validation
+	utility.ValidateExpectedError(t, anonymousErr, "A tweet with that ID does not exist")
+	utility.ValidateExpectedError(t, countErr, "A tweet with that ID does not exist")
+	if ownErr != nil {
+		t.Errorf("Unexpected error, %s", ownErr.Error())
+		return
+	}
+	expectUsers(t, likes, "gonza")
+}
+
+func TestBookmarksOfProtectedTweetsAreHidden(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	tweet := publishText(t, &manager, tokens[1], "gonza", "only for friends")
+	manager.BookmarkTweet(tokens[0], tweet.GetID())
+
+	//Operation
+	manager.SetProtected(tokens[1], true)
+
+	//Validation
+	if bookmarks, _ := manager.GetBookmarks(tokens[0]); len(bookmarks) != 0 {
+		t.Errorf("Expected no bookmarks but got %d", len(bookmarks))
+	}
+	_, err := manager.GetTweet()
+	utility.ValidateExpectedError(t, err, "A tweet with that ID does not exist")
+}
+
+func TestApprovedFollowerSeesProtectedTweets(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens, tweet := newProtectedManager(t, &manager)
+	manager.FollowUser(tokens[0], "gonza")
+
+	//Operation
+	err := manager.ApproveFollowRequest(tokens[1], "manu")
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if _, err := manager.GetVisibleTweetByID(tokens[0], tweet.GetID()); err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+	}
+	timeline, _ := manager.GetTimeline(tokens[0])
+	expectTweets(t, timeline, []domain.Tweeter{tweet})
+	if _, err := manager.GetVisibleTweetByID(tokens[2], tweet.GetID()); err == nil {
+		t.Error("Only approved followers should see the tweet")
+	}
+	if requests, _ := manager.GetFollowRequests(tokens[1]); len(requests) != 0 {
+		t.Errorf("Expected no requests but got %v", requests)
+	}
+}
+
+func TestRejectedUserDoesNotFollow(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens, _ := newProtectedManager(t, &manager)
+	manager.FollowUser(tokens[0], "gonza")
+
+	//Operation
+	err := manager.RejectFollowRequest(tokens[1], "manu")
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	followers, _ := manager.GetFollowers(domain.NewUser("gonza", ""))
+	expectUsers(t, followers)
+	err = manager.ApproveFollowRequest(tokens[1], "manu")
+	utility.ValidateExpectedError(t, err, "Couldn't approve user, That user didn't ask to follow you")
+}
+
+func TestProtectedUserSeesOwnTweets(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens, tweet := newProtectedManager(t, &manager)
+
+	//Operation
+	tweets, err := manager.GetVisibleTweetsFromUser(tokens[1], domain.NewUser("gonza", ""))
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	expectTweets(t, tweets, []domain.Tweeter{tweet})
+}
+
+func TestRetweetsOfProtectedTweetsAreHidden(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens, tweet := newProtectedManager(t, &manager)
+	manager.FollowUser(tokens[2], "gonza")
+	manager.ApproveFollowRequest(tokens[1], "root")
+	manager.Retweet(tokens[2], tweet.GetID())
+	manager.FollowUser(tokens[0], "root")
+
+	//Operation
+	timeline, _ := manager.GetTimeline(tokens[0])
+
+	//Validation
+	if len(timeline) != 0 {
+		t.Errorf("Expected an empty timeline but got %d tweets", len(timeline))
+	}
+}
+
+func TestMakingAccountPublicApprovesRequests(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens, tweet := newProtectedManager(t, &manager)
+	manager.FollowUser(tokens[0], "gonza")
+
+	//Operation
+	err := manager.SetProtected(tokens[1], false)
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	followers, _ := manager.GetFollowers(domain.NewUser("gonza", ""))
+	expectUsers(t, followers, "manu")
+	if _, err := manager.GetTweetByID(tweet.GetID()); err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+	}
+}
+
+func TestUnfollowingCancelsFollowRequest(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens, _ := newProtectedManager(t, &manager)
+	manager.FollowUser(tokens[0], "gonza")
+
+	//Operation
+	err := manager.UnfollowUser(tokens[0], "gonza")
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if requests, _ := manager.GetFollowRequests(tokens[1]); len(requests) != 0 {
+		t.Errorf("Expected no requests but got %v", requests)
+	}
+}
+
+func TestProtectionAndRequestsSurviveRestart(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+	var manager service.TweetManager
+	manager.InitializeManagerWithRepository(service.NewFileTweetRepository(path))
+	tokens, tweet := newProtectedManager(t, &manager)
+	manager.FollowUser(tokens[0], "gonza")
+
+	//Operation
+	var restarted service.TweetManager
+	restarted.InitializeManagerWithRepository(service.NewFileTweetRepository(path))
+	token, _ := restarted.Login(domain.NewUser("gonza", "hunter3"))
+
+	//Validation
+	requests, _ := restarted.GetFollowRequests(token)
+	expectUsers(t, requests, "manu")
+	if _, err := restarted.GetTweetByID(tweet.GetID()); err == nil {
+		t.Error("The account should still be protected")
+	}
+}
+
+func TestProtectionIsReplayedFromEventLog(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+	var manager service.TweetManager
+	repository := service.NewEventLogRepository(path, 0)
+	manager.InitializeManagerWithRepository(repository)
+	tokens, tweet := newProtectedManager(t, &manager)
+	manager.FollowUser(tokens[0], "gonza")
+	manager.FollowUser(tokens[2], "gonza")
+	manager.ApproveFollowRequest(tokens[1], "manu")
+	repository.Close()
+
+	//Operation
+	var restarted service.TweetManager
+	restartedRepository := service.NewEventLogRepository(path, 0)
+	restarted.InitializeManagerWithRepository(restartedRepository)
+	defer restartedRepository.Close()
+	token, _ := restarted.Login(domain.NewUser("gonza", "hunter3"))
+
+	//Validation
+	requests, _ := restarted.GetFollowRequests(token)
+	expectUsers(t, requests, "root")
+	followers, _ := restarted.GetFollowers(domain.NewUser("gonza", ""))
+	expectUsers(t, followers, "manu")
+	if _, err := restarted.GetTweetByID(tweet.GetID()); err == nil {
+		t.Error("The account should still be protected")
+	}
+}
@@ -0,0 +1,134 @@
+package service_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/service"
+	"github.com/cursoGo/src/utility"
+)
+
+func TestCanQuoteTweetByID(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	user := domain.NewUser("root", "root")
+	manager.Register(user)
+	token, _ := manager.Login(user)
+	quoted, _ := manager.NewTextTweet(user, "quote me")
+	manager.PublishTweet(token, quoted)
+
+	//Operation
+	quote, err := manager.QuoteTweet(token, quoted.GetID(), "nice")
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if quote.GetQuotedTweet() != quoted || !manager.TweetExists(quote) {
+		t.Errorf("Unexpected quote %s", quote)
+	}
+}
+
+func TestCantQuoteNonExistentTweet(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	user := domain.NewUser("root", "root")
+	manager.Register(user)
+	token, _ := manager.Login(user)
+
+	//Operation
+	_, err := manager.QuoteTweet(token, 42, "nice")
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't quote, A tweet with that ID does not exist")
+}
+
+func TestCantQuoteWithoutLoggingIn(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+
+	//Operation
+	_, err := manager.QuoteTweet("", 0, "nice")
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't quote, You must be logged in to tweet")
+}
+
+func TestCantPublishQuoteOfUnpublishedTweet(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	user := domain.NewUser("root", "root")
+	manager.Register(user)
+	token, _ := manager.Login(user)
+	quoted, _ := manager.NewTextTweet(user, "never published")
+	quote, _ := manager.NewQuoteTweet(user, "nice", quoted)
+
+	//Operation
+	err := manager.PublishTweet(token, quote)
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't quote, A tweet with that ID does not exist")
+}
+
+func TestQuotesShowEditsOfQuotedTweet(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	user := domain.NewUser("root", "root")
+	manager.Register(user)
+	token, _ := manager.Login(user)
+	quoted, _ := manager.NewTextTweet(user, "quote me")
+	manager.PublishTweet(token, quoted)
+	quote, _ := manager.QuoteTweet(token, quoted.GetID(), "nice")
+
+	//Operation
+	manager.EditTweetTextByID(token, quoted.GetID(), "edited")
+
+	//Validation
+	if !strings.Contains(quote.String(), "edited") {
+		t.Errorf("Expected the quote to show the edit, got %s", quote)
+	}
+}
+
+func TestQuotesOfDeletedTweetSurviveRestart(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+	var manager service.TweetManager
+	manager.InitializeManagerWithRepository(service.NewFileTweetRepository(path))
+	user := domain.NewUser("root", "root")
+	manager.Register(user)
+	token, _ := manager.Login(user)
+	quoted, _ := manager.NewTextTweet(user, "quote me")
+	manager.PublishTweet(token, quoted)
+	quote, _ := manager.QuoteTweet(token, quoted.GetID(), "nice")
+
+	//Operation
+	manager.DeleteTweetByID(token, quoted.GetID())
+	var restarted service.TweetManager
+	err := restarted.InitializeManagerWithRepository(service.NewFileTweetRepository(path))
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if quote.GetQuotedTweet() != nil {
+		t.Error("The quote should forget the deleted tweet")
+	}
+	restored, _ := restarted.GetTweetByID(quote.GetID())
+	restoredQuote, ok := restored.(*domain.QuoteTweet)
+	if !ok || restoredQuote.GetQuotedTweet() != nil || restoredQuote.GetQuotedID() != quoted.GetID() {
+		t.Errorf("Unexpected restored quote %s", restored)
+		return
+	}
+	if !strings.Contains(restoredQuote.String(), "This tweet was deleted") {
+		t.Errorf("Expected the quote to show the tweet was deleted, got %s", restoredQuote)
+	}
+}
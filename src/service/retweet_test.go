@@ -0,0 +1,201 @@
+package service_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/service"
+	"github.com/cursoGo/src/utility"
+)
+
+//newRetweetManager returns a manager where manu follows gonza and root has published a tweet.
+//It returns the tokens of manu, gonza and root and the tweet of root
+func newRetweetManager(t *testing.T, manager *service.TweetManager) (string, string, string, domain.Tweeter) {
+	manu := domain.NewUser("manu", "hunter2")
+	gonza := domain.NewUser("gonza", "hunter3")
+	root := domain.NewUser("root", "root")
+	manager.Register(manu)
+	manager.Register(gonza)
+	manager.Register(root)
+	manuToken, _ := manager.Login(manu)
+	gonzaToken, _ := manager.Login(gonza)
+	rootToken, _ := manager.Login(root)
+	manager.FollowUser(manuToken, gonza.Name)
+
+	tweet, _ := manager.NewTextTweet(root, "share me")
+	if err := manager.PublishTweet(rootToken, tweet); err != nil {
+		t.Fatalf("Unexpected error, %s", err.Error())
+	}
+	return manuToken, gonzaToken, rootToken, tweet
+}
+
+func TestRetweetsShowUpInFollowersTimeline(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	manuToken, gonzaToken, _, tweet := newRetweetManager(t, &manager)
+
+	//Operation
+	retweet, err := manager.Retweet(gonzaToken, tweet.GetID())
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	timeline, _ := manager.GetTimeline(manuToken)
+	expectTweets(t, timeline, []domain.Tweeter{retweet})
+	if !strings.HasPrefix(timeline[0].String(), "@gonza retweeted\n") || !strings.Contains(timeline[0].String(), "share me") {
+		t.Errorf("Unexpected retweet %s", timeline[0])
+	}
+}
+
+func TestCanCountRetweets(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	manuToken, gonzaToken, _, tweet := newRetweetManager(t, &manager)
+
+	//Operation
+	manager.Retweet(gonzaToken, tweet.GetID())
+	retweet, _ := manager.Retweet(manuToken, tweet.GetID())
+	count, err := manager.GetRetweetCount(manuToken, tweet.GetID())
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 retweets but got %d", count)
+	}
+	if count, _ := manager.GetRetweetCount(manuToken, retweet.GetID()); count != 2 {
+		t.Errorf("A retweet should count the retweets of its tweet, got %d", count)
+	}
+}
+
+func TestCanUndoRetweet(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	manuToken, gonzaToken, _, tweet := newRetweetManager(t, &manager)
+	retweet, _ := manager.Retweet(gonzaToken, tweet.GetID())
+
+	//Operation
+	err := manager.Unretweet(gonzaToken, tweet.GetID())
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if manager.TweetExists(retweet) {
+		t.Error("The retweet was not undone")
+	}
+	if timeline, _ := manager.GetTimeline(manuToken); len(timeline) != 0 {
+		t.Errorf("Expected an empty timeline but got %d tweets", len(timeline))
+	}
+	if count, _ := manager.GetRetweetCount(manuToken, tweet.GetID()); count != 0 {
+		t.Errorf("Expected no retweets but got %d", count)
+	}
+}
+
+func TestCantUndoRetweetThatWasNotMade(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	_, gonzaToken, _, tweet := newRetweetManager(t, &manager)
+
+	//Operation
+	err := manager.Unretweet(gonzaToken, tweet.GetID())
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't undo retweet, You haven't retweeted that tweet")
+}
+
+func TestCantRetweetTwice(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	_, gonzaToken, _, tweet := newRetweetManager(t, &manager)
+	retweet, _ := manager.Retweet(gonzaToken, tweet.GetID())
+
+	//Operation
+	_, err := manager.Retweet(gonzaToken, retweet.GetID())
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't retweet, You already retweeted that tweet")
+}
+
+func TestCantRetweetOwnTweet(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	_, _, rootToken, tweet := newRetweetManager(t, &manager)
+
+	//Operation
+	_, err := manager.Retweet(rootToken, tweet.GetID())
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't retweet, You can't retweet your own tweet")
+}
+
+func TestCantRetweetWithoutLoggingIn(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	_, _, _, tweet := newRetweetManager(t, &manager)
+
+	//Operation
+	_, err := manager.Retweet("", tweet.GetID())
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't retweet, You must be logged in to tweet")
+}
+
+func TestDeletingTweetRemovesItsRetweets(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	manuToken, gonzaToken, rootToken, tweet := newRetweetManager(t, &manager)
+	retweet, _ := manager.Retweet(gonzaToken, tweet.GetID())
+
+	//Operation
+	manager.DeleteTweetByID(rootToken, tweet.GetID())
+
+	//Validation
+	if manager.TweetExists(retweet) {
+		t.Error("The retweet of a deleted tweet should be removed")
+	}
+	if timeline, _ := manager.GetTimeline(manuToken); len(timeline) != 0 {
+		t.Errorf("Expected an empty timeline but got %d tweets", len(timeline))
+	}
+}
+
+func TestRetweetsSurviveRestart(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+	var manager service.TweetManager
+	manager.InitializeManagerWithRepository(service.NewFileTweetRepository(path))
+	_, gonzaToken, _, tweet := newRetweetManager(t, &manager)
+	retweet, _ := manager.Retweet(gonzaToken, tweet.GetID())
+
+	//Operation
+	var restarted service.TweetManager
+	restarted.InitializeManagerWithRepository(service.NewFileTweetRepository(path))
+	restored, err := restarted.GetTweetByID(retweet.GetID())
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if !restored.Equals(retweet) || restored.String() != retweet.String() {
+		t.Errorf("Expected %s but got %s", retweet, restored)
+	}
+	if count, _ := restarted.GetRetweetCount("", tweet.GetID()); count != 1 {
+		t.Errorf("Expected 1 retweet but got %d", count)
+	}
+}
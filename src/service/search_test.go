@@ -0,0 +1,64 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/service"
+	"github.com/cursoGo/src/utility"
+)
+
+func TestCanSearchPublishedTweets(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	_, gonzaToken, _, tweet := newRetweetManager(t, &manager)
+	other, _ := manager.NewTextTweet(domain.NewUser("gonza", ""), "no me compartas")
+	manager.PublishTweet(gonzaToken, other)
+	manager.Retweet(gonzaToken, tweet.GetID())
+
+	//Operation
+	tweets, err := manager.Search("share OR compartas")
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	expectTweets(t, tweets, []domain.Tweeter{tweet, other})
+}
+
+func TestSearchFollowsEditsAndDeletes(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	_, _, rootToken, tweet := newRetweetManager(t, &manager)
+	other, _ := manager.NewTextTweet(domain.NewUser("root", ""), "borrame")
+	manager.PublishTweet(rootToken, other)
+
+	//Operation
+	manager.EditTweetTextByID(rootToken, tweet.GetID(), "editado")
+	manager.DeleteTweetByID(rootToken, other.GetID())
+
+	//Validation
+	if tweets, _ := manager.Search("share"); len(tweets) != 0 {
+		t.Errorf("Expected no tweets with the old text but got %d", len(tweets))
+	}
+	if tweets, _ := manager.Search("borrame"); len(tweets) != 0 {
+		t.Errorf("Expected no deleted tweets but got %d", len(tweets))
+	}
+	tweets, _ := manager.Search("editado")
+	expectTweets(t, tweets, []domain.Tweeter{tweet})
+}
+
+func TestCantSearchWithInvalidQuery(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+
+	//Operation
+	_, err := manager.Search(`"hola`)
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't search, Invalid query, unclosed phrase")
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/cursoGo/src/domain"
+)
+
+//DefaultSessionDuration is how long a session lasts unless told otherwise
+const DefaultSessionDuration = 24 * time.Hour
+
+//Session is a user logged in from some client, identified by an opaque token
+type Session struct {
+	Token   string
+	User    domain.User
+	Expires time.Time
+}
+
+//SessionStore issues sessions and keeps them until they expire or get revoked.
+//It is safe for concurrent use
+type SessionStore struct {
+	sessions map[string]Session
+	duration time.Duration
+	clock    domain.Clock
+	mutex    sync.Mutex
+}
+
+//NewSessionStore returns an empty SessionStore whose sessions last the given duration, as told by clock
+func NewSessionStore(duration time.Duration, clock domain.Clock) *SessionStore {
+	return &SessionStore{sessions: make(map[string]Session), duration: duration, clock: clock}
+}
+
+//SetDuration changes how long the sessions created from now on last
+func (s *SessionStore) SetDuration(duration time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.duration = duration
+}
+
+//Create starts a new session for a user, forgetting the ones that already expired
+func (s *SessionStore) Create(user domain.User) (Session, error) {
+	token, err := newSessionToken()
+	if err != nil {
+		return Session{}, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := s.clock.Now()
+	for other, session := range s.sessions {
+		if !now.Before(session.Expires) {
+			delete(s.sessions, other)
+		}
+	}
+	session := Session{Token: token, User: user, Expires: now.Add(s.duration)}
+	s.sessions[token] = session
+	return session, nil
+}
+
+//Len returns how many sessions the store keeps, counting the expired ones that weren't forgotten yet
+func (s *SessionStore) Len() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.sessions)
+}
+
+//Get returns the session that has that token, if it hasn't expired
+func (s *SessionStore) Get(token string) (Session, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.get(token)
+}
+
+func (s *SessionStore) get(token string) (Session, error) {
+	session, ok := s.sessions[token]
+	if !ok {
+		return Session{}, ErrNotLoggedIn
+	}
+	if !s.clock.Now().Before(session.Expires) {
+		delete(s.sessions, token)
+		return Session{}, ErrSessionExpired
+	}
+	return session, nil
+}
+
+//Revoke ends the session that has that token
+func (s *SessionStore) Revoke(token string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, err := s.get(token); err != nil {
+		return err
+	}
+	delete(s.sessions, token)
+	return nil
+}
+
+//RevokeUser ends every session of a user but the one that has the token kept
+func (s *SessionStore) RevokeUser(name string, kept string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for token, session := range s.sessions {
+		if session.User.Name == name && token != kept {
+			delete(s.sessions, token)
+		}
+	}
+}
+
+func newSessionToken() (string, error) {
+	bytes := make([]byte, 16)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
@@ -0,0 +1,34 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/service"
+	"github.com/cursoGo/src/utility"
+)
+
+func TestCreatingSessionForgetsExpiredOnes(t *testing.T) {
+	//Initialization
+	clock := domain.NewFixedClock(time.Date(2018, time.March, 1, 12, 0, 0, 0, time.UTC))
+	store := service.NewSessionStore(time.Hour, clock)
+	expired, _ := store.Create(domain.NewUser("manu", ""))
+	store.Create(domain.NewUser("gonza", ""))
+	clock.Advance(30 * time.Minute)
+	kept, _ := store.Create(domain.NewUser("root", ""))
+	clock.Advance(45 * time.Minute)
+
+	//Operation
+	store.Create(domain.NewUser("manu", ""))
+
+	//Validation
+	if store.Len() != 2 {
+		t.Errorf("Expected 2 sessions but there are %d", store.Len())
+	}
+	_, err := store.Get(expired.Token)
+	utility.ValidateExpectedError(t, err, "Not logged in")
+	if _, err := store.Get(kept.Token); err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+	}
+}
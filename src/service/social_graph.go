@@ -0,0 +1,199 @@
+package service
+
+//Follow is an edge of the SocialGraph: the user with ID Follower follows the one with ID Followed
+type Follow struct {
+	Follower int `json:"follower"`
+	Followed int `json:"followed"`
+}
+
+//FollowCounts are how many followers a user has and how many users it follows
+type FollowCounts struct {
+	Followers int
+	Following int
+}
+
+//SocialGraph keeps who follows, blocks and mutes whom, and who asked to follow protected users, by user ID.
+//Users are listed in the order they were followed, blocked, muted or asked
+type SocialGraph struct {
+	following map[int][]int
+	followers map[int][]int
+	blocked   map[int][]int
+	muted     map[int][]int
+	requests  map[int][]int
+}
+
+//NewSocialGraph returns an empty SocialGraph
+func NewSocialGraph() *SocialGraph {
+	return &SocialGraph{
+		following: make(map[int][]int),
+		followers: make(map[int][]int),
+		blocked:   make(map[int][]int),
+		muted:     make(map[int][]int),
+		requests:  make(map[int][]int),
+	}
+}
+
+//Follow adds the edge from follower to followed. Users that blocked each other can't follow
+func (g *SocialGraph) Follow(follower, followed int) error {
+	if follower == followed {
+		return ErrCantFollowYourself
+	}
+	if g.IsFollowing(follower, followed) {
+		return ErrAlreadyFollowing
+	}
+	if g.AreBlocked(follower, followed) {
+		return ErrBlocked
+	}
+	g.following[follower] = append(g.following[follower], followed)
+	g.followers[followed] = append(g.followers[followed], follower)
+	return nil
+}
+
+//Unfollow removes the edge from follower to followed
+func (g *SocialGraph) Unfollow(follower, followed int) error {
+	if !g.IsFollowing(follower, followed) {
+		return ErrNotFollowing
+	}
+	g.following[follower] = removeID(g.following[follower], followed)
+	g.followers[followed] = removeID(g.followers[followed], follower)
+	return nil
+}
+
+//IsFollowing returns if follower follows followed
+func (g *SocialGraph) IsFollowing(follower, followed int) bool {
+	return containsID(g.following[follower], followed)
+}
+
+//Following returns the IDs of the users that a user follows
+func (g *SocialGraph) Following(id int) []int {
+	return append([]int(nil), g.following[id]...)
+}
+
+//Followers returns the IDs of the users that follow a user
+func (g *SocialGraph) Followers(id int) []int {
+	return append([]int(nil), g.followers[id]...)
+}
+
+//Mutuals returns the IDs of the users that a user follows and that follow it back
+func (g *SocialGraph) Mutuals(id int) []int {
+	var mutuals []int
+	for _, followed := range g.following[id] {
+		if g.IsFollowing(followed, id) {
+			mutuals = append(mutuals, followed)
+		}
+	}
+	return mutuals
+}
+
+//Counts returns how many followers a user has and how many users it follows
+func (g *SocialGraph) Counts(id int) FollowCounts {
+	return FollowCounts{Followers: len(g.followers[id]), Following: len(g.following[id])}
+}
+
+//Block makes blocker block blocked, removing the follows and follow requests between them
+func (g *SocialGraph) Block(blocker, blocked int) error {
+	if blocker == blocked {
+		return ErrCantBlockYourself
+	}
+	if g.IsBlocking(blocker, blocked) {
+		return ErrAlreadyBlocked
+	}
+	g.blocked[blocker] = append(g.blocked[blocker], blocked)
+	g.Unfollow(blocker, blocked)
+	g.Unfollow(blocked, blocker)
+	g.RemoveRequest(blocker, blocked)
+	g.RemoveRequest(blocked, blocker)
+	return nil
+}
+
+//Unblock makes blocker stop blocking blocked. The follows removed when blocking aren't restored
+func (g *SocialGraph) Unblock(blocker, blocked int) error {
+	if !g.IsBlocking(blocker, blocked) {
+		return ErrNotBlocked
+	}
+	g.blocked[blocker] = removeID(g.blocked[blocker], blocked)
+	return nil
+}
+
+//IsBlocking returns if blocker blocked blocked
+func (g *SocialGraph) IsBlocking(blocker, blocked int) bool {
+	return containsID(g.blocked[blocker], blocked)
+}
+
+//AreBlocked returns if any of two users blocked the other
+func (g *SocialGraph) AreBlocked(first, second int) bool {
+	return g.IsBlocking(first, second) || g.IsBlocking(second, first)
+}
+
+//Blocked returns the IDs of the users that a user blocked
+func (g *SocialGraph) Blocked(id int) []int {
+	return append([]int(nil), g.blocked[id]...)
+}
+
+//Mute makes muter mute muted. Only muter knows about it
+func (g *SocialGraph) Mute(muter, muted int) error {
+	if muter == muted {
+		return ErrCantMuteYourself
+	}
+	if g.IsMuting(muter, muted) {
+		return ErrAlreadyMuted
+	}
+	g.muted[muter] = append(g.muted[muter], muted)
+	return nil
+}
+
+//Unmute makes muter stop muting muted
+func (g *SocialGraph) Unmute(muter, muted int) error {
+	if !g.IsMuting(muter, muted) {
+		return ErrNotMuted
+	}
+	g.muted[muter] = removeID(g.muted[muter], muted)
+	return nil
+}
+
+//IsMuting returns if muter muted muted
+func (g *SocialGraph) IsMuting(muter, muted int) bool {
+	return containsID(g.muted[muter], muted)
+}
+
+//Muted returns the IDs of the users that a user muted
+func (g *SocialGraph) Muted(id int) []int {
+	return append([]int(nil), g.muted[id]...)
+}
+
+//Request makes requester ask to follow owner
+func (g *SocialGraph) Request(requester, owner int) error {
+	if requester == owner {
+		return ErrCantFollowYourself
+	}
+	if g.IsFollowing(requester, owner) {
+		return ErrAlreadyFollowing
+	}
+	if g.HasRequested(requester, owner) {
+		return ErrAlreadyRequested
+	}
+	if g.AreBlocked(requester, owner) {
+		return ErrBlocked
+	}
+	g.requests[owner] = append(g.requests[owner], requester)
+	return nil
+}
+
+//RemoveRequest forgets that requester asked to follow owner
+func (g *SocialGraph) RemoveRequest(requester, owner int) error {
+	if !g.HasRequested(requester, owner) {
+		return ErrNoFollowRequest
+	}
+	g.requests[owner] = removeID(g.requests[owner], requester)
+	return nil
+}
+
+//HasRequested returns if requester asked to follow owner
+func (g *SocialGraph) HasRequested(requester, owner int) bool {
+	return containsID(g.requests[owner], requester)
+}
+
+//Requests returns the IDs of the users that asked to follow a user
+func (g *SocialGraph) Requests(owner int) []int {
+	return append([]int(nil), g.requests[owner]...)
+}
@@ -0,0 +1,192 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/service"
+	"github.com/cursoGo/src/utility"
+)
+
+//newSocialManager registers manu, gonza and root in the manager and returns their tokens in that order
+func newSocialManager(manager *service.TweetManager) []string {
+	var tokens []string
+	for _, user := range []domain.User{
+		domain.NewUser("manu", "hunter2"),
+		domain.NewUser("gonza", "hunter3"),
+		domain.NewUser("root", "root"),
+	} {
+		manager.Register(user)
+		token, _ := manager.Login(user)
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+//expectUsers fails the test if the names of the users aren't the expected ones, in order
+func expectUsers(t *testing.T, got []domain.User, expected ...string) {
+	if len(got) != len(expected) {
+		t.Errorf("Expected %v but got %v", expected, got)
+		return
+	}
+	for i, name := range expected {
+		if got[i].Name != name {
+			t.Errorf("Expected %v but got %v", expected, got)
+			return
+		}
+	}
+}
+
+func TestSocialGraphKeepsFollowersAndFollowing(t *testing.T) {
+	//Initialization
+	graph := service.NewSocialGraph()
+
+	//Operation
+	graph.Follow(1, 2)
+	graph.Follow(1, 3)
+	graph.Follow(3, 2)
+
+	//Validation
+	if following := graph.Following(1); len(following) != 2 || following[0] != 2 || following[1] != 3 {
+		t.Errorf("Unexpected following %v", following)
+	}
+	if followers := graph.Followers(2); len(followers) != 2 || followers[0] != 1 || followers[1] != 3 {
+		t.Errorf("Unexpected followers %v", followers)
+	}
+	if counts := graph.Counts(2); counts.Followers != 2 || counts.Following != 0 {
+		t.Errorf("Unexpected counts %+v", counts)
+	}
+}
+
+func TestSocialGraphCantUnfollowWhoIsNotFollowed(t *testing.T) {
+	//Initialization
+	graph := service.NewSocialGraph()
+	graph.Follow(1, 2)
+
+	//Operation
+	err := graph.Unfollow(2, 1)
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "You don't follow that user")
+}
+
+func TestCanUnfollowUser(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	manager.FollowUser(tokens[0], "gonza")
+	tweet, _ := manager.NewTextTweet(domain.NewUser("gonza", ""), "hola")
+	manager.PublishTweet(tokens[1], tweet)
+
+	//Operation
+	err := manager.UnfollowUser(tokens[0], "gonza")
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	user, _ := manager.GetLoggedInUser(tokens[0])
+	if user.IsFollowing(domain.NewUser("gonza", "")) {
+		t.Error("The user is still followed")
+	}
+	if timeline, _ := manager.GetTimeline(tokens[0]); len(timeline) != 0 {
+		t.Errorf("Expected an empty timeline but got %d tweets", len(timeline))
+	}
+}
+
+func TestCantUnfollowUserThatIsNotFollowed(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+
+	//Operation
+	err := manager.UnfollowUser(tokens[0], "gonza")
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't unfollow user, You don't follow that user")
+}
+
+func TestCanGetFollowersFollowingAndMutuals(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	manu := domain.NewUser("manu", "")
+
+	//Operation
+	manager.FollowUser(tokens[0], "gonza")
+	manager.FollowUser(tokens[0], "root")
+	manager.FollowUser(tokens[1], "manu")
+
+	//Validation
+	following, _ := manager.GetFollowing(manu)
+	expectUsers(t, following, "gonza", "root")
+	followers, _ := manager.GetFollowers(manu)
+	expectUsers(t, followers, "gonza")
+	mutuals, _ := manager.GetMutuals(manu)
+	expectUsers(t, mutuals, "gonza")
+	if counts, _ := manager.GetFollowCounts(manu); counts.Followers != 1 || counts.Following != 2 {
+		t.Errorf("Unexpected counts %+v", counts)
+	}
+}
+
+func TestCantGetFollowersOfUnregisteredUser(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+
+	//Operation
+	_, err := manager.GetFollowers(domain.NewUser("nobody", ""))
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "That user is not registered")
+}
+
+func TestFollowsSurviveRestart(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+	var manager service.TweetManager
+	manager.InitializeManagerWithRepository(service.NewFileTweetRepository(path))
+	tokens := newSocialManager(&manager)
+	manager.FollowUser(tokens[0], "gonza")
+	manager.FollowUser(tokens[0], "root")
+	manager.UnfollowUser(tokens[0], "gonza")
+
+	//Operation
+	var restarted service.TweetManager
+	restarted.InitializeManagerWithRepository(service.NewFileTweetRepository(path))
+
+	//Validation
+	following, _ := restarted.GetFollowing(domain.NewUser("manu", ""))
+	expectUsers(t, following, "root")
+	followers, _ := restarted.GetFollowers(domain.NewUser("root", ""))
+	expectUsers(t, followers, "manu")
+}
+
+func TestFollowsAreReplayedFromEventLog(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+	var manager service.TweetManager
+	repository := service.NewEventLogRepository(path, 0)
+	manager.InitializeManagerWithRepository(repository)
+	tokens := newSocialManager(&manager)
+	manager.FollowUser(tokens[1], "root")
+	manager.FollowUser(tokens[1], "manu")
+	manager.UnfollowUser(tokens[1], "root")
+	repository.Close()
+
+	//Operation
+	var restarted service.TweetManager
+	restartedRepository := service.NewEventLogRepository(path, 0)
+	restarted.InitializeManagerWithRepository(restartedRepository)
+	defer restartedRepository.Close()
+
+	//Validation
+	following, _ := restarted.GetFollowing(domain.NewUser("gonza", ""))
+	expectUsers(t, following, "manu")
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cursoGo/src/domain"
+)
+
+//ThreadNode is a tweet of a conversation, with the replies it got, oldest first.
+//Deleted tweets that had replies show up as their Tombstone, with a nil Tweet, and so do
+//the tweets hidden from whoever asked for the conversation, which are Hidden
+type ThreadNode struct {
+	Tweet     domain.Tweeter
+	Tombstone *domain.Tombstone
+	Hidden    bool
+	Replies   []*ThreadNode
+}
+
+//GetID returns the ID of the tweet of the node
+func (n *ThreadNode) GetID() int {
+	if n.Tombstone != nil {
+		return n.Tombstone.ID
+	}
+	return n.Tweet.GetID()
+}
+
+//IsDeleted returns if the tweet of the node was deleted
+func (n *ThreadNode) IsDeleted() bool {
+	return n.Tombstone != nil && !n.Hidden
+}
+
+//hide replaces the tweets of the conversation for which hidden is true with tombstones
+//that don't tell who wrote them
+func (n *ThreadNode) hide(hidden func(domain.Tweeter) bool) {
+	if n.Tweet != nil && hidden(n.Tweet) {
+		tombstone := domain.NewTombstone(n.Tweet)
+		tombstone.User = domain.User{}
+		n.Tweet, n.Tombstone, n.Hidden = nil, &tombstone, true
+	}
+	for _, reply := range n.Replies {
+		reply.hide(hidden)
+	}
+}
+
+func (n *ThreadNode) parentID() (int, bool) {
+	if n.Tombstone != nil {
+		if n.Tombstone.ParentID == nil {
+			return 0, false
+		}
+		return *n.Tombstone.ParentID, true
+	}
+	return domain.GetParentID(n.Tweet)
+}
+
+func (n *ThreadNode) date() time.Time {
+	if n.Tombstone != nil {
+		return n.Tombstone.Date
+	}
+	return *n.Tweet.GetDate()
+}
+
+//String returns the tweet of the node, without its replies
+func (n *ThreadNode) String() string {
+	if n.Hidden {
+		return fmt.Sprintf("[%d] This tweet is unavailable", n.Tombstone.ID)
+	}
+	if n.Tombstone != nil {
+		return n.Tombstone.String()
+	}
+	if reply, ok := n.Tweet.(*domain.ReplyTweet); ok {
+		//Who it replies to is already told by the tree
+		return reply.TextTweet.String()
+	}
+	return n.Tweet.String()
+}
+
+//Format returns the conversation as a tree, every reply indented under the tweet it answers
+func (n *ThreadNode) Format() string {
+	var lines []string
+	n.format(0, &lines)
+	return strings.Join(lines, "\n")
+}
+
+func (n *ThreadNode) format(depth int, lines *[]string) {
+	indent := strings.Repeat("    ", depth)
+	for _, line := range strings.Split(n.String(), "\n") {
+		*lines = append(*lines, indent+line)
+	}
+	for _, reply := range n.Replies {
+		reply.format(depth+1, lines)
+	}
+}
+
+//Size returns how many tweets the conversation has, counting the deleted and hidden ones
+func (n *ThreadNode) Size() int {
+	size := 1
+	for _, reply := range n.Replies {
+		size += reply.Size()
+	}
+	return size
+}
+
+//buildThread returns the conversation that the tweet with that ID is part of, from its first tweet
+func buildThread(repository TweetRepository, id int) (*ThreadNode, error) {
+	nodes := make(map[int]*ThreadNode)
+	for _, tweet := range repository.GetTweets() {
+		nodes[tweet.GetID()] = &ThreadNode{Tweet: tweet}
+	}
+	for _, tombstone := range repository.GetTombstones() {
+		tombstone := tombstone
+		nodes[tombstone.ID] = &ThreadNode{Tombstone: &tombstone}
+	}
+	node, ok := nodes[id]
+	if !ok {
+		return nil, fmt.Errorf("Couldn't retrieve thread, %w", ErrTweetNotFound)
+	}
+
+	for _, reply := range nodes {
+		if parentID, ok := reply.parentID(); ok {
+			if parent, ok := nodes[parentID]; ok {
+				parent.Replies = append(parent.Replies, reply)
+			}
+		}
+	}
+	for _, parent := range nodes {
+		replies := parent.Replies
+		sort.Slice(replies, func(i, j int) bool {
+			if !replies[i].date().Equal(replies[j].date()) {
+				return replies[i].date().Before(replies[j].date())
+			}
+			return replies[i].GetID() < replies[j].GetID()
+		})
+	}
+
+	for parentID, ok := node.parentID(); ok; parentID, ok = node.parentID() {
+		parent, found := nodes[parentID]
+		if !found {
+			break
+		}
+		node = parent
+	}
+	return node, nil
+}
@@ -0,0 +1,249 @@
+package service_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/service"
+	"github.com/cursoGo/src/utility"
+)
+
+//newConversation publishes a tweet of root, a reply of gonza to it and a reply of root to that reply.
+//It returns the tokens of root and gonza and the three tweets
+func newConversation(t *testing.T, manager *service.TweetManager) (string, string, []domain.Tweeter) {
+	root := domain.NewUser("root", "root")
+	gonza := domain.NewUser("gonza", "hunter3")
+	manager.Register(root)
+	manager.Register(gonza)
+	rootToken, _ := manager.Login(root)
+	gonzaToken, _ := manager.Login(gonza)
+
+	tweet, _ := manager.NewTextTweet(root, "what do you think?")
+	manager.PublishTweet(rootToken, tweet)
+	reply, err := manager.ReplyToTweet(gonzaToken, tweet.GetID(), "I agree")
+	if err != nil {
+		t.Fatalf("Unexpected error, %s", err.Error())
+	}
+	answer, err := manager.ReplyToTweet(rootToken, reply.GetID(), "thanks")
+	if err != nil {
+		t.Fatalf("Unexpected error, %s", err.Error())
+	}
+	return rootToken, gonzaToken, []domain.Tweeter{tweet, reply, answer}
+}
+
+func TestCanReplyToTweet(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+
+	//Operation
+	_, _, tweets := newConversation(t, &manager)
+
+	//Validation
+	reply, ok := tweets[1].(*domain.ReplyTweet)
+	if !ok || reply.GetParentID() != tweets[0].GetID() {
+		t.Errorf("Expected a reply to %d but got %s", tweets[0].GetID(), tweets[1])
+	}
+	if !manager.TweetExists(reply) {
+		t.Error("The reply was not published")
+	}
+}
+
+func TestCantReplyToNonExistentTweet(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	user := domain.NewUser("root", "root")
+	manager.Register(user)
+	token, _ := manager.Login(user)
+
+	//Operation
+	_, err := manager.ReplyToTweet(token, 42, "hello?")
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't reply, A tweet with that ID does not exist")
+}
+
+func TestCantReplyWithoutLoggingIn(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	_, _, tweets := newConversation(t, &manager)
+
+	//Operation
+	_, err := manager.ReplyToTweet("", tweets[0].GetID(), "hello?")
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't reply, You must be logged in to tweet")
+}
+
+func TestThreadStartsFromTheFirstTweet(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	_, gonzaToken, tweets := newConversation(t, &manager)
+	other, _ := manager.ReplyToTweet(gonzaToken, tweets[0].GetID(), "me too")
+
+	//Operation
+	thread, err := manager.GetThread("", tweets[2].GetID())
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if thread.GetID() != tweets[0].GetID() || thread.Size() != 4 {
+		t.Errorf("Unexpected thread\n%s", thread.Format())
+		return
+	}
+	if len(thread.Replies) != 2 || thread.Replies[0].GetID() != tweets[1].GetID() || thread.Replies[1].GetID() != other.GetID() {
+		t.Errorf("Expected replies oldest first\n%s", thread.Format())
+	}
+}
+
+func TestThreadIsFormattedAsIndentedTree(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	_, _, tweets := newConversation(t, &manager)
+
+	//Operation
+	thread, _ := manager.GetThread("", tweets[0].GetID())
+	lines := strings.Split(thread.Format(), "\n")
+
+	//Validation
+	if len(lines) != 3 {
+		t.Errorf("Expected 3 lines but got %d", len(lines))
+		return
+	}
+	if !strings.HasPrefix(lines[1], "    [") || !strings.HasPrefix(lines[2], "        [") {
+		t.Errorf("Replies are not indented\n%s", thread.Format())
+	}
+}
+
+func TestDeletingParentLeavesTombstone(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	_, gonzaToken, tweets := newConversation(t, &manager)
+
+	//Operation
+	err := manager.DeleteTweetByID(gonzaToken, tweets[1].GetID())
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	thread, _ := manager.GetThread("", tweets[2].GetID())
+	if thread.GetID() != tweets[0].GetID() || thread.Size() != 3 {
+		t.Errorf("The conversation was broken\n%s", thread.Format())
+		return
+	}
+	deleted := thread.Replies[0]
+	if !deleted.IsDeleted() || !strings.Contains(deleted.String(), "This tweet was deleted") {
+		t.Errorf("Expected a tombstone but got %s", deleted)
+	}
+	if _, err := manager.ReplyToTweet(gonzaToken, tweets[1].GetID(), "again"); err == nil {
+		t.Error("Should not reply to a deleted tweet")
+	}
+}
+
+func TestDeletingTweetWithoutRepliesLeavesNoTombstone(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	rootToken, _, tweets := newConversation(t, &manager)
+
+	//Operation
+	manager.DeleteTweetByID(rootToken, tweets[2].GetID())
+
+	//Validation
+	thread, _ := manager.GetThread("", tweets[0].GetID())
+	if thread.Size() != 2 {
+		t.Errorf("Unexpected thread\n%s", thread.Format())
+	}
+}
+
+func TestTombstonesSurviveRestart(t *testing.T) {
+	//Initialization
+	path, cleanup := tempDataFile(t)
+	defer cleanup()
+	var manager service.TweetManager
+	manager.InitializeManagerWithRepository(service.NewFileTweetRepository(path))
+	rootToken, _, tweets := newConversation(t, &manager)
+	manager.DeleteTweetByID(rootToken, tweets[0].GetID())
+
+	//Operation
+	var restarted service.TweetManager
+	restarted.InitializeManagerWithRepository(service.NewFileTweetRepository(path))
+	thread, err := restarted.GetThread("", tweets[2].GetID())
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if !thread.IsDeleted() || thread.GetID() != tweets[0].GetID() || thread.Size() != 3 {
+		t.Errorf("Unexpected thread\n%s", thread.Format())
+	}
+}
+
+func TestThreadHidesTweetsTheViewerCantSee(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	rootToken, gonzaToken, tweets := newConversation(t, &manager)
+	manager.SetProtected(gonzaToken, true)
+
+	//Operation
+	thread, err := manager.GetThread(rootToken, tweets[2].GetID())
+	own, _ := manager.GetThread(gonzaToken, tweets[2].GetID())
+	_, hiddenErr := manager.GetThread("", tweets[1].GetID())
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	reply := thread.Replies[0]
+	if !reply.Hidden || reply.IsDeleted() || reply.Tweet != nil || reply.Tombstone.User.Name != "" || thread.Size() != 3 {
+		t.Errorf("Expected the reply of the protected user to be hidden\n%s", thread.Format())
+	}
+	if strings.Contains(thread.Format(), "I agree") || !strings.Contains(reply.String(), "This tweet is unavailable") {
+		t.Errorf("Unexpected thread\n%s", thread.Format())
+	}
+	if own.Replies[0].Hidden {
+		t.Errorf("The protected user should see their own reply\n%s", own.Format())
+	}
+	utility.ValidateExpectedError(t, hiddenErr, "Couldn't retrieve thread, A tweet with that ID does not exist")
+}
+
+func TestThreadHidesTweetsOfBlockedUsers(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	_, gonzaToken, tweets := newConversation(t, &manager)
+
+	//Operation
+	manager.BlockUser(gonzaToken, "root")
+	thread, _ := manager.GetThread(gonzaToken, tweets[1].GetID())
+
+	//Validation
+	if !thread.Hidden || thread.Replies[0].Hidden || !thread.Replies[0].Replies[0].Hidden {
+		t.Errorf("Expected the tweets of root to be hidden\n%s", thread.Format())
+	}
+}
+
+func TestCantRetrieveThreadOfNonExistentTweet(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+
+	//Operation
+	_, err := manager.GetThread("", 42)
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't retrieve thread, A tweet with that ID does not exist")
+}
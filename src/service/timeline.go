@@ -0,0 +1,173 @@
+package service
+
+import (
+	"container/heap"
+	"sort"
+
+	"github.com/cursoGo/src/domain"
+)
+
+//DefaultPageSize is how many tweets a timeline page has when no limit is asked for
+const DefaultPageSize = 20
+
+//PageRequest asks for a page of a timeline, newest tweets first.
+//Pages are found from a cursor, the ID of a tweet at the edge of the page that was shown before
+type PageRequest struct {
+	//Before asks for the tweets older than the one with this ID, when HasBefore is set
+	Before    int
+	HasBefore bool
+	//After asks for the tweets newer than the one with this ID, when HasAfter is set
+	After    int
+	HasAfter bool
+	//Limit is the maximum number of tweets in the page, DefaultPageSize if it isn't positive
+	Limit int
+}
+
+//FirstPage asks for the newest tweets of a timeline
+func FirstPage(limit int) PageRequest {
+	return PageRequest{Limit: limit}
+}
+
+//PageBefore asks for the tweets that are older than the one with that ID
+func PageBefore(id int, limit int) PageRequest {
+	return PageRequest{Before: id, HasBefore: true, Limit: limit}
+}
+
+//PageAfter asks for the tweets that are newer than the one with that ID
+func PageAfter(id int, limit int) PageRequest {
+	return PageRequest{After: id, HasAfter: true, Limit: limit}
+}
+
+func (r PageRequest) limit() int {
+	if r.Limit <= 0 {
+		return DefaultPageSize
+	}
+	return r.Limit
+}
+
+//TimelinePage is a page of a timeline, newest tweets first
+type TimelinePage struct {
+	Tweets   []domain.Tweeter
+	HasOlder bool
+	HasNewer bool
+}
+
+//Older asks for the page that follows this one, with older tweets
+func (p TimelinePage) Older(limit int) PageRequest {
+	if len(p.Tweets) == 0 {
+		return FirstPage(limit)
+	}
+	return PageBefore(p.Tweets[len(p.Tweets)-1].GetID(), limit)
+}
+
+//Newer asks for the page that precedes this one, with newer tweets
+func (p TimelinePage) Newer(limit int) PageRequest {
+	if len(p.Tweets) == 0 {
+		return FirstPage(limit)
+	}
+	return PageAfter(p.Tweets[0].GetID(), limit)
+}
+
+//isNewer returns if a tweet goes before another one in a timeline.
+//Tweets published at the same time are ordered by ID
+func isNewer(t1, t2 domain.Tweeter) bool {
+	if !t1.GetDate().Equal(*t2.GetDate()) {
+		return t1.GetDate().After(*t2.GetDate())
+	}
+	return t1.GetID() > t2.GetID()
+}
+
+//newestFirst returns a copy of tweets sorted as they go in a timeline
+func newestFirst(tweets []domain.Tweeter) []domain.Tweeter {
+	sorted := append([]domain.Tweeter(nil), tweets...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return isNewer(sorted[i], sorted[j])
+	})
+	return sorted
+}
+
+//timelineMerge merges the tweets of many authors, each already sorted newest first,
+//handing out the newest of all of them one at a time
+type timelineMerge [][]domain.Tweeter
+
+func (m timelineMerge) Len() int            { return len(m) }
+func (m timelineMerge) Less(i, j int) bool  { return isNewer(m[i][0], m[j][0]) }
+func (m timelineMerge) Swap(i, j int)       { m[i], m[j] = m[j], m[i] }
+func (m *timelineMerge) Push(x interface{}) { *m = append(*m, x.([]domain.Tweeter)) }
+func (m *timelineMerge) Pop() interface{} {
+	old := *m
+	last := old[len(old)-1]
+	*m = old[:len(old)-1]
+	return last
+}
+
+func newTimelineMerge(authors [][]domain.Tweeter) *timelineMerge {
+	merge := make(timelineMerge, 0, len(authors))
+	for _, tweets := range authors {
+		if len(tweets) > 0 {
+			merge = append(merge, tweets)
+		}
+	}
+	heap.Init(&merge)
+	return &merge
+}
+
+//next returns the newest tweet that wasn't handed out yet
+func (m *timelineMerge) next() (domain.Tweeter, bool) {
+	if m.Len() == 0 {
+		return nil, false
+	}
+	tweets := (*m)[0]
+	tweet := tweets[0]
+	if len(tweets) > 1 {
+		(*m)[0] = tweets[1:]
+		heap.Fix(m, 0)
+	} else {
+		heap.Pop(m)
+	}
+	return tweet, true
+}
+
+//mergeTimeline returns every tweet of the authors, newest first
+func mergeTimeline(authors [][]domain.Tweeter) []domain.Tweeter {
+	merge := newTimelineMerge(authors)
+	var timeline []domain.Tweeter
+	for tweet, ok := merge.next(); ok; tweet, ok = merge.next() {
+		timeline = append(timeline, tweet)
+	}
+	return timeline
+}
+
+//pageTimeline returns the page asked for of the merged tweets of the authors.
+//cursor is the tweet the request refers to, if it has one
+func pageTimeline(authors [][]domain.Tweeter, request PageRequest, cursor domain.Tweeter) TimelinePage {
+	merge := newTimelineMerge(authors)
+	limit := request.limit()
+	var page TimelinePage
+
+	switch {
+	case request.HasAfter:
+		//Only the last tweets before reaching the cursor are kept
+		for tweet, ok := merge.next(); ok && isNewer(tweet, cursor); tweet, ok = merge.next() {
+			page.Tweets = append(page.Tweets, tweet)
+			if len(page.Tweets) > limit {
+				page.Tweets = page.Tweets[1:]
+				page.HasNewer = true
+			}
+		}
+		page.HasOlder = true
+	default:
+		tweet, ok := merge.next()
+		if request.HasBefore {
+			for ok && !isNewer(cursor, tweet) {
+				page.HasNewer = true
+				tweet, ok = merge.next()
+			}
+		}
+		for ; ok && len(page.Tweets) < limit; tweet, ok = merge.next() {
+			page.Tweets = append(page.Tweets, tweet)
+		}
+		page.HasOlder = ok
+	}
+	return page
+}
@@ -0,0 +1,166 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/service"
+	"github.com/cursoGo/src/utility"
+)
+
+//newTimelineManager returns a manager where manu follows gonza, both have published tweets
+//a minute apart from each other, and the token of manu. The returned tweets are newest first
+func newTimelineManager(t *testing.T, count int) (*service.TweetManager, string, []domain.Tweeter) {
+	clock := domain.NewFixedClock(time.Date(2018, time.March, 1, 12, 0, 0, 0, time.UTC))
+	manager := &service.TweetManager{}
+	manager.InitializeManagerWith(service.NewMemoryTweetRepository(), domain.NewSequentialIDGenerator(), clock)
+	manu := domain.NewUser("manu", "hunter2")
+	gonza := domain.NewUser("gonza", "hunter3")
+	manager.Register(manu)
+	manager.Register(gonza)
+	manuToken, _ := manager.Login(manu)
+	gonzaToken, _ := manager.Login(gonza)
+	manager.FollowUser(manuToken, gonza.Name)
+
+	var tweets []domain.Tweeter
+	for i := 0; i < count; i++ {
+		user, token := manu, manuToken
+		if i%3 == 0 {
+			user, token = gonza, gonzaToken
+		}
+		tweet, _ := manager.NewTextTweet(user, "sample")
+		if err := manager.PublishTweet(token, tweet); err != nil {
+			t.Fatalf("Unexpected error, %s", err.Error())
+		}
+		tweets = append([]domain.Tweeter{tweet}, tweets...)
+		clock.Advance(time.Minute)
+	}
+	return manager, manuToken, tweets
+}
+
+//expectTweets checks that got are the expected tweets, in the same order
+func expectTweets(t *testing.T, got []domain.Tweeter, expected []domain.Tweeter) {
+	if len(got) != len(expected) {
+		t.Errorf("Expected size is %d but was %d", len(expected), len(got))
+		return
+	}
+	for i := range expected {
+		if got[i].GetID() != expected[i].GetID() {
+			t.Errorf("Expected tweet %d at position %d but was %d", expected[i].GetID(), i, got[i].GetID())
+		}
+	}
+}
+
+func TestTimelineIsMergedNewestFirst(t *testing.T) {
+	//Initialization
+	manager, token, tweets := newTimelineManager(t, 7)
+
+	//Operation
+	timeline, err := manager.GetTimeline(token)
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	expectTweets(t, timeline, tweets)
+}
+
+func TestTimelineIsOrderedByDateNotByPublication(t *testing.T) {
+	//Initialization
+	clock := domain.NewFixedClock(time.Date(2018, time.March, 1, 12, 0, 0, 0, time.UTC))
+	var manager service.TweetManager
+	manager.InitializeManagerWith(service.NewMemoryTweetRepository(), domain.NewSequentialIDGenerator(), clock)
+	user := domain.NewUser("root", "root")
+	manager.Register(user)
+	token, _ := manager.Login(user)
+	older, _ := manager.NewTextTweet(user, "older")
+	clock.Advance(time.Minute)
+	newer, _ := manager.NewTextTweet(user, "newer")
+
+	//Operation
+	manager.PublishTweet(token, newer)
+	manager.PublishTweet(token, older)
+	timeline, _ := manager.GetTimeline(token)
+
+	//Validation
+	expectTweets(t, timeline, []domain.Tweeter{newer, older})
+}
+
+func TestCanPageThroughTimeline(t *testing.T) {
+	//Initialization
+	manager, token, tweets := newTimelineManager(t, 7)
+
+	//Operation
+	first, _ := manager.GetTimelinePage(token, service.FirstPage(3))
+	second, _ := manager.GetTimelinePage(token, first.Older(3))
+	third, _ := manager.GetTimelinePage(token, second.Older(3))
+
+	//Validation
+	expectTweets(t, first.Tweets, tweets[0:3])
+	expectTweets(t, second.Tweets, tweets[3:6])
+	expectTweets(t, third.Tweets, tweets[6:7])
+	if first.HasNewer || !first.HasOlder {
+		t.Error("The first page should only have older pages")
+	}
+	if !second.HasNewer || !second.HasOlder {
+		t.Error("The second page should have newer and older pages")
+	}
+	if !third.HasNewer || third.HasOlder {
+		t.Error("The last page should only have newer pages")
+	}
+}
+
+func TestCanPageBackToNewerTweets(t *testing.T) {
+	//Initialization
+	manager, token, tweets := newTimelineManager(t, 7)
+	last, _ := manager.GetTimelinePage(token, service.PageBefore(tweets[4].GetID(), 5))
+
+	//Operation
+	previous, err := manager.GetTimelinePage(token, last.Newer(2))
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	expectTweets(t, last.Tweets, tweets[5:7])
+	expectTweets(t, previous.Tweets, tweets[3:5])
+	if !previous.HasNewer || !previous.HasOlder {
+		t.Error("The page should have newer and older pages")
+	}
+}
+
+func TestTimelinePageHasDefaultSize(t *testing.T) {
+	//Initialization
+	manager, token, tweets := newTimelineManager(t, service.DefaultPageSize+1)
+
+	//Operation
+	page, _ := manager.GetTimelinePage(token, service.FirstPage(0))
+
+	//Validation
+	expectTweets(t, page.Tweets, tweets[:service.DefaultPageSize])
+}
+
+func TestCantPageFromUnknownTweet(t *testing.T) {
+	//Initialization
+	manager, token, _ := newTimelineManager(t, 3)
+
+	//Operation
+	_, err := manager.GetTimelinePage(token, service.PageBefore(42, 3))
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't retrieve timeline, A tweet with that ID does not exist")
+}
+
+func TestCantPageTimelineWithoutLoggingIn(t *testing.T) {
+	//Initialization
+	manager, _, _ := newTimelineManager(t, 3)
+
+	//Operation
+	_, err := manager.GetTimelinePage("", service.FirstPage(3))
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "No user logged in")
+}
@@ -1,198 +1,537 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"log"
+	"sync"
+	"time"
 
 	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/events"
+	"github.com/cursoGo/src/search"
 )
 
-//TweetManager is a tweet manager
+//TweetManager is a tweet manager. It is safe for concurrent use, and it is the one
+//that takes care of synchronising the access to its repository
 type TweetManager struct {
-	users        []domain.User
-	userTweets   map[string][]domain.Tweeter
-	loggedInUser domain.User
+	repository    TweetRepository
+	notifications NotificationRepository
+	sessions      *SessionStore
+	ids           domain.IDGenerator
+	tweets        *domain.TweetFactory
+	clock         domain.Clock
+	bus           *events.Bus
+	//pending are the events of the operation in progress, published once it lets go of the mutex
+	pending []events.Event
+	//publishing tells if some goroutine is publishing the pending events, so that they are published in order
+	publishing bool
+	lastID     int
+	mutex      sync.RWMutex
 }
 
-//InitializeManager initializes the manager
+//InitializeManager initializes the manager with an in-memory repository,
+//giving its tweets sequential IDs of its own
 func (m *TweetManager) InitializeManager() {
-	m.users = make([]domain.User, 0)
-	m.userTweets = make(map[string][]domain.Tweeter)
-	domain.ResetCurrentID()
-	m.Logout()
+	m.InitializeManagerWith(NewMemoryTweetRepository(), domain.NewSequentialIDGenerator(), domain.SystemClock{})
+}
+
+//loadableRepository is a repository that has to read its contents before being used
+type loadableRepository interface {
+	Load() error
+}
+
+//InitializeManagerWithRepository initializes the manager storing everything in the given repository,
+//loading whatever it already had. Its tweets share their IDs with the ones created by the domain package
+func (m *TweetManager) InitializeManagerWithRepository(repository TweetRepository) error {
+	return m.InitializeManagerWith(repository, domain.DefaultIDGenerator(), domain.SystemClock{})
+}
+
+//InitializeManagerWith initializes the manager storing everything in the given repository,
+//creating tweets with IDs from ids and telling the time with clock. The options change how its
+//tweets are created, as WithMaxTweetLength does. Notifications are kept in memory only
+func (m *TweetManager) InitializeManagerWith(repository TweetRepository, ids domain.IDGenerator, clock domain.Clock, options ...domain.TweetFactoryOption) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.repository = repository
+	m.notifications = NewMemoryNotificationRepository()
+	m.sessions = NewSessionStore(DefaultSessionDuration, clock)
+	m.ids = ids
+	m.tweets = domain.NewTweetFactory(ids, clock, options...)
+	m.clock = clock
+	m.bus = events.NewBus()
+	m.lastID = -1
+
+	if loadable, ok := repository.(loadableRepository); ok {
+		err := loadable.Load()
+		if err != nil {
+			return fmt.Errorf("Couldn't initialize manager, %w", err)
+		}
+	}
+	for _, tweet := range repository.GetTweets() {
+		m.restoreID(tweet)
+		if tweet.GetID() > m.lastID {
+			m.lastID = tweet.GetID()
+		}
+	}
+	return nil
+}
+
+//restoreID keeps new tweets from reusing the ID of a loaded one, or of the ones it quotes or retweets
+func (m *TweetManager) restoreID(tweet domain.Tweeter) {
+	if restorer, ok := m.ids.(domain.IDRestorer); ok {
+		restorer.Restore(tweet.GetID())
+	}
+	if quote, ok := tweet.(*domain.QuoteTweet); ok && quote.GetQuotedTweet() != nil {
+		m.restoreID(quote.GetQuotedTweet())
+	}
+	if retweet, ok := tweet.(*domain.Retweet); ok {
+		m.restoreID(retweet.GetRetweeted())
+	}
+}
+
+//MaxTweetLength returns how many characters the text of the tweets of the manager can have
+func (m *TweetManager) MaxTweetLength() int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.tweets.MaxTweetLength()
+}
+
+//NewTextTweet returns a new TextTweet with an ID and date given by the manager
+func (m *TweetManager) NewTextTweet(user domain.User, text string) (*domain.TextTweet, error) {
+	return m.tweets.NewTextTweet(user, text)
+}
+
+//NewImageTweet returns a new ImageTweet with an ID and date given by the manager
+func (m *TweetManager) NewImageTweet(user domain.User, text string, url string) (*domain.ImageTweet, error) {
+	return m.tweets.NewImageTweet(user, text, url)
+}
+
+//NewQuoteTweet returns a new QuoteTweet with an ID and date given by the manager
+func (m *TweetManager) NewQuoteTweet(user domain.User, text string, quoted domain.Tweeter) (*domain.QuoteTweet, error) {
+	return m.tweets.NewQuoteTweet(user, text, quoted)
+}
+
+//NewReplyTweet returns a new ReplyTweet with an ID and date given by the manager
+func (m *TweetManager) NewReplyTweet(user domain.User, text string, parent domain.Tweeter) (*domain.ReplyTweet, error) {
+	return m.tweets.NewReplyTweet(user, text, parent)
+}
+
+//NewRetweet returns a new Retweet with an ID and date given by the manager
+func (m *TweetManager) NewRetweet(user domain.User, retweeted domain.Tweeter) *domain.Retweet {
+	return m.tweets.NewRetweet(user, retweeted)
 }
 
 //Register register a user
 func (m *TweetManager) Register(userToRegister domain.User) error {
+	defer m.publishEvents()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
 	if userToRegister.Name == "" {
-		return fmt.Errorf("Invalid name")
+		return ErrInvalidName
 	}
 	if userToRegister.Password == "" {
-		return fmt.Errorf("Invalid password")
+		return ErrInvalidPassword
 	}
 
-	if m.IsRegistered(userToRegister) {
-		return fmt.Errorf("The user is already registered")
+	if m.isRegistered(userToRegister) {
+		return ErrAlreadyRegistered
+	}
+	credentials, err := domain.NewCredentials(userToRegister.Name, userToRegister.Password)
+	if err != nil {
+		return err
+	}
+	err = m.repository.AddUserWithCredentials(userToRegister.Public(), credentials)
+	if err != nil {
+		return err
+	}
+	if registered, err := m.repository.GetUserByName(userToRegister.Name); err == nil {
+		m.emit(events.UserRegistered{User: registered.Public()})
 	}
-	m.users = append(m.users, userToRegister)
-	m.userTweets[userToRegister.Name] = make([]domain.Tweeter, 0)
 	return nil
 }
 
 //IsRegistered verifies that a user is registered
 func (m *TweetManager) IsRegistered(user domain.User) bool {
-	_, ok := m.userTweets[user.Name]
-	return ok
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.isRegistered(user)
 }
 
-func (m *TweetManager) validateLogin(user domain.User) bool {
-	for _, u := range m.users {
-		if u.Equals(user) {
-			return true
-		}
+func (m *TweetManager) isRegistered(user domain.User) bool {
+	_, err := m.repository.GetUserByName(user.Name)
+	return err == nil
+}
+
+func (m *TweetManager) validateLogin(user domain.User) (*domain.User, *domain.Credentials, bool) {
+	registeredUser, err := m.repository.GetUserByName(user.Name)
+	if err != nil {
+		return nil, nil, false
 	}
-	return false
+	credentials, err := m.repository.GetCredentials(user.Name)
+	if err != nil || !credentials.Verify(user.Password) {
+		return nil, nil, false
+	}
+	return registeredUser, credentials, true
 }
 
-//Login logs the user in
-func (m *TweetManager) Login(user domain.User) error {
-	if m.isLoggedIn() {
-		return fmt.Errorf("Already logged in")
+//upgradeCredentials hashes again the password of a user that just logged in,
+//when their hash was made with an older scheme. If it fails, the old hash is kept
+func (m *TweetManager) upgradeCredentials(user domain.User) {
+	credentials, err := domain.NewCredentials(user.Name, user.Password)
+	if err != nil {
+		log.Printf("Couldn't hash again the password of %s, %s", user.Name, err.Error())
+		return
 	}
-	if !m.validateLogin(user) {
-		return fmt.Errorf("The user is not registered")
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if err := m.repository.SetCredentials(credentials); err != nil {
+		log.Printf("Couldn't save the new credentials of %s, %s", user.Name, err.Error())
 	}
+}
 
-	m.loggedInUser = user
-	return nil
+//SetSessionDuration changes how long the sessions started from now on last
+func (m *TweetManager) SetSessionDuration(duration time.Duration) {
+	m.sessions.SetDuration(duration)
 }
 
-//GetLoggedInUser returns the logged in user
-func (m *TweetManager) GetLoggedInUser() (*domain.User, error) {
-	if !m.isLoggedIn() {
-		return nil, fmt.Errorf("Not logged in")
+//Login starts a new session for the user and returns its token.
+//A user can be logged in from many clients at once, each with its own session
+func (m *TweetManager) Login(user domain.User) (string, error) {
+	m.mutex.RLock()
+	registeredUser, credentials, ok := m.validateLogin(user)
+	m.mutex.RUnlock()
+	if !ok {
+		return "", ErrInvalidLogin
+	}
+	if credentials.NeedsUpgrade() {
+		m.upgradeCredentials(user)
+	}
+	session, err := m.sessions.Create(*registeredUser)
+	if err != nil {
+		return "", err
 	}
-	return &m.loggedInUser, nil
+	return session.Token, nil
 }
 
-//Logout logs the user out
-func (m *TweetManager) Logout() error {
-	if !m.isLoggedIn() {
-		return fmt.Errorf("Not logged in")
+//ChangePassword changes the password of the user logged in with a session, given their current one.
+//Every other session of the user ends
+func (m *TweetManager) ChangePassword(token string, oldPassword string, newPassword string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	user, err := m.loggedInUser(token)
+	if err != nil {
+		return fmt.Errorf("Couldn't change password, %w", err)
+	}
+	if newPassword == "" {
+		return fmt.Errorf("Couldn't change password, %w", ErrInvalidPassword)
+	}
+	credentials, err := m.repository.GetCredentials(user.Name)
+	if err != nil {
+		return fmt.Errorf("Couldn't change password, %w", err)
+	}
+	if !credentials.Verify(oldPassword) {
+		return fmt.Errorf("Couldn't change password, %w", ErrWrongPassword)
+	}
+	newCredentials, err := domain.NewCredentials(user.Name, newPassword)
+	if err != nil {
+		return fmt.Errorf("Couldn't change password, %w", err)
+	}
+	err = m.repository.SetCredentials(newCredentials)
+	if err != nil {
+		return fmt.Errorf("Couldn't change password, %w", err)
 	}
-	m.loggedInUser = domain.User{}
+	m.sessions.RevokeUser(user.Name, token)
 	return nil
 }
 
-//isLoggedIn checks if there is a logged in user
-func (m *TweetManager) isLoggedIn() bool {
-	return m.loggedInUser.Name != ""
+//GetLoggedInUser returns the user logged in with a session
+func (m *TweetManager) GetLoggedInUser(token string) (*domain.User, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.loggedInUser(token)
 }
 
-//GetTweet returns the last published Tweet
+func (m *TweetManager) loggedInUser(token string) (*domain.User, error) {
+	session, err := m.sessions.Get(token)
+	if err != nil {
+		return nil, err
+	}
+	return m.repository.GetUserByName(session.User.Name)
+}
+
+//Logout ends a session
+func (m *TweetManager) Logout(token string) error {
+	return m.sessions.Revoke(token)
+}
+
+//GetTweet returns the last published Tweet, if it can be seen without logging in
 func (m *TweetManager) GetTweet() (domain.Tweeter, error) {
-	tw, err := m.GetTweetByID(domain.GetCurrentID())
-	return tw, err
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.tweetVisibleTo(nil, m.lastID)
 }
 
-//GetTweetByID returns the tweet that has that ID
+//GetTweetByID returns the tweet that has that ID, if it can be seen without logging in
 func (m *TweetManager) GetTweetByID(id int) (domain.Tweeter, error) {
-	for _, tweets := range m.userTweets {
-		for _, tweet := range tweets {
-			if tweet.GetID() == id {
-				return tweet, nil
-			}
-		}
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.tweetVisibleTo(nil, id)
+}
+
+//GetVisibleTweetByID returns the tweet that has that ID, if the user logged in with a session can see it.
+//Without a valid session, it is the same as GetTweetByID
+func (m *TweetManager) GetVisibleTweetByID(token string, id int) (domain.Tweeter, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.tweetVisibleTo(m.viewer(token), id)
+}
+
+//tweetVisibleTo returns the tweet that has that ID, if viewer can see it
+func (m *TweetManager) tweetVisibleTo(viewer *domain.User, id int) (domain.Tweeter, error) {
+	tweet, err := m.repository.GetTweetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if m.hiddenFrom(viewer, nil)(tweet) {
+		return nil, ErrTweetNotFound
 	}
-	return nil, fmt.Errorf("A tweet with that ID does not exist")
+	return tweet, nil
 }
 
-//GetTweetsFromUser returns all tweets from one user
+//GetTweetsFromUser returns all tweets from one user, if they can be seen without logging in
 func (m *TweetManager) GetTweetsFromUser(user domain.User) ([]domain.Tweeter, error) {
-	if !m.IsRegistered(user) {
-		return nil, fmt.Errorf("That user is not registered")
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.tweetsVisibleTo(nil, user)
+}
+
+//GetVisibleTweetsFromUser returns the tweets from one user that the user logged in with a session can see.
+//Without a valid session, it is the same as GetTweetsFromUser
+func (m *TweetManager) GetVisibleTweetsFromUser(token string, user domain.User) ([]domain.Tweeter, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.tweetsVisibleTo(m.viewer(token), user)
+}
+
+//tweetsVisibleTo returns the tweets from one user that viewer can see
+func (m *TweetManager) tweetsVisibleTo(viewer *domain.User, user domain.User) ([]domain.Tweeter, error) {
+	if !m.isRegistered(user) {
+		return nil, ErrUserNotRegistered
+	}
+	if !m.canSee(viewer, user) {
+		return nil, ErrProtectedAccount
+	}
+	if viewer != nil && m.areBlocked(*viewer, user) {
+		return nil, ErrBlocked
 	}
+	tweets, err := m.repository.GetTweetsFromUser(user.Name)
+	if err != nil {
+		return nil, err
+	}
+	return withoutHidden(tweets, m.hiddenFrom(viewer, nil)), nil
+}
 
-	timeline := append(m.userTweets[user.Name])
-	return timeline, nil
+//viewer returns the user logged in with a session, or nil if the session isn't valid
+func (m *TweetManager) viewer(token string) *domain.User {
+	user, err := m.loggedInUser(token)
+	if err != nil {
+		return nil
+	}
+	return user
 }
 
-func (m *TweetManager) getTweetsFromFollowing(user domain.User) []domain.Tweeter {
-	var tweets []domain.Tweeter
-	for _, followedUser := range user.Following {
-		followedUserTweets, _ := m.GetTweetsFromUser(followedUser)
-		tweets = append(tweets, followedUserTweets...)
+//getTimelineAuthors returns the tweets of a user and of each user they follow, every list newest first.
+//The tweets hidden from the user by blocks and mutes are left out
+func (m *TweetManager) getTimelineAuthors(user domain.User) ([][]domain.Tweeter, error) {
+	stored, err := m.repository.GetUserByName(user.Name)
+	if err != nil {
+		return nil, ErrUserNotRegistered
 	}
-	return tweets
+
+	ownTweets, err := m.repository.GetTweetsFromUser(stored.Name)
+	if err != nil {
+		return nil, err
+	}
+	hidden := m.hiddenFrom(stored, m.repository.GetMuted(stored.ID))
+	authors := [][]domain.Tweeter{newestFirst(withoutHidden(ownTweets, hidden))}
+	for _, followed := range m.usersByID(m.repository.GetFollowing(stored.ID)) {
+		followedUserTweets, _ := m.repository.GetTweetsFromUser(followed.Name)
+		authors = append(authors, newestFirst(withoutHidden(followedUserTweets, hidden)))
+	}
+	return authors, nil
 }
 
-//GetTimelineFromUser returns all tweets from one user and who they are following
+//GetTimelineFromUser returns all tweets from one user and who they are following, newest first
 func (m *TweetManager) GetTimelineFromUser(user domain.User) ([]domain.Tweeter, error) {
-	if !m.IsRegistered(user) {
-		return nil, fmt.Errorf("That user is not registered")
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	authors, err := m.getTimelineAuthors(user)
+	if err != nil {
+		return nil, err
 	}
+	return mergeTimeline(authors), nil
+}
 
-	timeline := append(m.userTweets[user.Name], m.getTweetsFromFollowing(user)...)
-	return timeline, nil
+//GetTimeline returns the timeline of the user logged in with a session, newest first
+func (m *TweetManager) GetTimeline(token string) ([]domain.Tweeter, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	user, err := m.loggedInUser(token)
+	if err != nil {
+		return nil, ErrNoUserLoggedIn
+	}
+	authors, err := m.getTimelineAuthors(*user)
+	if err != nil {
+		return nil, err
+	}
+	return mergeTimeline(authors), nil
 }
 
-//GetTimeline returns the loggedInUser's timeline
-func (m *TweetManager) GetTimeline() ([]domain.Tweeter, error) {
-	if !m.isLoggedIn() {
-		return nil, fmt.Errorf("No user logged in")
+//GetTimelinePage returns a page of the timeline of the user logged in with a session
+func (m *TweetManager) GetTimelinePage(token string, request PageRequest) (TimelinePage, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	user, err := m.loggedInUser(token)
+	if err != nil {
+		return TimelinePage{}, ErrNoUserLoggedIn
 	}
-	return m.GetTimelineFromUser(m.loggedInUser)
+	authors, err := m.getTimelineAuthors(*user)
+	if err != nil {
+		return TimelinePage{}, err
+	}
+
+	var cursor domain.Tweeter
+	switch {
+	case request.HasBefore:
+		cursor, err = m.repository.GetTweetByID(request.Before)
+	case request.HasAfter:
+		cursor, err = m.repository.GetTweetByID(request.After)
+	}
+	if err != nil {
+		return TimelinePage{}, fmt.Errorf("Couldn't retrieve timeline, %w", err)
+	}
+	return pageTimeline(authors, request, cursor), nil
 }
 
-//PublishTweet Publishes a tweet
-func (m *TweetManager) PublishTweet(tweetToPublish domain.Tweeter) error {
-	if !m.loggedInUser.Equals(tweetToPublish.GetUser()) {
-		return fmt.Errorf("You must be logged in to tweet")
+//PublishTweet Publishes a tweet of the user logged in with a session
+func (m *TweetManager) PublishTweet(token string, tweetToPublish domain.Tweeter) error {
+	defer m.publishEvents()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	user, err := m.loggedInUser(token)
+	if err != nil || !user.Equals(tweetToPublish.GetUser()) {
+		return ErrMustBeLoggedInToTweet
+	}
+	return m.publishTweet(tweetToPublish)
+}
+
+func (m *TweetManager) publishTweet(tweetToPublish domain.Tweeter) error {
+	user := tweetToPublish.GetUser()
+	if parentID, ok := domain.GetParentID(tweetToPublish); ok {
+		if _, err := m.visibleTweet(user, parentID); err != nil {
+			return fmt.Errorf("Couldn't reply, %w", err)
+		}
+	}
+	if quote, ok := tweetToPublish.(*domain.QuoteTweet); ok {
+		if _, err := m.visibleTweet(user, quote.GetQuotedID()); err != nil {
+			return fmt.Errorf("Couldn't quote, %w", err)
+		}
 	}
-	m.userTweets[tweetToPublish.GetUser().Name] = append(m.userTweets[tweetToPublish.GetUser().Name], tweetToPublish)
+	if retweet, ok := tweetToPublish.(*domain.Retweet); ok {
+		if err := m.checkRetweet(user, retweet.GetRetweeted().GetID()); err != nil {
+			return fmt.Errorf("Couldn't retweet, %w", err)
+		}
+	} else if err := m.checkMentions(user, tweetToPublish.GetText()); err != nil {
+		return err
+	}
+	err := m.repository.AddTweet(tweetToPublish)
+	if err != nil {
+		return err
+	}
+	m.lastID = tweetToPublish.GetID()
+	m.notifyPublished(tweetToPublish)
+	m.emit(events.TweetPublished{Tweet: tweetToPublish})
 	return nil
 }
 
-//DeleteTweetByID deletes a tweet by its ID
-func (m *TweetManager) DeleteTweetByID(id int) error {
-	tweet, err := m.GetTweetByID(id)
+//ReplyToTweet publishes a reply of the user logged in with a session to the tweet with that ID
+func (m *TweetManager) ReplyToTweet(token string, id int, text string) (*domain.ReplyTweet, error) {
+	defer m.publishEvents()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	user, err := m.loggedInUser(token)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't reply, %w", ErrMustBeLoggedInToTweet)
+	}
+	parent, err := m.visibleTweet(*user, id)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't reply, %w", err)
+	}
+	reply, err := m.tweets.NewReplyTweet(*user, text, parent)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't reply, %w", err)
+	}
+	err = m.publishTweet(reply)
+	if err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+//GetThread returns the whole conversation that the tweet with that ID is part of, starting from the tweet
+//that began it, as the user logged in with a session sees it. The tweets hidden from them show up as
+//tombstones that don't tell who wrote them, and the tweet with that ID can't be hidden.
+//Without a valid session, it is the conversation as seen without logging in
+func (m *TweetManager) GetThread(token string, id int) (*ThreadNode, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	hidden := m.hiddenFrom(m.viewer(token), nil)
+	if tweet, err := m.repository.GetTweetByID(id); err == nil && hidden(tweet) {
+		return nil, fmt.Errorf("Couldn't retrieve thread, %w", ErrTweetNotFound)
+	}
+	thread, err := buildThread(m.repository, id)
+	if err != nil {
+		return nil, err
+	}
+	thread.hide(hidden)
+	return thread, nil
+}
+
+//DeleteTweetByID deletes a tweet by its ID, if it was published by the user logged in with a session
+func (m *TweetManager) DeleteTweetByID(token string, id int) error {
+	defer m.publishEvents()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	tweet, err := m.repository.GetTweetByID(id)
 	if err != nil {
-		return fmt.Errorf("Coudln't delete tweet, %s", err.Error())
+		return fmt.Errorf("Coudln't delete tweet, %w", err)
 	}
-	user, err := m.GetLoggedInUser()
+	user, err := m.loggedInUser(token)
 	if err != nil {
-		return fmt.Errorf("Coudln't delete tweet, %s", err.Error())
+		return fmt.Errorf("Coudln't delete tweet, %w", err)
 	}
 
 	if !tweet.GetUser().Equals(*user) {
-		return fmt.Errorf("You can't delete a tweet that you didn't publish")
+		return ErrCantDeleteOthersTweet
 	}
 	return m.deleteTweet(tweet)
 }
 
 //DeleteTweet deletes a tweet
 func (m *TweetManager) deleteTweet(tweet domain.Tweeter) error {
-	tweets := m.userTweets[tweet.GetUser().Name]
-	tweets = m.deleteElementFromTweets(tweets, tweet)
-	m.userTweets[tweet.GetUser().Name] = tweets
-	return nil
-}
-
-func (m *TweetManager) deleteElementFromTweets(slice []domain.Tweeter, element domain.Tweeter) []domain.Tweeter {
-	var newTweets []domain.Tweeter
-	for _, tweet := range slice {
-		if !tweet.Equals(element) {
-			newTweets = append(newTweets, tweet)
-		}
+	err := m.repository.DeleteTweet(tweet)
+	if err != nil {
+		return err
 	}
-	return newTweets
+	m.emit(events.TweetDeleted{Tweet: tweet})
+	return nil
 }
 
 func (m *TweetManager) tweetAppearsByCriteria(tweet domain.Tweeter, criteria func(domain.Tweeter, domain.Tweeter) bool) bool {
-	for _, tweets := range m.userTweets {
-		for _, tw := range tweets {
-			if criteria(tw, tweet) {
-				return true
-			}
+	for _, tw := range m.repository.GetTweets() {
+		if criteria(tw, tweet) {
+			return true
 		}
 	}
 	return false
@@ -204,65 +543,724 @@ func isEqualToCriteria(t1, t2 domain.Tweeter) bool {
 
 //TweetExists returns if a given tweet exists
 func (m *TweetManager) TweetExists(tweet domain.Tweeter) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
 	return m.tweetAppearsByCriteria(tweet, isEqualToCriteria)
 }
 
-//EditTweetTextByID edits a given tweet by its ID
-func (m *TweetManager) EditTweetTextByID(id int, newText string) error {
-	tweet, err := m.GetTweetByID(id)
+//EditTweetTextByID edits a given tweet by its ID, if it was published by the user logged in with a session
+func (m *TweetManager) EditTweetTextByID(token string, id int, newText string) error {
+	defer m.publishEvents()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	tweet, err := m.repository.GetTweetByID(id)
 	if err != nil {
-		return fmt.Errorf("Coudln't edit tweet, %s", err.Error())
+		return fmt.Errorf("Coudln't edit tweet, %w", err)
 	}
-	user, err := m.GetLoggedInUser()
+	user, err := m.loggedInUser(token)
 	if err != nil {
-		return fmt.Errorf("Coudln't edit tweet, %s", err.Error())
+		return fmt.Errorf("Coudln't edit tweet, %w", err)
 	}
 
 	if !tweet.GetUser().Equals(*user) {
-		return fmt.Errorf("You can't edit a tweet that you didn't publish")
+		return ErrCantEditOthersTweet
 	}
 	return m.editTweetText(tweet, newText)
 }
 
+//editTweetText changes the text of a stored tweet. If the repository can't store the change, the tweet
+//gets its old text back, so that it isn't changed without being stored
 func (m *TweetManager) editTweetText(t domain.Tweeter, text string) error {
-	err := t.SetText(text)
+	oldText := t.GetText()
+	err := m.checkMentions(t.GetUser(), text)
+	if err == nil {
+		err = m.tweets.SetText(t, text)
+	}
 	if err != nil {
-		return fmt.Errorf("Coudln't edit tweet, %s", err.Error())
+		return fmt.Errorf("Coudln't edit tweet, %w", err)
+	}
+	err = m.repository.UpdateTweet(t)
+	if err != nil {
+		domain.RestoreText(t, oldText)
+		return err
+	}
+	m.emit(events.TweetEdited{Tweet: t})
+	return nil
+}
+
+//checkMentions returns an error if a text of user mentions a user that is not registered,
+//or that blocked or was blocked by them
+func (m *TweetManager) checkMentions(user domain.User, text string) error {
+	for _, name := range domain.ExtractMentions(text) {
+		mentioned, err := m.repository.GetUserByName(name)
+		if err != nil {
+			return fmt.Errorf("Couldn't mention @%s, %w", name, ErrUserNotRegistered)
+		}
+		if m.areBlocked(user, *mentioned) {
+			return fmt.Errorf("Couldn't mention @%s, %w", name, ErrBlocked)
+		}
 	}
 	return nil
 }
 
-//FollowUser follows a user
-func (m *TweetManager) FollowUser(userName string) error {
-	user, err := m.GetLoggedInUser()
+//GetTweetsWithHashtag returns the public tweets that have a hashtag, newest first. The # and case of tag don't matter
+func (m *TweetManager) GetTweetsWithHashtag(tag string) []domain.Tweeter {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return newestFirst(withoutHidden(m.repository.GetTweetsWithHashtag(domain.NormalizeHashtag(tag)), m.hiddenFrom(nil, nil)))
+}
+
+//GetTweetsMentioning returns the public tweets that mention a user, newest first
+func (m *TweetManager) GetTweetsMentioning(user domain.User) ([]domain.Tweeter, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	if !m.isRegistered(user) {
+		return nil, ErrUserNotRegistered
+	}
+	return newestFirst(withoutHidden(m.repository.GetTweetsMentioning(user.Name), m.hiddenFrom(nil, nil))), nil
+}
+
+//GetMentions returns the tweets that mention the user logged in with a session, newest first.
+//The ones of users they blocked, muted or were blocked by are left out
+func (m *TweetManager) GetMentions(token string) ([]domain.Tweeter, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	user, err := m.loggedInUser(token)
+	if err != nil {
+		return nil, err
+	}
+	return newestFirst(withoutHidden(m.repository.GetTweetsMentioning(user.Name), m.hiddenFrom(user, m.repository.GetMuted(user.ID)))), nil
+}
+
+//Search returns the tweets that match a query, the most relevant first. See search.Query for how queries are written
+func (m *TweetManager) Search(text string) ([]domain.Tweeter, error) {
+	query, err := search.ParseQuery(text)
 	if err != nil {
-		return fmt.Errorf("Coudln't follow user, %s", err.Error())
+		return nil, fmt.Errorf("Couldn't search, %w", err)
+	}
+	return m.SearchTweets(query), nil
+}
+
+//SearchTweets returns the public tweets that match an already parsed query, the most relevant first
+func (m *TweetManager) SearchTweets(query search.Query) []domain.Tweeter {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return withoutHidden(m.repository.SearchTweets(query), m.hiddenFrom(nil, nil))
+}
+
+//FollowUser makes the user logged in with a session follow another user.
+//If that user is protected, it only asks them to approve the follow
+func (m *TweetManager) FollowUser(token string, userName string) error {
+	defer m.publishEvents()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	user, err := m.loggedInUser(token)
+	if err != nil {
+		return fmt.Errorf("Coudln't follow user, %w", err)
 	}
 	userToFollow, err := m.getUserByName(userName)
 
 	if err != nil {
-		return fmt.Errorf("Couldn't follow user, %s", err.Error())
+		return fmt.Errorf("Couldn't follow user, %w", err)
 	}
 	if user.Equals(*userToFollow) {
-		return fmt.Errorf("Can't follow yourself")
+		return ErrCantFollowYourself
+	}
+	if userToFollow.Protected {
+		err = m.repository.AddFollowRequest(user.ID, userToFollow.ID)
+	} else {
+		err = m.repository.AddFollow(user.ID, userToFollow.ID)
+	}
+	if errors.Is(err, ErrBlocked) || errors.Is(err, ErrAlreadyRequested) {
+		return fmt.Errorf("Couldn't follow user, %w", err)
+	}
+	if err == nil && !userToFollow.Protected {
+		m.notify(domain.FollowNotification, *user, *userToFollow, 0)
+		m.emit(events.UserFollowed{Follower: user.Public(), Followed: userToFollow.Public()})
+	}
+	return err
+}
+
+//SetProtected changes if the user logged in with a session is protected. When they stop being
+//protected, everyone that asked to follow them is approved
+func (m *TweetManager) SetProtected(token string, protected bool) error {
+	defer m.publishEvents()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	user, err := m.loggedInUser(token)
+	if err != nil {
+		return fmt.Errorf("Couldn't change protection, %w", err)
+	}
+	err = m.repository.SetProtected(user.ID, protected)
+	if err != nil {
+		return fmt.Errorf("Couldn't change protection, %w", err)
 	}
-	if user.IsFollowing(*userToFollow) {
-		return fmt.Errorf("Can't follow same user twice")
+	if protected {
+		return nil
+	}
+	for _, requester := range m.repository.GetFollowRequests(user.ID) {
+		err = m.approveFollowRequest(requester, user.ID)
+		if err != nil {
+			return fmt.Errorf("Couldn't change protection, %w", err)
+		}
 	}
-	user.Follow(*userToFollow)
 	return nil
 }
 
+//GetFollowRequests returns the users that asked to follow the user logged in with a session, in the order they asked
+func (m *TweetManager) GetFollowRequests(token string) ([]domain.User, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	user, err := m.loggedInUser(token)
+	if err != nil {
+		return nil, err
+	}
+	return m.usersByID(m.repository.GetFollowRequests(user.ID)), nil
+}
+
+//ApproveFollowRequest makes the user that asked to follow the user logged in with a session follow them.
+//Both of them are notified
+func (m *TweetManager) ApproveFollowRequest(token string, userName string) error {
+	return m.changeRelation(token, userName, "approve", func(owner, requester int) error {
+		return m.approveFollowRequest(requester, owner)
+	})
+}
+
+func (m *TweetManager) approveFollowRequest(requester, owner int) error {
+	err := m.repository.RemoveFollowRequest(requester, owner)
+	if err == nil {
+		err = m.repository.AddFollow(requester, owner)
+	}
+	if err != nil {
+		return err
+	}
+	follower, followerErr := m.repository.GetUserByID(requester)
+	followed, followedErr := m.repository.GetUserByID(owner)
+	if followerErr == nil && followedErr == nil {
+		m.notify(domain.FollowNotification, *follower, *followed, 0)
+		m.notify(domain.ApproveNotification, *followed, *follower, 0)
+		m.emit(events.UserFollowed{Follower: follower.Public(), Followed: followed.Public()})
+	}
+	return nil
+}
+
+//RejectFollowRequest forgets that a user asked to follow the user logged in with a session
+func (m *TweetManager) RejectFollowRequest(token string, userName string) error {
+	return m.changeRelation(token, userName, "reject", func(owner, requester int) error {
+		return m.repository.RemoveFollowRequest(requester, owner)
+	})
+}
+
+//UnfollowUser makes the user logged in with a session stop following another user,
+//or stop asking to follow them if they are protected
+func (m *TweetManager) UnfollowUser(token string, userName string) error {
+	return m.changeRelation(token, userName, "unfollow", func(follower, followed int) error {
+		err := m.repository.RemoveFollow(follower, followed)
+		if errors.Is(err, ErrNotFollowing) && m.repository.RemoveFollowRequest(follower, followed) == nil {
+			return nil
+		}
+		return err
+	})
+}
+
+//GetFollowers returns the users that follow a user, in the order they followed it
+func (m *TweetManager) GetFollowers(user domain.User) ([]domain.User, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	stored, err := m.repository.GetUserByName(user.Name)
+	if err != nil {
+		return nil, ErrUserNotRegistered
+	}
+	return m.usersByID(m.repository.GetFollowers(stored.ID)), nil
+}
+
+//GetFollowing returns the users that a user follows, in the order it followed them
+func (m *TweetManager) GetFollowing(user domain.User) ([]domain.User, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	stored, err := m.repository.GetUserByName(user.Name)
+	if err != nil {
+		return nil, ErrUserNotRegistered
+	}
+	return m.usersByID(m.repository.GetFollowing(stored.ID)), nil
+}
+
+//GetMutuals returns the users that a user follows and that follow it back
+func (m *TweetManager) GetMutuals(user domain.User) ([]domain.User, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	stored, err := m.repository.GetUserByName(user.Name)
+	if err != nil {
+		return nil, ErrUserNotRegistered
+	}
+	return m.usersByID(m.repository.GetMutuals(stored.ID)), nil
+}
+
+//GetFollowCounts returns how many followers a user has and how many users it follows
+func (m *TweetManager) GetFollowCounts(user domain.User) (FollowCounts, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	stored, err := m.repository.GetUserByName(user.Name)
+	if err != nil {
+		return FollowCounts{}, ErrUserNotRegistered
+	}
+	return m.repository.GetFollowCounts(stored.ID), nil
+}
+
+//BlockUser makes the user logged in with a session block another user. Users that blocked each other
+//don't follow each other, can't interact with the tweets of the other and don't see them in their timelines
+func (m *TweetManager) BlockUser(token string, userName string) error {
+	return m.changeRelation(token, userName, "block", m.repository.AddBlock)
+}
+
+//UnblockUser makes the user logged in with a session stop blocking another user
+func (m *TweetManager) UnblockUser(token string, userName string) error {
+	return m.changeRelation(token, userName, "unblock", m.repository.RemoveBlock)
+}
+
+//MuteUser hides the tweets of another user from the timeline of the user logged in with a session,
+//without the other user knowing about it
+func (m *TweetManager) MuteUser(token string, userName string) error {
+	return m.changeRelation(token, userName, "mute", m.repository.AddMute)
+}
+
+//UnmuteUser shows again the tweets of a muted user in the timeline of the user logged in with a session
+func (m *TweetManager) UnmuteUser(token string, userName string) error {
+	return m.changeRelation(token, userName, "unmute", m.repository.RemoveMute)
+}
+
+//changeRelation makes a change between the user logged in with a session and another user,
+//describing it as action if it fails
+func (m *TweetManager) changeRelation(token string, userName string, action string, change func(int, int) error) error {
+	defer m.publishEvents()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	user, err := m.loggedInUser(token)
+	if err != nil {
+		return fmt.Errorf("Couldn't %s user, %w", action, err)
+	}
+	other, err := m.getUserByName(userName)
+	if err != nil {
+		return fmt.Errorf("Couldn't %s user, %w", action, err)
+	}
+	err = change(user.ID, other.ID)
+	if err != nil {
+		return fmt.Errorf("Couldn't %s user, %w", action, err)
+	}
+	return nil
+}
+
+//GetBlockedUsers returns the users blocked by the user logged in with a session, in the order they were blocked
+func (m *TweetManager) GetBlockedUsers(token string) ([]domain.User, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	user, err := m.loggedInUser(token)
+	if err != nil {
+		return nil, err
+	}
+	return m.usersByID(m.repository.GetBlocked(user.ID)), nil
+}
+
+//GetMutedUsers returns the users muted by the user logged in with a session, in the order they were muted
+func (m *TweetManager) GetMutedUsers(token string) ([]domain.User, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	user, err := m.loggedInUser(token)
+	if err != nil {
+		return nil, err
+	}
+	return m.usersByID(m.repository.GetMuted(user.ID)), nil
+}
+
+//usersByID returns the public stored users with the given IDs, skipping the ones that don't exist
+func (m *TweetManager) usersByID(ids []int) []domain.User {
+	users := make([]domain.User, 0, len(ids))
+	for _, id := range ids {
+		user, err := m.repository.GetUserByID(id)
+		if err != nil {
+			continue
+		}
+		public := user.Public()
+		public.Following = nil
+		users = append(users, public)
+	}
+	return users
+}
+
+//GetUserByName returns the registered user that has that name
+func (m *TweetManager) GetUserByName(name string) (*domain.User, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.getUserByName(name)
+}
+
+//GetUserByID returns the registered user that has that ID
+func (m *TweetManager) GetUserByID(id int) (*domain.User, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.repository.GetUserByID(id)
+}
+
+//AreBlocked returns if any of two users blocked the other
+func (m *TweetManager) AreBlocked(first, second domain.User) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.areBlocked(first, second)
+}
+
 func (m *TweetManager) getUserByName(name string) (*domain.User, error) {
-	for _, user := range m.users {
-		if user.Name == name {
-			return &user, nil
+	return m.repository.GetUserByName(name)
+}
+
+//QuoteTweet publishes a tweet of the user logged in with a session that quotes the tweet with that ID
+func (m *TweetManager) QuoteTweet(token string, id int, text string) (*domain.QuoteTweet, error) {
+	defer m.publishEvents()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	user, err := m.loggedInUser(token)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't quote, %w", ErrMustBeLoggedInToTweet)
+	}
+	quoted, err := m.visibleTweet(*user, id)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't quote, %w", err)
+	}
+	quote, err := m.tweets.NewQuoteTweet(*user, text, quoted)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't quote, %w", err)
+	}
+	err = m.publishTweet(quote)
+	if err != nil {
+		return nil, err
+	}
+	return quote, nil
+}
+
+//Retweet shares again the tweet with that ID as the user logged in with a session
+func (m *TweetManager) Retweet(token string, id int) (*domain.Retweet, error) {
+	defer m.publishEvents()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	user, err := m.loggedInUser(token)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't retweet, %w", ErrMustBeLoggedInToTweet)
+	}
+	retweeted, err := m.visibleTweet(*user, id)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't retweet, %w", err)
+	}
+	retweet := m.tweets.NewRetweet(*user, retweeted)
+	err = m.publishTweet(retweet)
+	if err != nil {
+		return nil, err
+	}
+	return retweet, nil
+}
+
+//checkRetweet returns why user can't retweet the tweet with that ID, if they can't
+func (m *TweetManager) checkRetweet(user domain.User, id int) error {
+	retweeted, err := m.visibleTweet(user, id)
+	if err != nil {
+		return err
+	}
+	if retweeted.GetUser().Equals(user) {
+		return ErrCantRetweetOwnTweet
+	}
+	if _, err := m.findRetweet(user, id); err == nil {
+		return ErrAlreadyRetweeted
+	}
+	return nil
+}
+
+//findRetweet returns the retweet that user made of the tweet with that ID
+func (m *TweetManager) findRetweet(user domain.User, id int) (*domain.Retweet, error) {
+	tweets, err := m.repository.GetTweetsFromUser(user.Name)
+	if err != nil {
+		return nil, err
+	}
+	for _, tweet := range tweets {
+		if retweet, ok := tweet.(*domain.Retweet); ok && retweet.GetRetweeted().GetID() == id {
+			return retweet, nil
+		}
+	}
+	return nil, ErrNotRetweeted
+}
+
+//Unretweet undoes the retweet that the user logged in with a session made of the tweet with that ID
+func (m *TweetManager) Unretweet(token string, id int) error {
+	defer m.publishEvents()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	user, err := m.loggedInUser(token)
+	if err != nil {
+		return fmt.Errorf("Couldn't undo retweet, %w", err)
+	}
+	retweeted, err := m.originalTweet(id)
+	if err != nil {
+		return fmt.Errorf("Couldn't undo retweet, %w", err)
+	}
+	retweet, err := m.findRetweet(*user, retweeted.GetID())
+	if err != nil {
+		return fmt.Errorf("Couldn't undo retweet, %w", err)
+	}
+	return m.deleteTweet(retweet)
+}
+
+//GetRetweetCount returns how many times the tweet with that ID was retweeted, if the user logged in with
+//a session can see it. Without a valid session, the tweet has to be one that can be seen without logging in
+func (m *TweetManager) GetRetweetCount(token string, id int) (int, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	tweet, err := m.tweetVisibleTo(m.viewer(token), id)
+	if err != nil {
+		return 0, err
+	}
+	return tweet.GetCounters().Retweets, nil
+}
+
+//LikeTweet makes the user logged in with a session like the tweet with that ID
+func (m *TweetManager) LikeTweet(token string, id int) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	user, err := m.loggedInUser(token)
+	if err != nil {
+		return fmt.Errorf("Couldn't like tweet, %w", err)
+	}
+	tweet, err := m.visibleTweet(*user, id)
+	if err != nil {
+		return fmt.Errorf("Couldn't like tweet, %w", err)
+	}
+	err = m.repository.AddLike(user.Name, tweet.GetID())
+	if err != nil {
+		return fmt.Errorf("Couldn't like tweet, %w", err)
+	}
+	m.notify(domain.LikeNotification, *user, tweet.GetUser(), tweet.GetID())
+	return nil
+}
+
+//UnlikeTweet undoes the like of the user logged in with a session to the tweet with that ID
+func (m *TweetManager) UnlikeTweet(token string, id int) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	user, err := m.loggedInUser(token)
+	if err != nil {
+		return fmt.Errorf("Couldn't unlike tweet, %w", err)
+	}
+	tweet, err := m.originalTweet(id)
+	if err != nil {
+		return fmt.Errorf("Couldn't unlike tweet, %w", err)
+	}
+	err = m.repository.RemoveLike(user.Name, tweet.GetID())
+	if err != nil {
+		return fmt.Errorf("Couldn't unlike tweet, %w", err)
+	}
+	return nil
+}
+
+//GetLikes returns the users that liked the tweet with that ID, in the order they liked it, if the user logged in
+//with a session can see it. Without a valid session, the tweet has to be one that can be seen without logging in
+func (m *TweetManager) GetLikes(token string, id int) ([]domain.User, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	tweet, err := m.tweetVisibleTo(m.viewer(token), id)
+	if err != nil {
+		return nil, err
+	}
+	var users []domain.User
+	for _, name := range m.repository.GetLikes(domain.GetOriginal(tweet).GetID()) {
+		user, err := m.repository.GetUserByName(name)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user.Public())
+	}
+	return users, nil
+}
+
+//GetLikedTweets returns the tweets that a user likes that the user logged in with a session can see,
+//the last one liked first. Without a valid session, they are the ones that can be seen without logging in
+func (m *TweetManager) GetLikedTweets(token string, user domain.User) ([]domain.Tweeter, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	viewer := m.viewer(token)
+	if !m.isRegistered(user) {
+		return nil, ErrUserNotRegistered
+	}
+	if !m.canSee(viewer, user) {
+		return nil, ErrProtectedAccount
+	}
+	if viewer != nil && m.areBlocked(*viewer, user) {
+		return nil, ErrBlocked
+	}
+	tweets, err := m.tweetsByID(m.repository.GetLikedTweets(user.Name))
+	if err != nil {
+		return nil, err
+	}
+	return withoutHidden(tweets, m.hiddenFrom(viewer, nil)), nil
+}
+
+//BookmarkTweet privately saves the tweet with that ID for the user logged in with a session
+func (m *TweetManager) BookmarkTweet(token string, id int) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	user, err := m.loggedInUser(token)
+	if err != nil {
+		return fmt.Errorf("Couldn't bookmark tweet, %w", err)
+	}
+	tweet, err := m.visibleTweet(*user, id)
+	if err != nil {
+		return fmt.Errorf("Couldn't bookmark tweet, %w", err)
+	}
+	err = m.repository.AddBookmark(user.Name, tweet.GetID())
+	if err != nil {
+		return fmt.Errorf("Couldn't bookmark tweet, %w", err)
+	}
+	return nil
+}
+
+//RemoveBookmark removes the tweet with that ID from the bookmarks of the user logged in with a session
+func (m *TweetManager) RemoveBookmark(token string, id int) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	user, err := m.loggedInUser(token)
+	if err != nil {
+		return fmt.Errorf("Couldn't remove bookmark, %w", err)
+	}
+	tweet, err := m.originalTweet(id)
+	if err != nil {
+		return fmt.Errorf("Couldn't remove bookmark, %w", err)
+	}
+	err = m.repository.RemoveBookmark(user.Name, tweet.GetID())
+	if err != nil {
+		return fmt.Errorf("Couldn't remove bookmark, %w", err)
+	}
+	return nil
+}
+
+//GetBookmarks returns the tweets bookmarked by the user logged in with a session that they can still see,
+//the last one bookmarked first
+func (m *TweetManager) GetBookmarks(token string) ([]domain.Tweeter, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	user, err := m.loggedInUser(token)
+	if err != nil {
+		return nil, err
+	}
+	tweets, err := m.tweetsByID(m.repository.GetBookmarks(user.Name))
+	if err != nil {
+		return nil, err
+	}
+	return withoutHidden(tweets, m.hiddenFrom(user, nil)), nil
+}
+
+//tweetsByID returns the stored tweets with the given IDs, in reverse order
+func (m *TweetManager) tweetsByID(ids []int) ([]domain.Tweeter, error) {
+	tweets := make([]domain.Tweeter, 0, len(ids))
+	for i := len(ids) - 1; i >= 0; i-- {
+		tweet, err := m.repository.GetTweetByID(ids[i])
+		if err != nil {
+			return nil, err
 		}
+		tweets = append(tweets, tweet)
+	}
+	return tweets, nil
+}
+
+//visibleTweet returns the tweet with that ID, if user can see it: users that blocked each other
+//can't see nor interact with the tweets of the other, and the tweets of protected users can only be
+//seen by their followers. Retweets are taken as the tweet they share
+func (m *TweetManager) visibleTweet(user domain.User, id int) (domain.Tweeter, error) {
+	tweet, err := m.originalTweet(id)
+	if err != nil {
+		return nil, err
+	}
+	if m.areBlocked(user, tweet.GetUser()) {
+		return nil, ErrBlocked
+	}
+	if !m.canSee(&user, tweet.GetUser()) {
+		return nil, ErrTweetNotFound
+	}
+	return tweet, nil
+}
+
+//originalTweet returns the tweet with that ID, or the one it shares if it is a retweet
+func (m *TweetManager) originalTweet(id int) (domain.Tweeter, error) {
+	tweet, err := m.repository.GetTweetByID(id)
+	if err != nil {
+		return nil, err
 	}
-	return nil, fmt.Errorf("User not registered")
+	return domain.GetOriginal(tweet), nil
 }
 
-//QuoteTweet returns a new tweet that quotes the given tweet
-func QuoteTweet() {
+//areBlocked returns if any of two users blocked the other
+func (m *TweetManager) areBlocked(first, second domain.User) bool {
+	storedFirst, err := m.repository.GetUserByName(first.Name)
+	if err != nil {
+		return false
+	}
+	storedSecond, err := m.repository.GetUserByName(second.Name)
+	if err != nil {
+		return false
+	}
+	return m.repository.AreBlocked(storedFirst.ID, storedSecond.ID)
+}
+
+//canSee returns if viewer can see the tweets of author: the ones of protected users can only be seen
+//by themselves and their followers. A nil viewer is someone who isn't logged in
+func (m *TweetManager) canSee(viewer *domain.User, author domain.User) bool {
+	storedAuthor, err := m.repository.GetUserByName(author.Name)
+	if err != nil || !storedAuthor.Protected {
+		return true
+	}
+	if viewer == nil {
+		return false
+	}
+	storedViewer, err := m.repository.GetUserByName(viewer.Name)
+	if err != nil {
+		return false
+	}
+	return storedViewer.ID == storedAuthor.ID || m.repository.IsFollowing(storedViewer.ID, storedAuthor.ID)
+}
 
+//hiddenFrom returns a function that tells if a tweet is hidden from a stored viewer: the ones of
+//protected users they can't see, of users that blocked or were blocked by them, and of the muted users.
+//Retweets and quotes are hidden too if the tweet they share is. A nil viewer is someone who isn't logged in
+func (m *TweetManager) hiddenFrom(viewer *domain.User, muted []int) func(domain.Tweeter) bool {
+	hiddenAuthors := make(map[string]bool)
+	isHiddenAuthor := func(author domain.User) bool {
+		hidden, ok := hiddenAuthors[author.Name]
+		if !ok {
+			hidden = !m.canSee(viewer, author)
+			if stored, err := m.repository.GetUserByName(author.Name); err == nil && viewer != nil {
+				hidden = hidden || m.repository.AreBlocked(viewer.ID, stored.ID) || containsID(muted, stored.ID)
+			}
+			hiddenAuthors[author.Name] = hidden
+		}
+		return hidden
+	}
+	var isHidden func(domain.Tweeter) bool
+	isHidden = func(tweet domain.Tweeter) bool {
+		if isHiddenAuthor(tweet.GetUser()) {
+			return true
+		}
+		if retweet, ok := tweet.(*domain.Retweet); ok {
+			return isHidden(retweet.GetRetweeted())
+		}
+		if quote, ok := tweet.(*domain.QuoteTweet); ok && quote.GetQuotedTweet() != nil {
+			return isHidden(quote.GetQuotedTweet())
+		}
+		return false
+	}
+	return isHidden
+}
+
+//withoutHidden returns the tweets for which hidden is false
+func withoutHidden(tweets []domain.Tweeter, hidden func(domain.Tweeter) bool) []domain.Tweeter {
+	shown := make([]domain.Tweeter, 0, len(tweets))
+	for _, tweet := range tweets {
+		if !hidden(tweet) {
+			shown = append(shown, tweet)
+		}
+	}
+	return shown
 }
@@ -1,13 +1,20 @@
 package service_test
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/cursoGo/src/domain"
 	"github.com/cursoGo/src/service"
 	"github.com/cursoGo/src/utility"
 )
 
+//Hashing passwords is slow on purpose, but tests don't need it to be
+func init() {
+	domain.PasswordHashIterations = 1000
+}
+
 //UTILITY FUNCTIONS
 
 func isValidTweet(t *testing.T, publishedTweet domain.Tweeter, user domain.User, text string) bool {
@@ -75,21 +82,122 @@ func TestCantRegisterSameUserMoreThanOnce(t *testing.T) {
 
 //LOGIN TESTS
 
-func TestCantLoginIfAlreadyLoggedIn(t *testing.T) {
+func TestCanLoginFromManyClientsAtOnce(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+
+	user := domain.NewUser("root", "root")
+	manager.Register(user)
+	token, _ := manager.Login(user)
+
+	//Operation
+	secondToken, err := manager.Login(user)
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if token == secondToken {
+		t.Error("Every login should get its own session")
+	}
+}
+
+func TestManyUsersCanBeLoggedInAtOnce(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	user := domain.NewUser("manu", "hunter2")
+	secondUser := domain.NewUser("gonza", "hunter3")
+	manager.Register(user)
+	manager.Register(secondUser)
+	token, _ := manager.Login(user)
+	secondToken, _ := manager.Login(secondUser)
+	tweet, _ := manager.NewTextTweet(user, "hola")
+	secondTweet, _ := manager.NewTextTweet(secondUser, "chau")
+
+	//Operation
+	err := manager.PublishTweet(token, tweet)
+	secondErr := manager.PublishTweet(secondToken, secondTweet)
+
+	//Validation
+	if err != nil || secondErr != nil {
+		t.Error("Both users should be able to tweet")
+	}
+}
+
+func TestCantPublishTweetOfAnotherUser(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	user := domain.NewUser("manu", "hunter2")
+	secondUser := domain.NewUser("gonza", "hunter3")
+	manager.Register(user)
+	manager.Register(secondUser)
+	token, _ := manager.Login(user)
+	tweet, _ := manager.NewTextTweet(secondUser, "hola")
+
+	//Operation
+	err := manager.PublishTweet(token, tweet)
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "You must be logged in to tweet")
+}
+
+func TestCantUseSessionAfterLogout(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	user := domain.NewUser("root", "root")
+	manager.Register(user)
+	token, _ := manager.Login(user)
+	secondToken, _ := manager.Login(user)
+
+	//Operation
+	manager.Logout(token)
+
+	//Validation
+	_, err := manager.GetLoggedInUser(token)
+	utility.ValidateExpectedError(t, err, "Not logged in")
+	if _, err := manager.GetLoggedInUser(secondToken); err != nil {
+		t.Error("Other sessions of the user should still be valid")
+	}
+}
+
+func TestCantUseExpiredSession(t *testing.T) {
 	//Initialization
 	var manager service.TweetManager
 	manager.InitializeManager()
+	manager.SetSessionDuration(time.Millisecond)
+	user := domain.NewUser("root", "root")
+	manager.Register(user)
+	token, _ := manager.Login(user)
 
+	//Operation
+	time.Sleep(2 * time.Millisecond)
+
+	//Validation
+	_, err := manager.GetLoggedInUser(token)
+	utility.ValidateExpectedError(t, err, "Session expired")
+}
+
+func TestCantLogoutTwice(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
 	user := domain.NewUser("root", "root")
 	manager.Register(user)
-	manager.Login(user)
+	token, _ := manager.Login(user)
+	manager.Logout(token)
 
 	//Operation
-	err := manager.Login(user)
+	err := manager.Logout(token)
 
 	//Validation
-	utility.ValidateExpectedError(t, err, "Already logged in")
+	utility.ValidateExpectedError(t, err, "Not logged in")
 }
+
 func TestCantLogInWithUnregisteredUser(t *testing.T) {
 	//Initialization
 	var manager service.TweetManager
@@ -97,7 +205,7 @@ func TestCantLogInWithUnregisteredUser(t *testing.T) {
 	user := domain.NewUser("root", "root")
 
 	//Operation
-	err := manager.Login(user)
+	_, err := manager.Login(user)
 
 	//Validation
 	utility.ValidateExpectedError(t, err, "The user is not registered")
@@ -110,10 +218,10 @@ func TestCanGetLoggedInUser(t *testing.T) {
 	manager.InitializeManager()
 	user := domain.NewUser("root", "root")
 	manager.Register(user)
-	manager.Login(user)
+	token, _ := manager.Login(user)
 
 	//Operation
-	loggedInUser, _ := manager.GetLoggedInUser()
+	loggedInUser, _ := manager.GetLoggedInUser(token)
 	//Validate
 	if user.Name != loggedInUser.Name {
 		t.Error("The loggedInUser and the user that logged in do not match")
@@ -125,7 +233,7 @@ func TestCantGetLoggedInUserIfNoOneLoggedIn(t *testing.T) {
 	var manager service.TweetManager
 	manager.InitializeManager()
 	//Operation
-	_, err := manager.GetLoggedInUser()
+	_, err := manager.GetLoggedInUser("")
 	//Validate
 	utility.ValidateExpectedError(t, err, "Not logged in")
 }
@@ -138,7 +246,7 @@ func TestCantLogInWithIncorrectPassword(t *testing.T) {
 	incorrectUser := domain.NewUser("root", "incorrectPassword")
 	manager.Register(user)
 	//Operation
-	err := manager.Login(incorrectUser)
+	_, err := manager.Login(incorrectUser)
 	//Validation
 	utility.ValidateExpectedError(t, err, "The user is not registered")
 }
@@ -151,14 +259,14 @@ func TestPublishedTweetIsSaved(t *testing.T) {
 
 	user := domain.NewUser("root", "root")
 	manager.Register(user)
-	manager.Login(user)
+	token, _ := manager.Login(user)
 	text := "This is my first tweet"
-	tweet, _ := domain.NewTextTweet(user, text)
+	tweet, _ := manager.NewTextTweet(user, text)
 	//Operation
-	err := manager.PublishTweet(tweet)
+	err := manager.PublishTweet(token, tweet)
 
 	if err != nil {
-		t.Errorf(err.Error())
+		t.Error(err.Error())
 	}
 
 	//Validation
@@ -175,9 +283,9 @@ func TestMustBeLoggedInToPublishTweet(t *testing.T) {
 	manager.Register(user)
 
 	text := "This is my first tweet"
-	tweet, _ := domain.NewTextTweet(user, text)
+	tweet, _ := manager.NewTextTweet(user, text)
 	//Operation
-	err := manager.PublishTweet(tweet)
+	err := manager.PublishTweet("", tweet)
 	utility.ValidateExpectedError(t, err, "You must be logged in to tweet")
 
 }
@@ -190,16 +298,16 @@ func TestCanPublishAndRetriveMoreThanOneTweet(t *testing.T) {
 
 	user := domain.NewUser("root", "root")
 	manager.Register(user)
-	manager.Login(user)
+	token, _ := manager.Login(user)
 	text := "This is my first tweet"
 	secondText := "This is my second tweet"
 
-	tweet, _ := domain.NewTextTweet(user, text)
-	secondTweet, _ := domain.NewTextTweet(user, secondText)
+	tweet, _ := manager.NewTextTweet(user, text)
+	secondTweet, _ := manager.NewTextTweet(user, secondText)
 
 	//Operation
-	manager.PublishTweet(tweet)
-	manager.PublishTweet(secondTweet)
+	manager.PublishTweet(token, tweet)
+	manager.PublishTweet(token, secondTweet)
 
 	//Validation
 	publishedTweets, _ := manager.GetTweetsFromUser(user)
@@ -234,20 +342,20 @@ func TestCanRetrieveTimeline(t *testing.T) {
 	secondText := "This is my second tweet"
 	thirdText := "This is a tweet"
 
-	tweet, _ := domain.NewTextTweet(user, text)
-	secondTweet, _ := domain.NewTextTweet(user, secondText)
-	thirdTweet, _ := domain.NewTextTweet(secondUser, thirdText)
+	tweet, _ := manager.NewTextTweet(user, text)
+	secondTweet, _ := manager.NewTextTweet(user, secondText)
+	thirdTweet, _ := manager.NewTextTweet(secondUser, thirdText)
 
-	manager.Login(secondUser)
-	manager.PublishTweet(thirdTweet)
-	manager.Logout()
+	token, _ := manager.Login(secondUser)
+	manager.PublishTweet(token, thirdTweet)
+	manager.Logout(token)
 
-	manager.Login(user)
-	manager.PublishTweet(tweet)
-	manager.PublishTweet(secondTweet)
+	token, _ = manager.Login(user)
+	manager.PublishTweet(token, tweet)
+	manager.PublishTweet(token, secondTweet)
 
 	//Operation
-	publishedTweets, _ := manager.GetTimeline()
+	publishedTweets, _ := manager.GetTimeline(token)
 
 	//Validation
 	if len(publishedTweets) != 2 {
@@ -270,16 +378,16 @@ func TestCantRetrieveTimelineWithoutLoggingIn(t *testing.T) {
 
 	user := domain.NewUser("root", "root")
 	manager.Register(user)
-	manager.Login(user)
+	token, _ := manager.Login(user)
 
 	text := "This is my first tweet"
-	tweet, _ := domain.NewTextTweet(user, text)
+	tweet, _ := manager.NewTextTweet(user, text)
 
-	manager.PublishTweet(tweet)
-	manager.Logout()
+	manager.PublishTweet(token, tweet)
+	manager.Logout(token)
 
 	//Operation
-	_, err := manager.GetTimeline()
+	_, err := manager.GetTimeline(token)
 
 	//Validation
 	utility.ValidateExpectedError(t, err, "No user logged in")
@@ -333,26 +441,26 @@ func TestCanRetrieveTimelineWithFollowedUsersTweets(t *testing.T) {
 	thirdText := "This is a tweet"
 	fourthText := "This should not be picked up"
 
-	tweet, _ := domain.NewTextTweet(user, text)
-	secondTweet, _ := domain.NewTextTweet(user, secondText)
-	thirdTweet, _ := domain.NewTextTweet(secondUser, thirdText)
-	fourthTweet, _ := domain.NewTextTweet(otherUser, fourthText)
+	tweet, _ := manager.NewTextTweet(user, text)
+	secondTweet, _ := manager.NewTextTweet(user, secondText)
+	thirdTweet, _ := manager.NewTextTweet(secondUser, thirdText)
+	fourthTweet, _ := manager.NewTextTweet(otherUser, fourthText)
 
-	manager.Login(otherUser)
-	manager.PublishTweet(fourthTweet)
-	manager.Logout()
+	token, _ := manager.Login(otherUser)
+	manager.PublishTweet(token, fourthTweet)
+	manager.Logout(token)
 
-	manager.Login(secondUser)
-	manager.PublishTweet(thirdTweet)
-	manager.Logout()
+	token, _ = manager.Login(secondUser)
+	manager.PublishTweet(token, thirdTweet)
+	manager.Logout(token)
 
-	manager.Login(user)
-	manager.PublishTweet(tweet)
-	manager.PublishTweet(secondTweet)
-	manager.FollowUser(secondUser.Name)
+	token, _ = manager.Login(user)
+	manager.PublishTweet(token, tweet)
+	manager.PublishTweet(token, secondTweet)
+	manager.FollowUser(token, secondUser.Name)
 
 	//Operation
-	publishedTweets, _ := manager.GetTimeline()
+	publishedTweets, _ := manager.GetTimeline(token)
 
 	//Validation
 	if len(publishedTweets) != 3 {
@@ -376,13 +484,13 @@ func TestCanRetrieveTweetById(t *testing.T) {
 
 	user := domain.NewUser("root", "root")
 	manager.Register(user)
-	manager.Login(user)
+	token, _ := manager.Login(user)
 
 	text := "This is my first tweet"
 
-	tweet, _ := domain.NewTextTweet(user, text)
+	tweet, _ := manager.NewTextTweet(user, text)
 	//Operations
-	manager.PublishTweet(tweet)
+	manager.PublishTweet(token, tweet)
 
 	//Validation
 	publishedTweet, err := manager.GetTweetByID(0)
@@ -399,13 +507,13 @@ func TestCantRetrieveTweetByNonExistentID(t *testing.T) {
 
 	user := domain.NewUser("root", "root")
 	manager.Register(user)
-	manager.Login(user)
+	token, _ := manager.Login(user)
 
 	text := "This is my first tweet"
 
-	tweet, _ := domain.NewTextTweet(user, text)
+	tweet, _ := manager.NewTextTweet(user, text)
 	//Operations
-	err := manager.PublishTweet(tweet)
+	err := manager.PublishTweet(token, tweet)
 	_, err = manager.GetTweetByID(5)
 
 	utility.ValidateExpectedError(t, err, "A tweet with that ID does not exist")
@@ -418,9 +526,9 @@ func TestCanCheckIfTweetExists(t *testing.T) {
 	manager.InitializeManager()
 	user := domain.NewUser("root", "root")
 	manager.Register(user)
-	manager.Login(user)
-	tweet, _ := domain.NewTextTweet(user, "hola soy root")
-	manager.PublishTweet(tweet)
+	token, _ := manager.Login(user)
+	tweet, _ := manager.NewTextTweet(user, "hola soy root")
+	manager.PublishTweet(token, tweet)
 	//Operation
 	exists := manager.TweetExists(tweet)
 	//Validation
@@ -437,13 +545,13 @@ func TestCanDeleteTweet(t *testing.T) {
 	manager.InitializeManager()
 	user := domain.NewUser("root", "root")
 	manager.Register(user)
-	manager.Login(user)
-	tweet, _ := domain.NewTextTweet(user, "Tweet 1")
-	tweet2, _ := domain.NewTextTweet(user, "Tweet 2")
-	manager.PublishTweet(tweet)
-	manager.PublishTweet(tweet2)
+	token, _ := manager.Login(user)
+	tweet, _ := manager.NewTextTweet(user, "Tweet 1")
+	tweet2, _ := manager.NewTextTweet(user, "Tweet 2")
+	manager.PublishTweet(token, tweet)
+	manager.PublishTweet(token, tweet2)
 	//Operation
-	manager.DeleteTweetByID(tweet.GetID())
+	manager.DeleteTweetByID(token, tweet.GetID())
 	//Validation
 	exists := manager.TweetExists(tweet)
 	if exists {
@@ -458,7 +566,7 @@ func TestCantDeleteNonExistentTweet(t *testing.T) {
 	//Operation
 	user := domain.NewUser("useless", "user")
 	manager.Register(user)
-	err := manager.DeleteTweetByID(2)
+	err := manager.DeleteTweetByID("", 2)
 	//Validation
 	utility.ValidateExpectedError(t, err, "Coudln't delete tweet, A tweet with that ID does not exist")
 }
@@ -471,15 +579,15 @@ func TestCantDeleteATweetThatYouDidntPublish(t *testing.T) {
 	user2 := domain.NewUser("manu", "hunter2")
 	manager.Register(user1)
 	manager.Register(user2)
-	tweet, _ := domain.NewTextTweet(user1, "hola")
+	tweet, _ := manager.NewTextTweet(user1, "hola")
 
-	manager.Login(user1)
-	manager.PublishTweet(tweet)
-	manager.Logout()
+	token, _ := manager.Login(user1)
+	manager.PublishTweet(token, tweet)
+	manager.Logout(token)
 
-	manager.Login(user2)
+	token, _ = manager.Login(user2)
 	//Operation
-	err := manager.DeleteTweetByID(tweet.GetID())
+	err := manager.DeleteTweetByID(token, tweet.GetID())
 	//Validation
 	utility.ValidateExpectedError(t, err, "You can't delete a tweet that you didn't publish")
 }
@@ -490,13 +598,13 @@ func TestCantDeleteATweetIfNotLoggedIn(t *testing.T) {
 	manager.InitializeManager()
 	user1 := domain.NewUser("root", "root")
 	manager.Register(user1)
-	tweet, _ := domain.NewTextTweet(user1, "hola")
+	tweet, _ := manager.NewTextTweet(user1, "hola")
 
-	manager.Login(user1)
-	manager.PublishTweet(tweet)
-	manager.Logout()
+	token, _ := manager.Login(user1)
+	manager.PublishTweet(token, tweet)
+	manager.Logout(token)
 	//Operation
-	err := manager.DeleteTweetByID(tweet.GetID())
+	err := manager.DeleteTweetByID(token, tweet.GetID())
 	//Validation
 	utility.ValidateExpectedError(t, err, "Coudln't delete tweet, Not logged in")
 }
@@ -508,13 +616,13 @@ func TestCanEditTweetText(t *testing.T) {
 	manager.InitializeManager()
 	user := domain.NewUser("root", "root")
 	manager.Register(user)
-	manager.Login(user)
+	token, _ := manager.Login(user)
 	text := "sample"
-	tweet, _ := domain.NewTextTweet(user, text)
-	manager.PublishTweet(tweet)
+	tweet, _ := manager.NewTextTweet(user, text)
+	manager.PublishTweet(token, tweet)
 	newText := "modified sample"
 	//Operation
-	manager.EditTweetTextByID(tweet.GetID(), newText)
+	manager.EditTweetTextByID(token, tweet.GetID(), newText)
 	//Validation
 	retrievedTweet, _ := manager.GetTweet()
 	if retrievedTweet.GetText() != newText {
@@ -526,13 +634,13 @@ func TestCantEditNonExistentTweet(t *testing.T) {
 	manager.InitializeManager()
 	user := domain.NewUser("root", "root")
 	manager.Register(user)
-	manager.Login(user)
+	token, _ := manager.Login(user)
 	text := "sample"
-	tweet, _ := domain.NewTextTweet(user, text)
-	manager.PublishTweet(tweet)
+	tweet, _ := manager.NewTextTweet(user, text)
+	manager.PublishTweet(token, tweet)
 	newText := "modified sample"
 	//Operation
-	err := manager.EditTweetTextByID(4, newText)
+	err := manager.EditTweetTextByID(token, 4, newText)
 	//Validation
 	utility.ValidateExpectedError(t, err, "Coudln't edit tweet, A tweet with that ID does not exist")
 }
@@ -541,14 +649,14 @@ func TestCantEditTweetIfNotLoggedIn(t *testing.T) {
 	manager.InitializeManager()
 	user := domain.NewUser("root", "root")
 	manager.Register(user)
-	manager.Login(user)
+	token, _ := manager.Login(user)
 	text := "sample"
-	tweet, _ := domain.NewTextTweet(user, text)
-	manager.PublishTweet(tweet)
+	tweet, _ := manager.NewTextTweet(user, text)
+	manager.PublishTweet(token, tweet)
 	newText := "modified sample"
-	manager.Logout()
+	manager.Logout(token)
 	//Operation
-	err := manager.EditTweetTextByID(tweet.GetID(), newText)
+	err := manager.EditTweetTextByID(token, tweet.GetID(), newText)
 	//Validation
 	utility.ValidateExpectedError(t, err, "Coudln't edit tweet, Not logged in")
 }
@@ -559,15 +667,15 @@ func TestCantEditTweetThatYouDidNotPublish(t *testing.T) {
 	otherUser := domain.NewUser("manu", "hunter2")
 	manager.Register(otherUser)
 	manager.Register(user)
-	manager.Login(user)
+	token, _ := manager.Login(user)
 	text := "sample"
-	tweet, _ := domain.NewTextTweet(user, text)
-	manager.PublishTweet(tweet)
+	tweet, _ := manager.NewTextTweet(user, text)
+	manager.PublishTweet(token, tweet)
 	newText := "modified sample"
-	manager.Logout()
-	manager.Login(otherUser)
+	manager.Logout(token)
+	token, _ = manager.Login(otherUser)
 	//Operation
-	err := manager.EditTweetTextByID(tweet.GetID(), newText)
+	err := manager.EditTweetTextByID(token, tweet.GetID(), newText)
 	//Validation
 	utility.ValidateExpectedError(t, err, "You can't edit a tweet that you didn't publish")
 }
@@ -576,13 +684,13 @@ func TestCantEditTweetWithEmptyText(t *testing.T) {
 	manager.InitializeManager()
 	user := domain.NewUser("root", "root")
 	manager.Register(user)
-	manager.Login(user)
+	token, _ := manager.Login(user)
 	text := "sample"
-	tweet, _ := domain.NewTextTweet(user, text)
-	manager.PublishTweet(tweet)
+	tweet, _ := manager.NewTextTweet(user, text)
+	manager.PublishTweet(token, tweet)
 	invalidText := ""
 	//Operation
-	err := manager.EditTweetTextByID(tweet.GetID(), invalidText)
+	err := manager.EditTweetTextByID(token, tweet.GetID(), invalidText)
 	//Validation
 	utility.ValidateExpectedError(t, err, "Coudln't edit tweet, Can't have no text")
 }
@@ -592,20 +700,48 @@ func TestCantEditTextTweetWithLongText(t *testing.T) {
 	manager.InitializeManager()
 	user := domain.NewUser("root", "root")
 	manager.Register(user)
-	manager.Login(user)
+	token, _ := manager.Login(user)
 	text := "sample"
-	tweet, _ := domain.NewTextTweet(user, text)
-	manager.PublishTweet(tweet)
+	tweet, _ := manager.NewTextTweet(user, text)
+	manager.PublishTweet(token, tweet)
 	invalidText := "Este es un texto muy largo que se supone" +
 		"que haga fallar al test del tweet, ya que en el" +
 		"tweeter que estamos haciendo no se puede tweetear" +
 		"algo que tenga mas de 140 caracteres."
 	//Operation
-	err := manager.EditTweetTextByID(tweet.GetID(), invalidText)
+	err := manager.EditTweetTextByID(token, tweet.GetID(), invalidText)
 	//Validation
 	utility.ValidateExpectedError(t, err, "Coudln't edit tweet, Can't have more than 140 characters")
 }
 
+func TestMaxTweetLengthCanBeConfigured(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManagerWith(service.NewMemoryTweetRepository(), domain.NewSequentialIDGenerator(), domain.SystemClock{}, domain.WithMaxTweetLength(200))
+	user := domain.NewUser("root", "root")
+	manager.Register(user)
+	token, _ := manager.Login(user)
+	tweet, _ := manager.NewTextTweet(user, "hola")
+	manager.PublishTweet(token, tweet)
+
+	//Operation
+	long, err := manager.NewTextTweet(user, strings.Repeat("a", 150))
+	if err == nil {
+		err = manager.EditTweetTextByID(token, tweet.GetID(), strings.Repeat("b", 150))
+	}
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if manager.MaxTweetLength() != 200 || domain.TweetLength(long.GetText()) != 150 {
+		t.Errorf("Expected a limit of 200 characters but got %d", manager.MaxTweetLength())
+	}
+	_, err = manager.NewTextTweet(user, strings.Repeat("a", 201))
+	utility.ValidateExpectedError(t, err, "Can't have more than 200 characters")
+}
+
 func TestCanFollowUser(t *testing.T) {
 	//Initialization
 	var manager service.TweetManager
@@ -615,15 +751,15 @@ func TestCanFollowUser(t *testing.T) {
 
 	manager.Register(user)
 	manager.Register(secondUser)
-	manager.Login(user)
+	token, _ := manager.Login(user)
 	//Operation
-	err := manager.FollowUser(secondUser.Name)
+	err := manager.FollowUser(token, secondUser.Name)
 	if err != nil {
 		t.Errorf("Unexpected error, %s", err.Error())
 		return
 	}
 	//Validation
-	u, _ := manager.GetLoggedInUser()
+	u, _ := manager.GetLoggedInUser(token)
 	following := u.IsFollowing(secondUser)
 	if !following {
 		t.Error("User not followed correctly")
@@ -637,9 +773,9 @@ func TestCantFollowNonexistentUser(t *testing.T) {
 	secondUser := domain.NewUser("gonza", "hunter3")
 
 	manager.Register(user)
-	manager.Login(user)
+	token, _ := manager.Login(user)
 	//Operation
-	err := manager.FollowUser(secondUser.Name)
+	err := manager.FollowUser(token, secondUser.Name)
 	//Validation
 	utility.ValidateExpectedError(t, err, "Couldn't follow user, User not registered")
 }
@@ -649,7 +785,7 @@ func TestCantFollowIfNotLoggedIn(t *testing.T) {
 	manager.InitializeManager()
 	secondUser := domain.NewUser("gonza", "hunter3")
 	//Operation
-	err := manager.FollowUser(secondUser.Name)
+	err := manager.FollowUser("", secondUser.Name)
 	//Validation
 	utility.ValidateExpectedError(t, err, "Coudln't follow user, Not logged in")
 }
@@ -661,9 +797,9 @@ func TestCantFollowYourself(t *testing.T) {
 	user := domain.NewUser("manu", "hunter2")
 
 	manager.Register(user)
-	manager.Login(user)
+	token, _ := manager.Login(user)
 	//Operation
-	err := manager.FollowUser(user.Name)
+	err := manager.FollowUser(token, user.Name)
 	//Validation
 	utility.ValidateExpectedError(t, err, "Can't follow yourself")
 }
@@ -677,10 +813,193 @@ func TestCantFollowSameUserTwice(t *testing.T) {
 
 	manager.Register(user)
 	manager.Register(secondUser)
-	manager.Login(user)
+	token, _ := manager.Login(user)
 	//Operation
-	manager.FollowUser(secondUser.Name)
-	err := manager.FollowUser(secondUser.Name)
+	manager.FollowUser(token, secondUser.Name)
+	err := manager.FollowUser(token, secondUser.Name)
 	//Validation
 	utility.ValidateExpectedError(t, err, "Can't follow same user twice")
 }
+
+//IDS AND CLOCK TESTS
+func TestManagersGiveTheirOwnIDs(t *testing.T) {
+	//Initialization
+	var firstManager, secondManager service.TweetManager
+	firstManager.InitializeManager()
+	secondManager.InitializeManager()
+	user := domain.NewUser("root", "root")
+
+	//Operation
+	firstManager.NewTextTweet(user, "first")
+	firstTweet, _ := firstManager.NewTextTweet(user, "second")
+	secondTweet, _ := secondManager.NewTextTweet(user, "first")
+
+	//Validation
+	if firstTweet.GetID() != 1 || secondTweet.GetID() != 0 {
+		t.Errorf("Expected IDs 1 and 0 but got %d and %d", firstTweet.GetID(), secondTweet.GetID())
+	}
+}
+
+func TestTweetsAreDatedByTheManagerClock(t *testing.T) {
+	//Initialization
+	now := time.Date(2018, time.March, 1, 12, 0, 0, 0, time.UTC)
+	var manager service.TweetManager
+	manager.InitializeManagerWith(service.NewMemoryTweetRepository(), domain.NewSequentialIDGenerator(), domain.NewFixedClock(now))
+	user := domain.NewUser("root", "root")
+	manager.Register(user)
+	token, _ := manager.Login(user)
+
+	//Operation
+	tweet, _ := manager.NewTextTweet(user, "sample")
+	manager.PublishTweet(token, tweet)
+
+	//Validation
+	publishedTweet, _ := manager.GetTweet()
+	if !publishedTweet.GetDate().Equal(now) {
+		t.Errorf("Expected date %s but was %s", now, publishedTweet.GetDate())
+	}
+}
+
+func TestSessionsExpireByTheManagerClock(t *testing.T) {
+	//Initialization
+	clock := domain.NewFixedClock(time.Date(2018, time.March, 1, 12, 0, 0, 0, time.UTC))
+	var manager service.TweetManager
+	manager.InitializeManagerWith(service.NewMemoryTweetRepository(), domain.NewSequentialIDGenerator(), clock)
+	user := domain.NewUser("root", "root")
+	manager.Register(user)
+	token, _ := manager.Login(user)
+
+	//Operation
+	clock.Advance(service.DefaultSessionDuration)
+	_, err := manager.GetLoggedInUser(token)
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Session expired")
+}
+
+//PASSWORD TESTS
+func TestPasswordsAreNotStoredInPlainText(t *testing.T) {
+	//Initialization
+	repository := service.NewMemoryTweetRepository()
+	var manager service.TweetManager
+	manager.InitializeManagerWithRepository(repository)
+	user := domain.NewUser("root", "hunter2")
+
+	//Operation
+	manager.Register(user)
+
+	//Validation
+	stored, _ := repository.GetUserByName(user.Name)
+	if stored.Password != "" {
+		t.Error("The stored user should not have a password")
+	}
+	credentials, err := repository.GetCredentials(user.Name)
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if strings.Contains(credentials.PasswordHash, user.Password) {
+		t.Errorf("The password can be read from %s", credentials.PasswordHash)
+	}
+}
+
+func TestTweetsDontCarryThePasswordOfTheirUser(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	user := domain.NewUser("root", "hunter2")
+	manager.Register(user)
+	token, _ := manager.Login(user)
+	tweet, _ := manager.NewTextTweet(user, "sample")
+
+	//Operation
+	manager.PublishTweet(token, tweet)
+
+	//Validation
+	publishedTweet, _ := manager.GetTweet()
+	if publishedTweet.GetUser().Password != "" {
+		t.Error("The tweet should not have the password of its user")
+	}
+}
+
+func TestCanChangePassword(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	user := domain.NewUser("root", "hunter2")
+	manager.Register(user)
+	token, _ := manager.Login(user)
+
+	//Operation
+	err := manager.ChangePassword(token, "hunter2", "hunter3")
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	if _, err := manager.Login(user); err == nil {
+		t.Error("Should not log in with the old password")
+	}
+	if _, err := manager.Login(domain.NewUser("root", "hunter3")); err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+	}
+}
+
+func TestCantChangePasswordWithoutTheCurrentOne(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	user := domain.NewUser("root", "hunter2")
+	manager.Register(user)
+	token, _ := manager.Login(user)
+
+	//Operation
+	err := manager.ChangePassword(token, "wrong", "hunter3")
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't change password, Wrong password")
+}
+
+func TestChangingPasswordEndsOtherSessions(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	user := domain.NewUser("root", "hunter2")
+	manager.Register(user)
+	token, _ := manager.Login(user)
+	otherToken, _ := manager.Login(user)
+
+	//Operation
+	manager.ChangePassword(token, "hunter2", "hunter3")
+
+	//Validation
+	if _, err := manager.GetLoggedInUser(token); err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+	}
+	_, err := manager.GetLoggedInUser(otherToken)
+	utility.ValidateExpectedError(t, err, "Not logged in")
+}
+
+func TestWeakPasswordHashesAreUpgradedOnLogin(t *testing.T) {
+	//Initialization
+	repository := service.NewMemoryTweetRepository()
+	var manager service.TweetManager
+	manager.InitializeManagerWithRepository(repository)
+	user := domain.NewUser("root", "hunter2")
+	repository.AddUser(domain.NewUser("root", ""))
+	repository.SetCredentials(domain.NewPlainCredentials("root", "hunter2"))
+
+	//Operation
+	_, err := manager.Login(user)
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	credentials, _ := repository.GetCredentials(user.Name)
+	if credentials.NeedsUpgrade() || !credentials.Verify("hunter2") {
+		t.Errorf("Expected an upgraded hash but got %s", credentials.PasswordHash)
+	}
+}
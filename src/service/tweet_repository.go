@@ -0,0 +1,614 @@
+package service
+
+import (
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/search"
+)
+
+//TweetRepository is where the manager stores users, their tweets and who they follow
+type TweetRepository interface {
+	AddUser(domain.User) error
+	AddUserWithCredentials(domain.User, domain.Credentials) error
+	GetUsers() []domain.User
+	GetUserByName(string) (*domain.User, error)
+	GetUserByID(int) (*domain.User, error)
+	SetProtected(id int, protected bool) error
+	SetCredentials(domain.Credentials) error
+	GetCredentials(string) (*domain.Credentials, error)
+
+	AddTweet(domain.Tweeter) error
+	GetTweets() []domain.Tweeter
+	GetTweetsFromUser(string) ([]domain.Tweeter, error)
+	GetTweetByID(int) (domain.Tweeter, error)
+	UpdateTweet(domain.Tweeter) error
+	DeleteTweet(domain.Tweeter) error
+	GetTombstones() []domain.Tombstone
+	GetTombstone(int) (*domain.Tombstone, error)
+	GetTweetsWithHashtag(string) []domain.Tweeter
+	GetTweetsMentioning(string) []domain.Tweeter
+	SearchTweets(search.Query) []domain.Tweeter
+
+	AddFollow(follower, followed int) error
+	RemoveFollow(follower, followed int) error
+	GetFollowing(int) []int
+	GetFollowers(int) []int
+	GetMutuals(int) []int
+	GetFollowCounts(int) FollowCounts
+	IsFollowing(follower, followed int) bool
+	AddFollowRequest(requester, owner int) error
+	RemoveFollowRequest(requester, owner int) error
+	GetFollowRequests(int) []int
+	AddBlock(blocker, blocked int) error
+	RemoveBlock(blocker, blocked int) error
+	GetBlocked(int) []int
+	AreBlocked(first, second int) bool
+	AddMute(muter, muted int) error
+	RemoveMute(muter, muted int) error
+	GetMuted(int) []int
+
+	AddLike(name string, id int) error
+	RemoveLike(name string, id int) error
+	GetLikes(int) []string
+	GetLikedTweets(string) []int
+	AddBookmark(name string, id int) error
+	RemoveBookmark(name string, id int) error
+	GetBookmarks(string) []int
+}
+
+//MemoryTweetRepository is a TweetRepository that keeps everything in memory
+type MemoryTweetRepository struct {
+	users       []domain.User
+	lastUserID  int
+	credentials map[string]domain.Credentials
+	userTweets  map[string][]domain.Tweeter
+	byID        map[int]domain.Tweeter
+	tombstones  []domain.Tombstone
+	graph       *SocialGraph
+	likes       map[int][]string
+	retweets    map[int]int
+	liked       map[string][]int
+	bookmarks   map[string][]int
+	hashtags    *entityIndex
+	mentions    *entityIndex
+	index       *search.Index
+}
+
+//NewMemoryTweetRepository returns a new empty MemoryTweetRepository
+func NewMemoryTweetRepository() *MemoryTweetRepository {
+	return &MemoryTweetRepository{
+		users:       make([]domain.User, 0),
+		credentials: make(map[string]domain.Credentials),
+		userTweets:  make(map[string][]domain.Tweeter),
+		byID:        make(map[int]domain.Tweeter),
+		graph:       NewSocialGraph(),
+		likes:       make(map[int][]string),
+		retweets:    make(map[int]int),
+		liked:       make(map[string][]int),
+		bookmarks:   make(map[string][]int),
+		hashtags:    newEntityIndex(domain.ExtractHashtags),
+		mentions:    newEntityIndex(domain.ExtractMentions),
+		index:       search.NewIndex(),
+	}
+}
+
+//AddUser stores a new user without its password, giving it the next ID unless it already has one.
+//Users that still carry a password, as the ones saved before passwords were hashed, keep it as
+//plain credentials until they log in again
+func (r *MemoryTweetRepository) AddUser(user domain.User) error {
+	if _, ok := r.userTweets[user.Name]; ok {
+		return ErrAlreadyRegistered
+	}
+	if user.ID == 0 {
+		user.ID = r.lastUserID + 1
+	}
+	if _, err := r.GetUserByID(user.ID); err == nil {
+		return ErrAlreadyRegistered
+	}
+	if user.ID > r.lastUserID {
+		r.lastUserID = user.ID
+	}
+	if user.Password != "" {
+		r.credentials[user.Name] = domain.NewPlainCredentials(user.Name, user.Password)
+	}
+	user.Following = nil
+	r.users = append(r.users, user.Public())
+	r.userTweets[user.Name] = make([]domain.Tweeter, 0)
+	return nil
+}
+
+//AddUserWithCredentials stores a new user with its credentials, so that it is never stored without them
+func (r *MemoryTweetRepository) AddUserWithCredentials(user domain.User, credentials domain.Credentials) error {
+	if credentials.Name != user.Name {
+		return ErrInvalidName
+	}
+	err := r.AddUser(user.Public())
+	if err != nil {
+		return err
+	}
+	return r.SetCredentials(credentials)
+}
+
+//SetCredentials stores the credentials of a registered user, replacing the ones it had
+func (r *MemoryTweetRepository) SetCredentials(credentials domain.Credentials) error {
+	if _, ok := r.userTweets[credentials.Name]; !ok {
+		return ErrUserNotRegistered
+	}
+	r.credentials[credentials.Name] = credentials
+	return nil
+}
+
+//GetCredentials returns the credentials of a user
+func (r *MemoryTweetRepository) GetCredentials(name string) (*domain.Credentials, error) {
+	credentials, ok := r.credentials[name]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return &credentials, nil
+}
+
+//GetUsers returns all the stored users
+func (r *MemoryTweetRepository) GetUsers() []domain.User {
+	users := make([]domain.User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, r.withFollowing(user))
+	}
+	return users
+}
+
+//GetUserByName returns the stored user that has that name
+func (r *MemoryTweetRepository) GetUserByName(name string) (*domain.User, error) {
+	for _, user := range r.users {
+		if user.Name == name {
+			found := r.withFollowing(user)
+			return &found, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+//GetUserByID returns the stored user that has that ID
+func (r *MemoryTweetRepository) GetUserByID(id int) (*domain.User, error) {
+	for _, user := range r.users {
+		if user.ID == id {
+			found := r.withFollowing(user)
+			return &found, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+//SetProtected changes if the user with that ID is protected
+func (r *MemoryTweetRepository) SetProtected(id int, protected bool) error {
+	for i, user := range r.users {
+		if user.ID == id {
+			r.users[i].Protected = protected
+			return nil
+		}
+	}
+	return ErrUserNotFound
+}
+
+//withFollowing fills the Following list of a user from the social graph
+func (r *MemoryTweetRepository) withFollowing(user domain.User) domain.User {
+	user.Following = nil
+	for _, id := range r.graph.Following(user.ID) {
+		for _, followed := range r.users {
+			if followed.ID == id {
+				user.Follow(followed)
+			}
+		}
+	}
+	return user
+}
+
+//AddTweet stores a tweet under its author
+func (r *MemoryTweetRepository) AddTweet(tweet domain.Tweeter) error {
+	name := tweet.GetUser().Name
+	if _, ok := r.userTweets[name]; !ok {
+		return ErrUserNotRegistered
+	}
+	r.userTweets[name] = append(r.userTweets[name], tweet)
+	r.byID[tweet.GetID()] = tweet
+	r.indexEntities(tweet)
+	if retweet, ok := tweet.(*domain.Retweet); ok {
+		r.retweets[retweet.GetRetweeted().GetID()]++
+		r.refreshCounters(retweet.GetRetweeted())
+	}
+	return nil
+}
+
+//GetTweets returns every stored tweet
+func (r *MemoryTweetRepository) GetTweets() []domain.Tweeter {
+	var tweets []domain.Tweeter
+	for _, user := range r.users {
+		tweets = append(tweets, r.userTweets[user.Name]...)
+	}
+	return tweets
+}
+
+//GetTweetsFromUser returns the tweets published by a user, oldest first
+func (r *MemoryTweetRepository) GetTweetsFromUser(name string) ([]domain.Tweeter, error) {
+	tweets, ok := r.userTweets[name]
+	if !ok {
+		return nil, ErrUserNotRegistered
+	}
+	return append([]domain.Tweeter(nil), tweets...), nil
+}
+
+//GetTweetByID returns the stored tweet that has that ID
+func (r *MemoryTweetRepository) GetTweetByID(id int) (domain.Tweeter, error) {
+	tweet, ok := r.byID[id]
+	if !ok {
+		return nil, ErrTweetNotFound
+	}
+	return tweet, nil
+}
+
+//UpdateTweet saves the changes made to a stored tweet
+func (r *MemoryTweetRepository) UpdateTweet(tweet domain.Tweeter) error {
+	tweets := r.userTweets[tweet.GetUser().Name]
+	for i, tw := range tweets {
+		if tw.GetID() == tweet.GetID() {
+			tweets[i] = tweet
+			r.byID[tweet.GetID()] = tweet
+			r.indexEntities(tweet)
+			r.refreshCounters(tweet)
+			return nil
+		}
+	}
+	return ErrTweetNotFound
+}
+
+//DeleteTweet removes a stored tweet, with its retweets, likes and bookmarks.
+//If it has replies, it leaves a tombstone in its place
+func (r *MemoryTweetRepository) DeleteTweet(tweet domain.Tweeter) error {
+	name := tweet.GetUser().Name
+	var newTweets []domain.Tweeter
+	for _, tw := range r.userTweets[name] {
+		if !tw.Equals(tweet) {
+			newTweets = append(newTweets, tw)
+		}
+	}
+	r.userTweets[name] = newTweets
+	delete(r.byID, tweet.GetID())
+	r.removeQuotesOf(tweet.GetID())
+	r.removeRetweetsOf(tweet.GetID())
+	r.removeLikesOf(tweet.GetID())
+	r.hashtags.remove(tweet.GetID())
+	r.mentions.remove(tweet.GetID())
+	r.index.Remove(tweet.GetID())
+	if retweet, ok := tweet.(*domain.Retweet); ok {
+		r.forgetRetweet(retweet.GetRetweeted().GetID())
+		r.refreshCounters(retweet.GetRetweeted())
+	}
+	if r.hasReplies(tweet.GetID()) {
+		r.tombstones = append(r.tombstones, domain.NewTombstone(tweet))
+	}
+	return nil
+}
+
+//indexEntities indexes a stored tweet by its hashtags, mentions and words.
+//Retweets are left out, as their text is the one of the tweet they share
+func (r *MemoryTweetRepository) indexEntities(tweet domain.Tweeter) {
+	if _, ok := tweet.(*domain.Retweet); ok {
+		return
+	}
+	r.hashtags.add(tweet)
+	r.mentions.add(tweet)
+	r.index.Add(tweet)
+}
+
+//GetTweetsWithHashtag returns the stored tweets that have a hashtag, given lowercased and without the #
+func (r *MemoryTweetRepository) GetTweetsWithHashtag(tag string) []domain.Tweeter {
+	return r.tweetsByID(r.hashtags.get(tag))
+}
+
+//GetTweetsMentioning returns the stored tweets that mention a user
+func (r *MemoryTweetRepository) GetTweetsMentioning(name string) []domain.Tweeter {
+	return r.tweetsByID(r.mentions.get(name))
+}
+
+//SearchTweets returns the stored tweets that match a query, the most relevant first
+func (r *MemoryTweetRepository) SearchTweets(query search.Query) []domain.Tweeter {
+	var tweets []domain.Tweeter
+	for _, result := range r.index.Search(query) {
+		if tweet, err := r.GetTweetByID(result.ID); err == nil {
+			tweets = append(tweets, tweet)
+		}
+	}
+	return tweets
+}
+
+func (r *MemoryTweetRepository) tweetsByID(ids []int) []domain.Tweeter {
+	var tweets []domain.Tweeter
+	for _, id := range ids {
+		if tweet, err := r.GetTweetByID(id); err == nil {
+			tweets = append(tweets, tweet)
+		}
+	}
+	return tweets
+}
+
+//removeQuotesOf makes the stored quotes of a deleted tweet forget it
+func (r *MemoryTweetRepository) removeQuotesOf(id int) {
+	for _, tweets := range r.userTweets {
+		for _, tweet := range tweets {
+			if quote, ok := tweet.(*domain.QuoteTweet); ok && quote.GetQuotedID() == id {
+				quote.RemoveQuotedTweet()
+			}
+		}
+	}
+}
+
+//removeRetweetsOf removes the stored retweets of a deleted tweet
+func (r *MemoryTweetRepository) removeRetweetsOf(id int) {
+	for name, tweets := range r.userTweets {
+		var newTweets []domain.Tweeter
+		for _, tweet := range tweets {
+			if retweet, ok := tweet.(*domain.Retweet); !ok || retweet.GetRetweeted().GetID() != id {
+				newTweets = append(newTweets, tweet)
+			} else {
+				delete(r.byID, retweet.GetID())
+			}
+		}
+		r.userTweets[name] = newTweets
+	}
+	delete(r.retweets, id)
+}
+
+//forgetRetweet counts one less stored retweet of the tweet with that ID
+func (r *MemoryTweetRepository) forgetRetweet(id int) {
+	if r.retweets[id] <= 1 {
+		delete(r.retweets, id)
+		return
+	}
+	r.retweets[id]--
+}
+
+//removeLikesOf forgets who liked or bookmarked a deleted tweet
+func (r *MemoryTweetRepository) removeLikesOf(id int) {
+	for _, name := range r.likes[id] {
+		r.liked[name] = removeID(r.liked[name], id)
+	}
+	delete(r.likes, id)
+	for name, bookmarks := range r.bookmarks {
+		r.bookmarks[name] = removeID(bookmarks, id)
+	}
+}
+
+//hasReplies returns if a stored tweet or tombstone answers the tweet with that ID
+func (r *MemoryTweetRepository) hasReplies(id int) bool {
+	for _, tweets := range r.userTweets {
+		for _, tweet := range tweets {
+			if parentID, ok := domain.GetParentID(tweet); ok && parentID == id {
+				return true
+			}
+		}
+	}
+	for _, tombstone := range r.tombstones {
+		if tombstone.ParentID != nil && *tombstone.ParentID == id {
+			return true
+		}
+	}
+	return false
+}
+
+//GetTombstones returns the tombstones of the deleted tweets that had replies
+func (r *MemoryTweetRepository) GetTombstones() []domain.Tombstone {
+	return append([]domain.Tombstone(nil), r.tombstones...)
+}
+
+//GetTombstone returns the tombstone of the deleted tweet that had that ID
+func (r *MemoryTweetRepository) GetTombstone(id int) (*domain.Tombstone, error) {
+	for _, tombstone := range r.tombstones {
+		if tombstone.ID == id {
+			return &tombstone, nil
+		}
+	}
+	return nil, ErrTweetNotFound
+}
+
+//AddFollow stores that the user with ID follower follows the one with ID followed
+func (r *MemoryTweetRepository) AddFollow(follower, followed int) error {
+	if _, err := r.GetUserByID(follower); err != nil {
+		return err
+	}
+	if _, err := r.GetUserByID(followed); err != nil {
+		return err
+	}
+	return r.graph.Follow(follower, followed)
+}
+
+//RemoveFollow forgets that the user with ID follower follows the one with ID followed
+func (r *MemoryTweetRepository) RemoveFollow(follower, followed int) error {
+	return r.graph.Unfollow(follower, followed)
+}
+
+//GetFollowing returns the IDs of the users that a user follows, in the order they were followed
+func (r *MemoryTweetRepository) GetFollowing(id int) []int {
+	return r.graph.Following(id)
+}
+
+//GetFollowers returns the IDs of the users that follow a user, in the order they followed it
+func (r *MemoryTweetRepository) GetFollowers(id int) []int {
+	return r.graph.Followers(id)
+}
+
+//GetMutuals returns the IDs of the users that a user follows and that follow it back
+func (r *MemoryTweetRepository) GetMutuals(id int) []int {
+	return r.graph.Mutuals(id)
+}
+
+//GetFollowCounts returns how many followers a user has and how many users it follows
+func (r *MemoryTweetRepository) GetFollowCounts(id int) FollowCounts {
+	return r.graph.Counts(id)
+}
+
+//IsFollowing returns if the user with ID follower follows the one with ID followed
+func (r *MemoryTweetRepository) IsFollowing(follower, followed int) bool {
+	return r.graph.IsFollowing(follower, followed)
+}
+
+//AddFollowRequest stores that the user with ID requester asked to follow the one with ID owner
+func (r *MemoryTweetRepository) AddFollowRequest(requester, owner int) error {
+	if _, err := r.GetUserByID(requester); err != nil {
+		return err
+	}
+	if _, err := r.GetUserByID(owner); err != nil {
+		return err
+	}
+	return r.graph.Request(requester, owner)
+}
+
+//RemoveFollowRequest forgets that the user with ID requester asked to follow the one with ID owner
+func (r *MemoryTweetRepository) RemoveFollowRequest(requester, owner int) error {
+	return r.graph.RemoveRequest(requester, owner)
+}
+
+//GetFollowRequests returns the IDs of the users that asked to follow a user, in the order they asked
+func (r *MemoryTweetRepository) GetFollowRequests(owner int) []int {
+	return r.graph.Requests(owner)
+}
+
+//AddBlock stores that the user with ID blocker blocks the one with ID blocked, removing their follows
+func (r *MemoryTweetRepository) AddBlock(blocker, blocked int) error {
+	if _, err := r.GetUserByID(blocker); err != nil {
+		return err
+	}
+	if _, err := r.GetUserByID(blocked); err != nil {
+		return err
+	}
+	return r.graph.Block(blocker, blocked)
+}
+
+//RemoveBlock forgets that the user with ID blocker blocks the one with ID blocked
+func (r *MemoryTweetRepository) RemoveBlock(blocker, blocked int) error {
+	return r.graph.Unblock(blocker, blocked)
+}
+
+//GetBlocked returns the IDs of the users that a user blocked, in the order they were blocked
+func (r *MemoryTweetRepository) GetBlocked(id int) []int {
+	return r.graph.Blocked(id)
+}
+
+//AreBlocked returns if any of two users blocked the other
+func (r *MemoryTweetRepository) AreBlocked(first, second int) bool {
+	return r.graph.AreBlocked(first, second)
+}
+
+//AddMute stores that the user with ID muter mutes the one with ID muted
+func (r *MemoryTweetRepository) AddMute(muter, muted int) error {
+	if _, err := r.GetUserByID(muter); err != nil {
+		return err
+	}
+	if _, err := r.GetUserByID(muted); err != nil {
+		return err
+	}
+	return r.graph.Mute(muter, muted)
+}
+
+//RemoveMute forgets that the user with ID muter mutes the one with ID muted
+func (r *MemoryTweetRepository) RemoveMute(muter, muted int) error {
+	return r.graph.Unmute(muter, muted)
+}
+
+//GetMuted returns the IDs of the users that a user muted, in the order they were muted
+func (r *MemoryTweetRepository) GetMuted(id int) []int {
+	return r.graph.Muted(id)
+}
+
+//AddLike stores that a user likes the tweet with that ID
+func (r *MemoryTweetRepository) AddLike(name string, id int) error {
+	tweet, err := r.GetTweetByID(id)
+	if err != nil {
+		return err
+	}
+	if containsID(r.liked[name], id) {
+		return ErrAlreadyLiked
+	}
+	r.likes[id] = append(r.likes[id], name)
+	r.liked[name] = append(r.liked[name], id)
+	r.refreshCounters(tweet)
+	return nil
+}
+
+//RemoveLike forgets that a user likes the tweet with that ID
+func (r *MemoryTweetRepository) RemoveLike(name string, id int) error {
+	tweet, err := r.GetTweetByID(id)
+	if err != nil {
+		return err
+	}
+	if !containsID(r.liked[name], id) {
+		return ErrNotLiked
+	}
+	var likes []string
+	for _, liker := range r.likes[id] {
+		if liker != name {
+			likes = append(likes, liker)
+		}
+	}
+	r.likes[id] = likes
+	r.liked[name] = removeID(r.liked[name], id)
+	r.refreshCounters(tweet)
+	return nil
+}
+
+//GetLikes returns the names of the users that like the tweet with that ID, in the order they liked it
+func (r *MemoryTweetRepository) GetLikes(id int) []string {
+	return append([]string(nil), r.likes[id]...)
+}
+
+//GetLikedTweets returns the IDs of the tweets that a user likes, in the order they were liked
+func (r *MemoryTweetRepository) GetLikedTweets(name string) []int {
+	return append([]int(nil), r.liked[name]...)
+}
+
+//AddBookmark stores that a user bookmarked the tweet with that ID
+func (r *MemoryTweetRepository) AddBookmark(name string, id int) error {
+	if _, err := r.GetTweetByID(id); err != nil {
+		return err
+	}
+	if containsID(r.bookmarks[name], id) {
+		return ErrAlreadyBookmarked
+	}
+	r.bookmarks[name] = append(r.bookmarks[name], id)
+	return nil
+}
+
+//RemoveBookmark forgets that a user bookmarked the tweet with that ID
+func (r *MemoryTweetRepository) RemoveBookmark(name string, id int) error {
+	if !containsID(r.bookmarks[name], id) {
+		return ErrNotBookmarked
+	}
+	r.bookmarks[name] = removeID(r.bookmarks[name], id)
+	return nil
+}
+
+//GetBookmarks returns the IDs of the tweets that a user bookmarked, in the order they were bookmarked
+func (r *MemoryTweetRepository) GetBookmarks(name string) []int {
+	return append([]int(nil), r.bookmarks[name]...)
+}
+
+//refreshCounters updates how many likes and retweets a stored tweet shows
+func (r *MemoryTweetRepository) refreshCounters(tweet domain.Tweeter) {
+	tweet.SetCounters(domain.Counters{Likes: len(r.likes[tweet.GetID()]), Retweets: r.retweets[tweet.GetID()]})
+}
+
+func containsID(ids []int, id int) bool {
+	for _, other := range ids {
+		if other == id {
+			return true
+		}
+	}
+	return false
+}
+
+func removeID(ids []int, id int) []int {
+	var kept []int
+	for _, other := range ids {
+		if other != id {
+			kept = append(kept, other)
+		}
+	}
+	return kept
+}
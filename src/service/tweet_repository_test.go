@@ -0,0 +1,95 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/service"
+	"github.com/cursoGo/src/utility"
+)
+
+func TestMemoryRepositoryCanStoreTweets(t *testing.T) {
+	//Initialization
+	repository := service.NewMemoryTweetRepository()
+	user := domain.NewUser("root", "root")
+	repository.AddUser(user)
+	tweet, _ := domain.NewTextTweet(user, "hola")
+	//Operation
+	err := repository.AddTweet(tweet)
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	stored, err := repository.GetTweetByID(tweet.GetID())
+	if err != nil || !stored.Equals(tweet) {
+		t.Error("Tweet did not get stored")
+	}
+}
+
+func TestMemoryRepositoryCantStoreTweetsOfUnregisteredUser(t *testing.T) {
+	//Initialization
+	repository := service.NewMemoryTweetRepository()
+	user := domain.NewUser("root", "root")
+	tweet, _ := domain.NewTextTweet(user, "hola")
+	//Operation
+	err := repository.AddTweet(tweet)
+	//Validation
+	utility.ValidateExpectedError(t, err, "That user is not registered")
+}
+
+func TestMemoryRepositoryFillsFollowingOfStoredUsers(t *testing.T) {
+	//Initialization
+	repository := service.NewMemoryTweetRepository()
+	user := domain.NewUser("manu", "hunter2")
+	secondUser := domain.NewUser("gonza", "hunter3")
+	repository.AddUser(user)
+	repository.AddUser(secondUser)
+	follower, _ := repository.GetUserByName(user.Name)
+	followed, _ := repository.GetUserByName(secondUser.Name)
+	//Operation
+	repository.AddFollow(follower.ID, followed.ID)
+	//Validation
+	stored, _ := repository.GetUserByID(follower.ID)
+	if !stored.IsFollowing(secondUser) {
+		t.Error("Follow did not get stored")
+	}
+}
+
+func TestManagerUsesGivenRepository(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	repository := service.NewMemoryTweetRepository()
+	manager.InitializeManagerWithRepository(repository)
+	user := domain.NewUser("root", "root")
+	manager.Register(user)
+	token, _ := manager.Login(user)
+	tweet, _ := domain.NewTextTweet(user, "hola")
+	//Operation
+	manager.PublishTweet(token, tweet)
+	//Validation
+	tweets, _ := repository.GetTweetsFromUser(user.Name)
+	if len(tweets) != 1 {
+		t.Errorf("Expected size is 1 but was %d", len(tweets))
+	}
+}
+
+func TestFollowsSurviveLogout(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	user := domain.NewUser("manu", "hunter2")
+	secondUser := domain.NewUser("gonza", "hunter3")
+	manager.Register(user)
+	manager.Register(secondUser)
+	token, _ := manager.Login(user)
+	manager.FollowUser(token, secondUser.Name)
+	//Operation
+	manager.Logout(token)
+	token, _ = manager.Login(user)
+	//Validation
+	u, _ := manager.GetLoggedInUser(token)
+	if !u.IsFollowing(secondUser) {
+		t.Error("Follow got lost after logging out")
+	}
+}
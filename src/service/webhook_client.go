@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+//webhookTimeout is how long a request to a webhook, or looking up its host, can take
+const webhookTimeout = 10 * time.Second
+
+type publicOnlyKey struct{}
+
+//WithPublicAddressesOnly returns a context whose requests, made with a client of NewWebhookClient,
+//can't reach loopback, link-local, private or unspecified addresses
+func WithPublicAddressesOnly(ctx context.Context) context.Context {
+	return context.WithValue(ctx, publicOnlyKey{}, true)
+}
+
+func isPublicOnly(ctx context.Context) bool {
+	publicOnly, _ := ctx.Value(publicOnlyKey{}).(bool)
+	return publicOnly
+}
+
+//webhookTransport sends the requests that can only reach public addresses through a transport of its own,
+//so that they never reuse a connection dialed for another request
+type webhookTransport struct {
+	public *http.Transport
+	any    *http.Transport
+}
+
+func (t webhookTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if isPublicOnly(request.Context()) {
+		return t.public.RoundTrip(request)
+	}
+	return t.any.RoundTrip(request)
+}
+
+//NewWebhookClient returns the client that webhook dispatchers use by default. Its requests time out after
+//10 seconds, and the ones made with a context of WithPublicAddressesOnly are refused when the address dialed
+//isn't public. That is checked once the host is resolved, so a host can't change its address to get past it
+func NewWebhookClient() *http.Client {
+	public := http.DefaultTransport.(*http.Transport).Clone()
+	//through a proxy, the address dialed would be the one of the proxy
+	public.Proxy = nil
+	public.DialContext = (&net.Dialer{Timeout: webhookTimeout, Control: checkDialedAddress}).DialContext
+	return &http.Client{
+		Timeout:   webhookTimeout,
+		Transport: webhookTransport{public: public, any: http.DefaultTransport.(*http.Transport).Clone()},
+	}
+}
+
+//checkDialedAddress refuses to connect to an address that isn't public
+func checkDialedAddress(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	return checkPublicIP(net.ParseIP(host))
+}
+
+//checkPublicHost returns ErrPrivateWebhookURL if host is, or resolves to, an address that isn't public
+func checkPublicHost(host string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+	addresses, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return ErrWebhookHostNotFound
+	}
+	for _, address := range addresses {
+		if err := checkPublicIP(address.IP); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//checkPublicIP returns ErrPrivateWebhookURL if ip is a loopback, link-local, private or unspecified address
+func checkPublicIP(ip net.IP) error {
+	if ip == nil || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsPrivate() || ip.IsUnspecified() {
+		return ErrPrivateWebhookURL
+	}
+	return nil
+}
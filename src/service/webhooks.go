@@ -0,0 +1,523 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	stdjson "encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/events"
+)
+
+//Headers of a webhook request
+const (
+	//SignatureHeader holds "sha256=" followed by the hex HMAC-SHA256 of the body, keyed with the secret of the webhook
+	SignatureHeader = "X-Tweeter-Signature"
+	EventHeader     = "X-Tweeter-Event"
+	DeliveryHeader  = "X-Tweeter-Delivery"
+)
+
+//maxDeliveryHistory is how many deliveries a WebhookDispatcher remembers, besides the dead letters.
+//Pending deliveries that are forgotten are still attempted
+const maxDeliveryHistory = 1000
+
+//maxDeadLetters is how many deliveries that ran out of attempts a WebhookDispatcher remembers
+const maxDeadLetters = 1000
+
+//Webhook is a URL that gets a signed JSON POST for every event of a user: when their tweets are
+//published, edited or deleted and when they are followed. Global webhooks get the events of every user
+type Webhook struct {
+	ID     int    `json:"id"`
+	Owner  string `json:"owner,omitempty"`
+	Global bool   `json:"global,omitempty"`
+	URL    string `json:"url"`
+	//Secret signs the payloads. It is only shown when the webhook is added
+	Secret string `json:"secret,omitempty"`
+}
+
+//WebhookPayload is the body of a webhook request
+type WebhookPayload struct {
+	Delivery int                 `json:"delivery"`
+	Event    string              `json:"event"`
+	Time     time.Time           `json:"time"`
+	Tweet    *domain.TweetRecord `json:"tweet,omitempty"`
+	Follower string              `json:"follower,omitempty"`
+	Followed string              `json:"followed,omitempty"`
+}
+
+//DeliveryStatus is where a delivery is at
+type DeliveryStatus string
+
+//Statuses of a delivery
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	//DeliveryFailed is the status of the deliveries that ran out of attempts or whose webhook was removed,
+	//which are kept as dead letters
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+//ReasonWebhookRemoved is the reason of the deliveries that failed because their webhook was removed
+const ReasonWebhookRemoved = "webhook removed"
+
+//DeliveryAttempt is a request made to deliver an event to a webhook
+type DeliveryAttempt struct {
+	Time       time.Time `json:"time"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+//Delivery is an event that has to be sent to a webhook, and the attempts made to send it
+type Delivery struct {
+	ID        int                `json:"id"`
+	WebhookID int                `json:"webhookID"`
+	Event     string             `json:"event"`
+	Payload   stdjson.RawMessage `json:"payload"`
+	Status    DeliveryStatus     `json:"status"`
+	Attempts  []DeliveryAttempt  `json:"attempts"`
+	//NextAttempt is when a pending delivery is attempted again
+	NextAttempt *time.Time `json:"nextAttempt,omitempty"`
+	//Reason is why a failed delivery stopped being attempted before running out of attempts
+	Reason string `json:"reason,omitempty"`
+
+	due time.Time
+}
+
+//copy returns a copy of the delivery that doesn't share its attempts
+func (d *Delivery) copy() Delivery {
+	copied := *d
+	copied.Attempts = append([]DeliveryAttempt(nil), d.Attempts...)
+	if d.Status == DeliveryPending {
+		due := d.due
+		copied.NextAttempt = &due
+	}
+	return copied
+}
+
+//RetryPolicy says how many times a delivery is attempted, and how long to wait between attempts.
+//The wait starts at BaseDelay and doubles after every failed attempt, up to MaxDelay
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+//DefaultRetryPolicy attempts a delivery 6 times over about half an hour
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 6, BaseDelay: time.Minute, MaxDelay: 10 * time.Minute}
+
+//delay returns how long to wait after the given number of failed attempts
+func (p RetryPolicy) delay(failed int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < failed && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+//SignPayload returns the signature of a webhook body, as sent in SignatureHeader
+func SignPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+//VerifySignature returns if signature is the one of a webhook body, so that receivers know it was sent by the tweeter
+func VerifySignature(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(SignPayload(secret, body)), []byte(signature))
+}
+
+//WebhookDispatcher delivers the events published by a TweetManager to webhooks, retrying the deliveries
+//that fail in the background. Every webhook has a worker of its own, so a slow receiver only delays
+//its own deliveries. It is safe for concurrent use
+type WebhookDispatcher struct {
+	tweets       *TweetManager
+	client       *http.Client
+	policy       RetryPolicy
+	subscription *events.Subscription
+
+	webhooks      []Webhook
+	workers       map[int]*webhookWorker
+	lastWebhookID int
+	//history is every delivery in the order they were created, up to maxDeliveryHistory
+	history        []*Delivery
+	deadLetters    []*Delivery
+	lastDeliveryID int
+	//allowPrivate lets the webhooks of users point to addresses that aren't public
+	allowPrivate bool
+	closed       bool
+	mutex        sync.Mutex
+
+	running sync.WaitGroup
+	once    sync.Once
+}
+
+//webhookWorker attempts the pending deliveries to a webhook, one at a time, until it is canceled
+type webhookWorker struct {
+	webhook Webhook
+	queue   []*Delivery
+	wake    chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+//NewWebhookDispatcher returns a WebhookDispatcher for the events of tweets that sends requests with a
+//NewWebhookClient, with the DefaultRetryPolicy
+func NewWebhookDispatcher(tweets *TweetManager) *WebhookDispatcher {
+	return NewWebhookDispatcherWith(tweets, NewWebhookClient(), DefaultRetryPolicy)
+}
+
+//NewWebhookDispatcherWith returns a WebhookDispatcher for the events of tweets that sends requests with client,
+//retrying them as policy says. Requests to the webhooks of users have a context of WithPublicAddressesOnly,
+//which only a client of NewWebhookClient enforces when dialing
+func NewWebhookDispatcherWith(tweets *TweetManager, client *http.Client, policy RetryPolicy) *WebhookDispatcher {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	d := &WebhookDispatcher{
+		tweets:  tweets,
+		client:  client,
+		policy:  policy,
+		workers: make(map[int]*webhookWorker),
+	}
+	d.subscription = tweets.EventBus().SubscribeAsync(d.enqueue, 256, events.Block)
+	return d
+}
+
+//Close stops delivering events, cancelling the requests being made. The pending deliveries aren't attempted anymore
+func (d *WebhookDispatcher) Close() {
+	d.once.Do(func() {
+		d.subscription.Unsubscribe()
+		d.mutex.Lock()
+		d.closed = true
+		for _, worker := range d.workers {
+			worker.cancel()
+		}
+		d.mutex.Unlock()
+		d.running.Wait()
+	})
+}
+
+//AllowPrivateAddresses lets the webhooks of users point to loopback, link-local, private and unspecified
+//addresses, as when their receivers run next to the tweeter. It is meant for tests and development
+func (d *WebhookDispatcher) AllowPrivateAddresses() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.allowPrivate = true
+}
+
+//AddWebhook adds a webhook for the events of the user logged in with a session.
+//Unless private addresses are allowed, its host has to resolve to public addresses only
+func (d *WebhookDispatcher) AddWebhook(token string, webhookURL string) (*Webhook, error) {
+	user, err := d.tweets.GetLoggedInUser(token)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't add webhook, %w", err)
+	}
+	secret, err := newSessionToken()
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't add webhook, %w", err)
+	}
+	return d.addWebhook(Webhook{Owner: user.Name, URL: webhookURL, Secret: secret})
+}
+
+//AddGlobalWebhook adds a webhook for the events of every user, whose payloads are signed with secret.
+//It is meant for the administrators of the tweeter, so it doesn't need a session and can point anywhere
+func (d *WebhookDispatcher) AddGlobalWebhook(webhookURL string, secret string) (*Webhook, error) {
+	return d.addWebhook(Webhook{Global: true, URL: webhookURL, Secret: secret})
+}
+
+func (d *WebhookDispatcher) addWebhook(webhook Webhook) (*Webhook, error) {
+	parsed, err := url.Parse(webhook.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("Couldn't add webhook, %w", ErrInvalidWebhookURL)
+	}
+	if !webhook.Global && !d.privateAllowed() {
+		if err := checkPublicHost(parsed.Hostname()); err != nil {
+			return nil, fmt.Errorf("Couldn't add webhook, %w", err)
+		}
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.lastWebhookID++
+	webhook.ID = d.lastWebhookID
+	d.webhooks = append(d.webhooks, webhook)
+	if !d.closed {
+		worker := &webhookWorker{webhook: webhook, wake: make(chan struct{}, 1)}
+		worker.ctx, worker.cancel = context.WithCancel(context.Background())
+		d.workers[webhook.ID] = worker
+		d.running.Add(1)
+		go d.run(worker)
+	}
+	return &webhook, nil
+}
+
+func (d *WebhookDispatcher) privateAllowed() bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.allowPrivate
+}
+
+//GetWebhooks returns the webhooks of the user logged in with a session, without their secrets
+func (d *WebhookDispatcher) GetWebhooks(token string) ([]Webhook, error) {
+	user, err := d.tweets.GetLoggedInUser(token)
+	if err != nil {
+		return nil, err
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	var webhooks []Webhook
+	for _, webhook := range d.webhooks {
+		if !webhook.Global && webhook.Owner == user.Name {
+			webhook.Secret = ""
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	return webhooks, nil
+}
+
+//RemoveWebhook removes a webhook of the user logged in with a session, cancelling the request being made to it.
+//Its pending deliveries fail with ReasonWebhookRemoved
+func (d *WebhookDispatcher) RemoveWebhook(token string, id int) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if _, err := d.ownWebhook(token, id); err != nil {
+		return fmt.Errorf("Couldn't remove webhook, %w", err)
+	}
+	for i, webhook := range d.webhooks {
+		if webhook.ID == id {
+			d.webhooks = append(d.webhooks[:i:i], d.webhooks[i+1:]...)
+			break
+		}
+	}
+	if worker, ok := d.workers[id]; ok {
+		worker.cancel()
+		delete(d.workers, id)
+		for _, delivery := range worker.queue {
+			delivery.Status = DeliveryFailed
+			delivery.Reason = ReasonWebhookRemoved
+			d.deadLetters = keepLatest(append(d.deadLetters, delivery), maxDeadLetters)
+		}
+		worker.queue = nil
+	}
+	return nil
+}
+
+//ownWebhook returns the webhook with that ID, if it belongs to the user logged in with a session. The mutex must be locked
+func (d *WebhookDispatcher) ownWebhook(token string, id int) (*Webhook, error) {
+	user, err := d.tweets.GetLoggedInUser(token)
+	if err != nil {
+		return nil, err
+	}
+	for i, webhook := range d.webhooks {
+		if webhook.ID == id && !webhook.Global && webhook.Owner == user.Name {
+			return &d.webhooks[i], nil
+		}
+	}
+	return nil, ErrWebhookNotFound
+}
+
+//GetDeliveries returns the deliveries to a webhook of the user logged in with a session, the latest first
+func (d *WebhookDispatcher) GetDeliveries(token string, id int) ([]Delivery, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if _, err := d.ownWebhook(token, id); err != nil {
+		return nil, err
+	}
+	return d.deliveriesTo(id), nil
+}
+
+//Deliveries returns the deliveries to any webhook, global ones included, the latest first.
+//It is meant for the administrators of the tweeter, so it doesn't need a session
+func (d *WebhookDispatcher) Deliveries(id int) []Delivery {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.deliveriesTo(id)
+}
+
+func (d *WebhookDispatcher) deliveriesTo(id int) []Delivery {
+	var deliveries []Delivery
+	for i := len(d.history) - 1; i >= 0; i-- {
+		if d.history[i].WebhookID == id {
+			deliveries = append(deliveries, d.history[i].copy())
+		}
+	}
+	return deliveries
+}
+
+//DeadLetters returns the deliveries that ran out of attempts, the latest first
+func (d *WebhookDispatcher) DeadLetters() []Delivery {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	var deadLetters []Delivery
+	for i := len(d.deadLetters) - 1; i >= 0; i-- {
+		deadLetters = append(deadLetters, d.deadLetters[i].copy())
+	}
+	return deadLetters
+}
+
+//enqueue creates the deliveries of an event to the webhooks that want it
+func (d *WebhookDispatcher) enqueue(event events.Event) {
+	payload := WebhookPayload{Event: event.Kind(), Time: time.Now()}
+	var user string
+	switch e := event.(type) {
+	case events.TweetPublished:
+		payload.Tweet, user = tweetRecord(e.Tweet), e.Tweet.GetUser().Name
+	case events.TweetEdited:
+		payload.Tweet, user = tweetRecord(e.Tweet), e.Tweet.GetUser().Name
+	case events.TweetDeleted:
+		payload.Tweet, user = tweetRecord(e.Tweet), e.Tweet.GetUser().Name
+	case events.UserFollowed:
+		payload.Follower, payload.Followed, user = e.Follower.Name, e.Followed.Name, e.Followed.Name
+	default:
+		return
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for _, webhook := range d.webhooks {
+		worker, ok := d.workers[webhook.ID]
+		if !ok || (!webhook.Global && webhook.Owner != user) {
+			continue
+		}
+		d.lastDeliveryID++
+		payload.Delivery = d.lastDeliveryID
+		body, err := json.Marshal(payload)
+		if err != nil {
+			continue
+		}
+		delivery := &Delivery{
+			ID:        d.lastDeliveryID,
+			WebhookID: webhook.ID,
+			Event:     payload.Event,
+			Payload:   body,
+			Status:    DeliveryPending,
+			due:       payload.Time,
+		}
+		worker.queue = append(worker.queue, delivery)
+		d.history = keepLatest(append(d.history, delivery), maxDeliveryHistory)
+		select {
+		case worker.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+//tweetRecord returns the record of a tweet, or nil if it can't be recorded
+func tweetRecord(tweet domain.Tweeter) *domain.TweetRecord {
+	record, err := domain.NewTweetRecord(tweet)
+	if err != nil {
+		return nil
+	}
+	return &record
+}
+
+//keepLatest returns the last deliveries, up to limit
+func keepLatest(deliveries []*Delivery, limit int) []*Delivery {
+	if len(deliveries) > limit {
+		return deliveries[len(deliveries)-limit:]
+	}
+	return deliveries
+}
+
+//run attempts the deliveries of a worker as they are due, until it is canceled
+func (d *WebhookDispatcher) run(worker *webhookWorker) {
+	defer d.running.Done()
+	for worker.ctx.Err() == nil {
+		delivery, wait := d.nextDue(worker)
+		if delivery != nil {
+			d.attempt(worker, delivery)
+			continue
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-worker.wake:
+		case <-timer.C:
+		case <-worker.ctx.Done():
+		}
+		timer.Stop()
+	}
+}
+
+//nextDue returns a pending delivery of a worker that is due, or how long to wait for one if there is none
+func (d *WebhookDispatcher) nextDue(worker *webhookWorker) (*Delivery, time.Duration) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	now := time.Now()
+	wait := time.Hour
+	for _, delivery := range worker.queue {
+		if !delivery.due.After(now) {
+			return delivery, 0
+		}
+		if until := delivery.due.Sub(now); until < wait {
+			wait = until
+		}
+	}
+	return nil, wait
+}
+
+//attempt sends a delivery to the webhook of a worker, scheduling it again if it fails and it has attempts left.
+//Attempts cut short by cancelling the worker don't count
+func (d *WebhookDispatcher) attempt(worker *webhookWorker, delivery *Delivery) {
+	webhook, body := worker.webhook, delivery.Payload
+	ctx := worker.ctx
+	d.mutex.Lock()
+	if !webhook.Global && !d.allowPrivate {
+		ctx = WithPublicAddressesOnly(ctx)
+	}
+	d.mutex.Unlock()
+
+	attempt := DeliveryAttempt{Time: time.Now()}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err == nil {
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set(SignatureHeader, SignPayload(webhook.Secret, body))
+		request.Header.Set(EventHeader, delivery.Event)
+		request.Header.Set(DeliveryHeader, strconv.Itoa(delivery.ID))
+		var response *http.Response
+		response, err = d.client.Do(request)
+		if err == nil {
+			response.Body.Close()
+			attempt.StatusCode = response.StatusCode
+		}
+	}
+	if err != nil {
+		if worker.ctx.Err() != nil {
+			return
+		}
+		attempt.Error = err.Error()
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if delivery.Status != DeliveryPending {
+		//The webhook was removed while the request was being made
+		return
+	}
+	delivery.Attempts = append(delivery.Attempts, attempt)
+	switch {
+	case err == nil && attempt.StatusCode >= 200 && attempt.StatusCode < 300:
+		delivery.Status = DeliveryDelivered
+	case len(delivery.Attempts) >= d.policy.MaxAttempts:
+		delivery.Status = DeliveryFailed
+		d.deadLetters = keepLatest(append(d.deadLetters, delivery), maxDeadLetters)
+	default:
+		delivery.due = attempt.Time.Add(d.policy.delay(len(delivery.Attempts)))
+		return
+	}
+	for i, queued := range worker.queue {
+		if queued == delivery {
+			worker.queue = append(worker.queue[:i:i], worker.queue[i+1:]...)
+			break
+		}
+	}
+}
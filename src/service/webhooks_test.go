@@ -0,0 +1,468 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cursoGo/src/service"
+	"github.com/cursoGo/src/utility"
+	jsoniter "github.com/json-iterator/go"
+)
+
+//receiver is a webhook receiver that answers with the given statuses in turn, and then with 200
+type receiver struct {
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+	mutex    sync.Mutex
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, request *http.Request) {
+	body, _ := ioutil.ReadAll(request.Body)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.requests = append(r.requests, request)
+	r.bodies = append(r.bodies, body)
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (r *receiver) received() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.requests)
+}
+
+//fastRetries retries deliveries after a few milliseconds
+var fastRetries = service.RetryPolicy{MaxAttempts: 3, BaseDelay: 10 * time.Millisecond, MaxDelay: 40 * time.Millisecond}
+
+//newWebhookManager returns the tokens of newSocialManager, a dispatcher for the manager that allows private addresses
+//and a receiver listening on a local port
+func newWebhookManager(manager *service.TweetManager, statuses ...int) ([]string, *service.WebhookDispatcher, *receiver, *httptest.Server) {
+	manager.InitializeManager()
+	tokens := newSocialManager(manager)
+	receiver := &receiver{statuses: statuses}
+	dispatcher := service.NewWebhookDispatcherWith(manager, http.DefaultClient, fastRetries)
+	dispatcher.AllowPrivateAddresses()
+	return tokens, dispatcher, receiver, httptest.NewServer(receiver)
+}
+
+//eventually waits for condition to be true, failing the test if it takes more than a second
+func eventually(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWebhookGetsSignedPayload(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	tokens, dispatcher, receiver, listener := newWebhookManager(&manager)
+	defer listener.Close()
+	defer dispatcher.Close()
+	webhook, err := dispatcher.AddWebhook(tokens[0], listener.URL+"/hook")
+	if err != nil {
+		t.Fatalf("Unexpected error, %s", err.Error())
+	}
+
+	//Operation
+	tweet := publishText(t, &manager, tokens[0], "manu", "hola")
+
+	//Validation
+	eventually(t, func() bool { return receiver.received() == 1 })
+	request, body := receiver.requests[0], receiver.bodies[0]
+	if !service.VerifySignature(webhook.Secret, body, request.Header.Get(service.SignatureHeader)) {
+		t.Error("The signature doesn't match the body")
+	}
+	if request.Header.Get(service.EventHeader) != "TweetPublished" {
+		t.Errorf("Unexpected event %s", request.Header.Get(service.EventHeader))
+	}
+	var payload service.WebhookPayload
+	jsoniter.Unmarshal(body, &payload)
+	if payload.Tweet == nil || payload.Tweet.ID != tweet.GetID() || payload.Tweet.Text != "hola" {
+		t.Errorf("Unexpected payload %s", body)
+	}
+	eventually(t, func() bool {
+		deliveries, _ := dispatcher.GetDeliveries(tokens[0], webhook.ID)
+		return len(deliveries) == 1 && deliveries[0].Status == service.DeliveryDelivered
+	})
+}
+
+func TestWebhookOnlyGetsEventsOfItsOwner(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	tokens, dispatcher, receiver, listener := newWebhookManager(&manager)
+	defer listener.Close()
+	defer dispatcher.Close()
+	dispatcher.AddWebhook(tokens[0], listener.URL)
+
+	//Operation
+	gonzaTweet := publishText(t, &manager, tokens[1], "gonza", "no es de manu")
+	manager.EditTweetTextByID(tokens[1], gonzaTweet.GetID(), "tampoco")
+	manager.FollowUser(tokens[1], "manu")
+
+	//Validation
+	eventually(t, func() bool { return receiver.received() == 1 })
+	time.Sleep(20 * time.Millisecond)
+	if receiver.received() != 1 || receiver.requests[0].Header.Get(service.EventHeader) != "UserFollowed" {
+		t.Errorf("Expected only the follow but got %d requests", receiver.received())
+	}
+}
+
+func TestGlobalWebhookGetsEveryEvent(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	tokens, dispatcher, receiver, listener := newWebhookManager(&manager)
+	defer listener.Close()
+	defer dispatcher.Close()
+	dispatcher.AddGlobalWebhook(listener.URL, "admin secret")
+
+	//Operation
+	tweet := publishText(t, &manager, tokens[1], "gonza", "hola")
+	manager.EditTweetTextByID(tokens[1], tweet.GetID(), "chau")
+	manager.DeleteTweetByID(tokens[1], tweet.GetID())
+	manager.FollowUser(tokens[2], "manu")
+
+	//Validation
+	eventually(t, func() bool { return receiver.received() == 4 })
+	for i, event := range []string{"TweetPublished", "TweetEdited", "TweetDeleted", "UserFollowed"} {
+		if got := receiver.requests[i].Header.Get(service.EventHeader); got != event {
+			t.Errorf("Expected %s but got %s", event, got)
+		}
+		if !service.VerifySignature("admin secret", receiver.bodies[i], receiver.requests[i].Header.Get(service.SignatureHeader)) {
+			t.Error("The signature doesn't match the body")
+		}
+	}
+}
+
+func TestFailedDeliveriesAreRetriedWithBackoff(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	tokens, dispatcher, _, listener := newWebhookManager(&manager, http.StatusInternalServerError, http.StatusServiceUnavailable)
+	defer listener.Close()
+	defer dispatcher.Close()
+	webhook, _ := dispatcher.AddWebhook(tokens[0], listener.URL)
+
+	//Operation
+	publishText(t, &manager, tokens[0], "manu", "hola")
+
+	//Validation
+	var deliveries []service.Delivery
+	eventually(t, func() bool {
+		deliveries, _ = dispatcher.GetDeliveries(tokens[0], webhook.ID)
+		return len(deliveries) == 1 && deliveries[0].Status == service.DeliveryDelivered
+	})
+	attempts := deliveries[0].Attempts
+	if len(attempts) != 3 || attempts[0].StatusCode != 500 || attempts[1].StatusCode != 503 || attempts[2].StatusCode != 200 {
+		t.Errorf("Unexpected attempts %+v", attempts)
+		return
+	}
+	if attempts[1].Time.Sub(attempts[0].Time) < 10*time.Millisecond || attempts[2].Time.Sub(attempts[1].Time) < 20*time.Millisecond {
+		t.Errorf("Attempts didn't back off %+v", attempts)
+	}
+	if len(dispatcher.DeadLetters()) != 0 {
+		t.Error("Delivered events should not be dead letters")
+	}
+}
+
+func TestDeliveriesOutOfAttemptsAreDeadLetters(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	tokens, dispatcher, receiver, listener := newWebhookManager(&manager, 500, 500, 500)
+	defer listener.Close()
+	defer dispatcher.Close()
+	webhook, _ := dispatcher.AddWebhook(tokens[0], listener.URL)
+
+	//Operation
+	publishText(t, &manager, tokens[0], "manu", "hola")
+
+	//Validation
+	eventually(t, func() bool { return len(dispatcher.DeadLetters()) == 1 })
+	deadLetter := dispatcher.DeadLetters()[0]
+	if deadLetter.WebhookID != webhook.ID || deadLetter.Status != service.DeliveryFailed || len(deadLetter.Attempts) != 3 {
+		t.Errorf("Unexpected dead letter %+v", deadLetter)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if receiver.received() != 3 {
+		t.Errorf("Expected 3 attempts but got %d", receiver.received())
+	}
+}
+
+//hangingReceiver returns a webhook receiver that doesn't answer until release is closed
+func hangingReceiver(release chan struct{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+}
+
+func TestSlowWebhookDoesntDelayOthers(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	tokens, dispatcher, receiver, listener := newWebhookManager(&manager)
+	defer listener.Close()
+	release := make(chan struct{})
+	slow := hangingReceiver(release)
+	defer slow.Close()
+	defer close(release)
+	defer dispatcher.Close()
+	dispatcher.AddWebhook(tokens[0], slow.URL)
+	dispatcher.AddWebhook(tokens[0], listener.URL)
+
+	//Operation
+	publishText(t, &manager, tokens[0], "manu", "hola")
+	publishText(t, &manager, tokens[0], "manu", "chau")
+
+	//Validation
+	eventually(t, func() bool { return receiver.received() == 2 })
+}
+
+func TestDeadLettersAndHistoryAreCapped(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	dispatcher := service.NewWebhookDispatcherWith(&manager, http.DefaultClient, service.RetryPolicy{MaxAttempts: 1})
+	dispatcher.AllowPrivateAddresses()
+	release := make(chan struct{})
+	slow := hangingReceiver(release)
+	defer slow.Close()
+	defer close(release)
+	defer dispatcher.Close()
+	unreachable := httptest.NewServer(&receiver{})
+	unreachable.Close()
+	stuck, _ := dispatcher.AddWebhook(tokens[1], slow.URL)
+	failing, _ := dispatcher.AddWebhook(tokens[0], unreachable.URL)
+	publishText(t, &manager, tokens[1], "gonza", "hola")
+
+	//Operation
+	for i := 0; i < 1100; i++ {
+		publishText(t, &manager, tokens[0], "manu", fmt.Sprintf("tweet %d", i))
+	}
+
+	//Validation
+	var deliveries []service.Delivery
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		deliveries = dispatcher.Deliveries(failing.ID)
+		if len(deliveries) > 0 && deliveries[0].Status == service.DeliveryFailed && deliveries[0].ID == 1101 {
+			break
+		}
+	}
+	if len(deliveries) == 0 || deliveries[0].ID != 1101 || deliveries[0].Status != service.DeliveryFailed {
+		t.Fatal("Expected every delivery to fail")
+	}
+	if len(deliveries)+len(dispatcher.Deliveries(stuck.ID)) != 1000 {
+		t.Errorf("Expected 1000 deliveries to be remembered but got %d", len(deliveries)+len(dispatcher.Deliveries(stuck.ID)))
+	}
+	if len(dispatcher.DeadLetters()) != 1000 {
+		t.Errorf("Expected 1000 dead letters but got %d", len(dispatcher.DeadLetters()))
+	}
+}
+
+func TestUnreachableWebhookIsRetried(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	tokens, dispatcher, _, listener := newWebhookManager(&manager)
+	listener.Close()
+	defer dispatcher.Close()
+	webhook, _ := dispatcher.AddWebhook(tokens[0], listener.URL)
+
+	//Operation
+	publishText(t, &manager, tokens[0], "manu", "hola")
+
+	//Validation
+	eventually(t, func() bool { return len(dispatcher.DeadLetters()) == 1 })
+	deliveries := dispatcher.Deliveries(webhook.ID)
+	if len(deliveries) != 1 || deliveries[0].Attempts[0].Error == "" {
+		t.Errorf("Unexpected deliveries %+v", deliveries)
+	}
+}
+
+func TestCantAddWebhookWithInvalidURL(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	tokens, dispatcher, _, listener := newWebhookManager(&manager)
+	defer listener.Close()
+	defer dispatcher.Close()
+
+	//Operation
+	_, err := dispatcher.AddWebhook(tokens[0], "ftp://example.com/hook")
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't add webhook, A webhook needs an absolute http or https URL")
+}
+
+func TestCantAddWebhookToPrivateAddress(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	dispatcher := service.NewWebhookDispatcher(&manager)
+	defer dispatcher.Close()
+
+	for _, webhookURL := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://10.0.0.1/hook",
+		"https://192.168.1.1/hook",
+		"http://[::1]/hook",
+		"http://0.0.0.0/hook",
+	} {
+		//Operation
+		_, err := dispatcher.AddWebhook(tokens[0], webhookURL)
+
+		//Validation
+		utility.ValidateExpectedError(t, err, "Couldn't add webhook, A webhook can't point to a local or private address")
+	}
+}
+
+func TestWebhookClientRefusesPrivateAddressesWhenDialing(t *testing.T) {
+	//Initialization
+	listener := httptest.NewServer(&receiver{})
+	defer listener.Close()
+	client := service.NewWebhookClient()
+	public, _ := http.NewRequestWithContext(service.WithPublicAddressesOnly(context.Background()), "POST", listener.URL, nil)
+	unrestricted, _ := http.NewRequest("POST", listener.URL, nil)
+
+	//Operation
+	_, publicErr := client.Do(public)
+	response, unrestrictedErr := client.Do(unrestricted)
+
+	//Validation
+	if !errors.Is(publicErr, service.ErrPrivateWebhookURL) {
+		t.Errorf("Expected the private address to be refused but got %v", publicErr)
+	}
+	if unrestrictedErr != nil {
+		t.Errorf("Unexpected error, %s", unrestrictedErr.Error())
+		return
+	}
+	response.Body.Close()
+}
+
+func TestGlobalWebhookCanPointToPrivateAddress(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	manager.InitializeManager()
+	tokens := newSocialManager(&manager)
+	receiver := &receiver{}
+	listener := httptest.NewServer(receiver)
+	defer listener.Close()
+	dispatcher := service.NewWebhookDispatcher(&manager)
+	defer dispatcher.Close()
+	dispatcher.AddGlobalWebhook(listener.URL, "secret")
+
+	//Operation
+	publishText(t, &manager, tokens[0], "manu", "hola")
+
+	//Validation
+	eventually(t, func() bool { return receiver.received() == 1 })
+}
+
+func TestCantRemoveWebhookOfOthers(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	tokens, dispatcher, _, listener := newWebhookManager(&manager)
+	defer listener.Close()
+	defer dispatcher.Close()
+	webhook, _ := dispatcher.AddWebhook(tokens[0], listener.URL)
+
+	//Operation
+	err := dispatcher.RemoveWebhook(tokens[1], webhook.ID)
+
+	//Validation
+	utility.ValidateExpectedError(t, err, "Couldn't remove webhook, A webhook with that ID does not exist")
+	if webhooks, _ := dispatcher.GetWebhooks(tokens[0]); len(webhooks) != 1 || webhooks[0].Secret != "" {
+		t.Errorf("Unexpected webhooks %+v", webhooks)
+	}
+}
+
+func TestRemovedWebhookGetsNoEvents(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	tokens, dispatcher, receiver, listener := newWebhookManager(&manager)
+	defer listener.Close()
+	defer dispatcher.Close()
+	webhook, _ := dispatcher.AddWebhook(tokens[0], listener.URL)
+
+	//Operation
+	err := dispatcher.RemoveWebhook(tokens[0], webhook.ID)
+	publishText(t, &manager, tokens[0], "manu", "hola")
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	time.Sleep(20 * time.Millisecond)
+	if receiver.received() != 0 {
+		t.Errorf("Expected no requests but got %d", receiver.received())
+	}
+}
+
+func TestPendingDeliveriesOfRemovedWebhookFail(t *testing.T) {
+	//Initialization
+	var manager service.TweetManager
+	tokens, dispatcher, _, listener := newWebhookManager(&manager)
+	defer listener.Close()
+	release := make(chan struct{})
+	slow := hangingReceiver(release)
+	defer slow.Close()
+	defer close(release)
+	defer dispatcher.Close()
+	webhook, _ := dispatcher.AddWebhook(tokens[0], slow.URL)
+	publishText(t, &manager, tokens[0], "manu", "hola")
+	publishText(t, &manager, tokens[0], "manu", "chau")
+	eventually(t, func() bool { return len(dispatcher.Deliveries(webhook.ID)) == 2 })
+
+	//Operation
+	err := dispatcher.RemoveWebhook(tokens[0], webhook.ID)
+
+	//Validation
+	if err != nil {
+		t.Errorf("Unexpected error, %s", err.Error())
+		return
+	}
+	time.Sleep(20 * time.Millisecond)
+	deliveries := dispatcher.Deliveries(webhook.ID)
+	for _, delivery := range deliveries {
+		if delivery.Status != service.DeliveryFailed || delivery.Reason != service.ReasonWebhookRemoved || len(delivery.Attempts) != 0 {
+			t.Errorf("Unexpected delivery %+v", delivery)
+		}
+	}
+	if len(dispatcher.DeadLetters()) != 2 {
+		t.Errorf("Expected 2 dead letters but got %d", len(dispatcher.DeadLetters()))
+	}
+}
+
+func TestSignatureDependsOnSecret(t *testing.T) {
+	//Initialization
+	body := []byte(`{"event":"TweetPublished"}`)
+
+	//Operation
+	signature := service.SignPayload("secret", body)
+
+	//Validation
+	if !service.VerifySignature("secret", body, signature) {
+		t.Error("The signature should be valid")
+	}
+	if service.VerifySignature("other", body, signature) || service.VerifySignature("secret", []byte("{}"), signature) {
+		t.Error("The signature should only be valid for the same secret and body")
+	}
+}
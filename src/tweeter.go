@@ -1,20 +1,53 @@
 package main
 
 import (
+	"fmt"
+	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/abiosoft/ishell"
 	"github.com/cursoGo/src/domain"
+	"github.com/cursoGo/src/server"
 	"github.com/cursoGo/src/service"
 )
 
+//timelinePageSize is how many tweets the timeline command shows at once
+const timelinePageSize = 10
+
+//scheduleLayout is how the schedule command reads and shows dates, in local time
+const scheduleLayout = "2006-01-02 15:04"
+
 func main() {
 
+	var manager service.TweetManager
+	err := manager.InitializeManagerWithRepository(newRepository())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		serve(&manager)
+		return
+	}
+
+	messages := service.NewMessageManager(&manager)
+	drafts := service.NewDraftManager(&manager)
+	defer drafts.Close()
+
 	shell := ishell.New()
 	shell.SetPrompt("Tweeter >> ")
 	shell.Print("Type 'help' to know commands\n")
-	var manager service.TweetManager
-	manager.InitializeManager()
+
+	//token of the shell's session, empty while logged out
+	var token string
+	//page of the timeline that was shown last
+	var timelinePage service.TimelinePage
+	//conversation and page that the read command showed last
+	var readConversation int
+	var readPage service.MessagePage
 
 	shell.AddCmd(&ishell.Cmd{
 		Name: "register",
@@ -48,6 +81,11 @@ func main() {
 
 			defer c.ShowPrompt(true)
 
+			if _, err := manager.GetLoggedInUser(token); err == nil {
+				c.Print("Invalid login, Already logged in\n")
+				return
+			}
+
 			c.Print("Insert name: ")
 			name := c.ReadLine()
 
@@ -55,11 +93,12 @@ func main() {
 			password := c.ReadLine()
 
 			user := domain.NewUser(name, password)
-			err := manager.Login(user)
+			newToken, err := manager.Login(user)
 			if err != nil {
 				c.Printf("Invalid login, %s\n", err.Error())
 				return
 			}
+			token = newToken
 			c.Print("Login successfull\n")
 		},
 	})
@@ -70,15 +109,38 @@ func main() {
 		Func: func(c *ishell.Context) {
 
 			defer c.ShowPrompt(true)
-			err := manager.Logout()
+			err := manager.Logout(token)
 			if err != nil {
 				c.Printf("Couldn't log out, %s\n", err.Error())
 				return
 			}
+			token = ""
 			c.Print("Logged out\n")
 		},
 	})
 
+	shell.AddCmd(&ishell.Cmd{
+		Name: "password",
+		Help: "Changes your password",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			c.Print("Insert current password: ")
+			oldPassword := c.ReadPassword()
+
+			c.Print("Insert new password: ")
+			newPassword := c.ReadPassword()
+
+			err := manager.ChangePassword(token, oldPassword, newPassword)
+			if err != nil {
+				c.Println(err.Error())
+				return
+			}
+			c.Print("Password changed\n")
+		},
+	})
+
 	shell.AddCmd(&ishell.Cmd{
 		Name: "publishTweet",
 		Help: "Publishes a tweet",
@@ -86,10 +148,10 @@ func main() {
 
 			defer c.ShowPrompt(true)
 
-			loggedInUser, err := manager.GetLoggedInUser()
+			loggedInUser, err := manager.GetLoggedInUser(token)
 
 			if err != nil {
-				c.Printf(err.Error())
+				c.Println(err.Error())
 				return
 			}
 
@@ -104,9 +166,9 @@ func main() {
 				c.Print("Insert image URL: ")
 				url := c.ReadLine()
 
-				tweet, err = domain.NewImageTweet(*loggedInUser, text, url)
+				tweet, err = manager.NewImageTweet(*loggedInUser, text, url)
 			case "n":
-				tweet, err = domain.NewTextTweet(*loggedInUser, text)
+				tweet, err = manager.NewTextTweet(*loggedInUser, text)
 			default:
 				c.Printf("Invalid answer")
 				return
@@ -117,7 +179,7 @@ func main() {
 				return
 			}
 
-			err = manager.PublishTweet(tweet)
+			err = manager.PublishTweet(token, tweet)
 
 			if err != nil {
 				c.Printf("Tweet not published, %s\n", err.Error())
@@ -130,19 +192,75 @@ func main() {
 
 	shell.AddCmd(&ishell.Cmd{
 		Name: "timeline",
-		Help: "Shows timeline from logged in user",
+		Help: "Shows timeline from logged in user, newest first. Use 'timeline next' and 'timeline prev' to change pages",
 		Func: func(c *ishell.Context) {
 
 			defer c.ShowPrompt(true)
 
-			tweets, err := manager.GetTimeline()
+			request := service.FirstPage(timelinePageSize)
+			if len(c.Args) > 0 {
+				switch c.Args[0] {
+				case "next":
+					if !timelinePage.HasOlder {
+						c.Print("There are no older tweets\n")
+						return
+					}
+					request = timelinePage.Older(timelinePageSize)
+				case "prev":
+					if !timelinePage.HasNewer {
+						c.Print("There are no newer tweets\n")
+						return
+					}
+					request = timelinePage.Newer(timelinePageSize)
+				default:
+					c.Print("Invalid answer\n")
+					return
+				}
+			}
+
+			page, err := manager.GetTimelinePage(token, request)
 			if err != nil {
 				c.Printf("Can't retrieve timeline, %s\n", err.Error())
 				return
 			}
-			for _, t := range tweets {
+			timelinePage = page
+			for _, t := range page.Tweets {
 				c.Println(t)
 			}
+			if page.HasOlder {
+				c.Print("Type 'timeline next' for older tweets\n")
+			}
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "watch",
+		Help: "Shows the new tweets of your timeline as they are published, until you press Enter",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			stream, err := manager.WatchTimeline(token)
+			if err != nil {
+				c.Printf("Can't watch timeline, %s\n", err.Error())
+				return
+			}
+			c.Print("Watching your timeline, press Enter to stop\n")
+			stopped := make(chan struct{})
+			go func() {
+				for {
+					select {
+					case tweet := <-stream.Tweets:
+						c.Println(tweet)
+					case <-stopped:
+						return
+					}
+				}
+			}()
+			c.ReadLine()
+			close(stopped)
+			stream.Close()
 			return
 		},
 	})
@@ -157,55 +275,1026 @@ func main() {
 			c.Print("Write the ID of the tweet: ")
 			id, _ := strconv.Atoi(c.ReadLine())
 
-			tweet, err := manager.GetTweetByID(id)
+			tweet, err := manager.GetVisibleTweetByID(token, id)
 			if err != nil {
 				c.Printf("Couldn't retrieve, %s\n", err.Error())
 				return
 			}
-			c.Print(tweet)
+			c.Println(tweet)
 			return
 		},
 	})
 
 	shell.AddCmd(&ishell.Cmd{
-		Name: "deleteTweet",
-		Help: "Deletes a tweet by its ID",
+		Name: "reply",
+		Help: "Replies to a tweet by its ID",
 		Func: func(c *ishell.Context) {
 
 			defer c.ShowPrompt(true)
 
-			c.Print("Which tweet do you want to delete?: ")
+			c.Print("Which tweet do you want to reply to?: ")
+			id, err := strconv.Atoi(c.ReadLine())
+			if err != nil {
+				c.Print("Invalid tweet ID\n")
+				return
+			}
 
-			id, _ := strconv.Atoi(c.ReadLine())
-			err := manager.DeleteTweetByID(id)
+			c.Print("Write your reply: ")
+			text := c.ReadLine()
+
+			_, err = manager.ReplyToTweet(token, id, text)
 			if err != nil {
-				c.Printf("Coudln't delete tweet, %s\n", err.Error())
+				c.Println(err.Error())
 				return
 			}
-			c.Print("Tweet deleted successfully\n")
+			c.Print("Reply sent\n")
 			return
 		},
 	})
 
 	shell.AddCmd(&ishell.Cmd{
-		Name: "follow",
-		Help: "Follow a user",
+		Name: "quote",
+		Help: "Quotes a tweet by its ID",
 		Func: func(c *ishell.Context) {
 
 			defer c.ShowPrompt(true)
 
-			c.Print("Who do you want to follow?: ")
-			userToFollow := c.ReadLine()
-			err := manager.FollowUser(userToFollow)
+			c.Print("Which tweet do you want to quote?: ")
+			id, err := strconv.Atoi(c.ReadLine())
 			if err != nil {
-				c.Printf("%s, \n", err.Error())
+				c.Print("Invalid tweet ID\n")
 				return
 			}
-			c.Print("User followed successfully\n")
+
+			c.Print("Write your tweet: ")
+			text := c.ReadLine()
+
+			quote, err := manager.QuoteTweet(token, id, text)
+			if err != nil {
+				c.Println(err.Error())
+				return
+			}
+			c.Println(quote)
 			return
 		},
 	})
 
-	shell.Run()
+	shell.AddCmd(&ishell.Cmd{
+		Name: "retweet",
+		Help: "Retweets a tweet by its ID",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			c.Print("Which tweet do you want to retweet?: ")
+			id, err := strconv.Atoi(c.ReadLine())
+			if err != nil {
+				c.Print("Invalid tweet ID\n")
+				return
+			}
+
+			retweet, err := manager.Retweet(token, id)
+			if err != nil {
+				c.Println(err.Error())
+				return
+			}
+			c.Println(retweet)
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "unretweet",
+		Help: "Undoes a retweet, by the ID of the retweeted tweet",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			c.Print("Which tweet do you want to stop retweeting?: ")
+			id, err := strconv.Atoi(c.ReadLine())
+			if err != nil {
+				c.Print("Invalid tweet ID\n")
+				return
+			}
+
+			err = manager.Unretweet(token, id)
+			if err != nil {
+				c.Println(err.Error())
+				return
+			}
+			c.Print("Retweet undone\n")
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "like",
+		Help: "Likes a tweet by its ID",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			c.Print("Which tweet do you like?: ")
+			id, err := strconv.Atoi(c.ReadLine())
+			if err != nil {
+				c.Print("Invalid tweet ID\n")
+				return
+			}
+
+			err = manager.LikeTweet(token, id)
+			if err != nil {
+				c.Println(err.Error())
+				return
+			}
+			c.Print("Tweet liked\n")
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "unlike",
+		Help: "Undoes the like of a tweet by its ID",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			c.Print("Which tweet don't you like anymore?: ")
+			id, err := strconv.Atoi(c.ReadLine())
+			if err != nil {
+				c.Print("Invalid tweet ID\n")
+				return
+			}
+
+			err = manager.UnlikeTweet(token, id)
+			if err != nil {
+				c.Println(err.Error())
+				return
+			}
+			c.Print("Like undone\n")
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "likes",
+		Help: "Shows who liked a tweet by its ID",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			c.Print("Write the ID of the tweet: ")
+			id, err := strconv.Atoi(c.ReadLine())
+			if err != nil {
+				c.Print("Invalid tweet ID\n")
+				return
+			}
+
+			users, err := manager.GetLikes(token, id)
+			if err != nil {
+				c.Printf("Couldn't retrieve likes, %s\n", err.Error())
+				return
+			}
+			for _, user := range users {
+				c.Printf("@%s\n", user)
+			}
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "liked",
+		Help: "Shows the tweets that a user liked",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			c.Print("Write the name of the user: ")
+			name := c.ReadLine()
+
+			tweets, err := manager.GetLikedTweets(token, domain.NewUser(name, ""))
+			if err != nil {
+				c.Printf("Couldn't retrieve liked tweets, %s\n", err.Error())
+				return
+			}
+			for _, t := range tweets {
+				c.Println(t)
+			}
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "bookmark",
+		Help: "Privately bookmarks a tweet by its ID",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			c.Print("Which tweet do you want to bookmark?: ")
+			id, err := strconv.Atoi(c.ReadLine())
+			if err != nil {
+				c.Print("Invalid tweet ID\n")
+				return
+			}
+
+			err = manager.BookmarkTweet(token, id)
+			if err != nil {
+				c.Println(err.Error())
+				return
+			}
+			c.Print("Tweet bookmarked\n")
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "unbookmark",
+		Help: "Removes a tweet from your bookmarks by its ID",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			c.Print("Which tweet do you want to remove from your bookmarks?: ")
+			id, err := strconv.Atoi(c.ReadLine())
+			if err != nil {
+				c.Print("Invalid tweet ID\n")
+				return
+			}
+
+			err = manager.RemoveBookmark(token, id)
+			if err != nil {
+				c.Println(err.Error())
+				return
+			}
+			c.Print("Bookmark removed\n")
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "bookmarks",
+		Help: "Shows the tweets you bookmarked",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			tweets, err := manager.GetBookmarks(token)
+			if err != nil {
+				c.Printf("Couldn't retrieve bookmarks, %s\n", err.Error())
+				return
+			}
+			for _, t := range tweets {
+				c.Println(t)
+			}
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "hashtag",
+		Help: "Shows the tweets with a hashtag, newest first. Use 'hashtag <tag>'",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			if len(c.Args) != 1 {
+				c.Print("Use 'hashtag <tag>'\n")
+				return
+			}
+
+			for _, t := range manager.GetTweetsWithHashtag(c.Args[0]) {
+				c.Println(t)
+			}
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "mentions",
+		Help: "Shows the tweets that mention you, newest first",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			tweets, err := manager.GetMentions(token)
+			if err != nil {
+				c.Printf("Couldn't retrieve mentions, %s\n", err.Error())
+				return
+			}
+			for _, t := range tweets {
+				c.Println(t)
+			}
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "search",
+		Help: "Searches tweets, the most relevant first. Use 'search <query>', with words, \"phrases\", OR, NOT, from:<user>, since:<yyyy-mm-dd>, until:<yyyy-mm-dd> and type:<kind>",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			query := strings.Join(c.RawArgs[1:], " ")
+			if query == "" {
+				c.Print("What do you want to search?: ")
+				query = c.ReadLine()
+			}
+
+			tweets, err := manager.Search(query)
+			if err != nil {
+				c.Println(err.Error())
+				return
+			}
+			if len(tweets) == 0 {
+				c.Print("No tweets found\n")
+			}
+			for _, t := range tweets {
+				c.Println(t)
+			}
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "thread",
+		Help: "Shows the conversation of a tweet, 'thread <id>'",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			if len(c.Args) != 1 {
+				c.Print("Usage: thread <id>\n")
+				return
+			}
+			id, err := strconv.Atoi(c.Args[0])
+			if err != nil {
+				c.Print("Invalid tweet ID\n")
+				return
+			}
+
+			thread, err := manager.GetThread(token, id)
+			if err != nil {
+				c.Println(err.Error())
+				return
+			}
+			c.Println(thread.Format())
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "deleteTweet",
+		Help: "Deletes a tweet by its ID",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			c.Print("Which tweet do you want to delete?: ")
+
+			id, _ := strconv.Atoi(c.ReadLine())
+			err := manager.DeleteTweetByID(token, id)
+			if err != nil {
+				c.Printf("Coudln't delete tweet, %s\n", err.Error())
+				return
+			}
+			c.Print("Tweet deleted successfully\n")
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "follow",
+		Help: "Follow a user",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			c.Print("Who do you want to follow?: ")
+			userToFollow := c.ReadLine()
+			err := manager.FollowUser(token, userToFollow)
+			if err != nil {
+				c.Printf("%s, \n", err.Error())
+				return
+			}
+			if user, err := manager.GetLoggedInUser(token); err == nil && !user.IsFollowing(domain.NewUser(userToFollow, "")) {
+				c.Print("The user is protected, a follow request was sent\n")
+				return
+			}
+			c.Print("User followed successfully\n")
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "protect",
+		Help: "Protect your account, so that only the followers you approve see your tweets. Use 'protect off' to make it public again, approving every request",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			protected := len(c.Args) == 0 || c.Args[0] != "off"
+			err := manager.SetProtected(token, protected)
+			if err != nil {
+				c.Printf("%s\n", err.Error())
+				return
+			}
+			if protected {
+				c.Print("Your account is now protected\n")
+				return
+			}
+			c.Print("Your account is now public\n")
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "requests",
+		Help: "Shows the users that asked to follow you",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			users, err := manager.GetFollowRequests(token)
+			if err != nil {
+				c.Printf("Couldn't retrieve follow requests, %s\n", err.Error())
+				return
+			}
+			for _, user := range users {
+				c.Printf("@%s\n", user)
+			}
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "approve",
+		Help: "Approve the follow request of a user",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			c.Print("Whose request do you want to approve?: ")
+			err := manager.ApproveFollowRequest(token, c.ReadLine())
+			if err != nil {
+				c.Printf("%s\n", err.Error())
+				return
+			}
+			c.Print("Request approved successfully\n")
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "reject",
+		Help: "Reject the follow request of a user",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			c.Print("Whose request do you want to reject?: ")
+			err := manager.RejectFollowRequest(token, c.ReadLine())
+			if err != nil {
+				c.Printf("%s\n", err.Error())
+				return
+			}
+			c.Print("Request rejected successfully\n")
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "unfollow",
+		Help: "Stop following a user",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			c.Print("Who do you want to unfollow?: ")
+			userToUnfollow := c.ReadLine()
+			err := manager.UnfollowUser(token, userToUnfollow)
+			if err != nil {
+				c.Printf("%s\n", err.Error())
+				return
+			}
+			c.Print("User unfollowed successfully\n")
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "block",
+		Help: "Block a user: neither of you will see the other's tweets nor be able to interact",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			c.Print("Who do you want to block?: ")
+			err := manager.BlockUser(token, c.ReadLine())
+			if err != nil {
+				c.Printf("%s\n", err.Error())
+				return
+			}
+			c.Print("User blocked successfully\n")
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "unblock",
+		Help: "Unblock a user",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			c.Print("Who do you want to unblock?: ")
+			err := manager.UnblockUser(token, c.ReadLine())
+			if err != nil {
+				c.Printf("%s\n", err.Error())
+				return
+			}
+			c.Print("User unblocked successfully\n")
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "blocked",
+		Help: "Shows the users you blocked",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			users, err := manager.GetBlockedUsers(token)
+			if err != nil {
+				c.Printf("Couldn't retrieve blocked users, %s\n", err.Error())
+				return
+			}
+			for _, user := range users {
+				c.Printf("@%s\n", user)
+			}
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "mute",
+		Help: "Hide the tweets of a user from your timeline, without them knowing",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			c.Print("Who do you want to mute?: ")
+			err := manager.MuteUser(token, c.ReadLine())
+			if err != nil {
+				c.Printf("%s\n", err.Error())
+				return
+			}
+			c.Print("User muted successfully\n")
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "unmute",
+		Help: "Unmute a user",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			c.Print("Who do you want to unmute?: ")
+			err := manager.UnmuteUser(token, c.ReadLine())
+			if err != nil {
+				c.Printf("%s\n", err.Error())
+				return
+			}
+			c.Print("User unmuted successfully\n")
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "muted",
+		Help: "Shows the users you muted",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			users, err := manager.GetMutedUsers(token)
+			if err != nil {
+				c.Printf("Couldn't retrieve muted users, %s\n", err.Error())
+				return
+			}
+			for _, user := range users {
+				c.Printf("@%s\n", user)
+			}
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "followers",
+		Help: "Shows the users that follow a user, marking the mutual follows",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			c.Print("Write the name of the user: ")
+			user := domain.NewUser(c.ReadLine(), "")
+
+			followers, err := manager.GetFollowers(user)
+			if err != nil {
+				c.Printf("Couldn't retrieve followers, %s\n", err.Error())
+				return
+			}
+			mutuals, _ := manager.GetMutuals(user)
+			printFollows(c, followers, mutuals)
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "following",
+		Help: "Shows the users that a user follows, marking the mutual follows",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			c.Print("Write the name of the user: ")
+			user := domain.NewUser(c.ReadLine(), "")
+
+			following, err := manager.GetFollowing(user)
+			if err != nil {
+				c.Printf("Couldn't retrieve following, %s\n", err.Error())
+				return
+			}
+			mutuals, _ := manager.GetMutuals(user)
+			printFollows(c, following, mutuals)
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "notifications",
+		Help: "Shows your notifications, the latest first, and marks them as read",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			groups, err := manager.GetNotifications(token)
+			if err != nil {
+				c.Printf("%s\n", err.Error())
+				return
+			}
+			if len(groups) == 0 {
+				c.Print("You have no notifications\n")
+			}
+			for _, group := range groups {
+				c.Println(group)
+			}
+			manager.MarkNotificationsAsRead(token)
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "dm",
+		Help: "Sends a direct message, 'dm <user> [<user>...]'. Messaging many users starts a group conversation",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			names := c.Args
+			if len(names) == 0 {
+				c.Print("Who do you want to message?: ")
+				names = strings.Fields(c.ReadLine())
+			}
+			c.Print("Write your message: ")
+			text := c.ReadLine()
+
+			message, err := messages.SendMessage(token, names, text)
+			if err != nil {
+				c.Printf("%s\n", err.Error())
+				return
+			}
+			c.Printf("Message sent to conversation %d\n", message.ConversationID)
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "inbox",
+		Help: "Shows your conversations, the one with the latest message first",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			inbox, err := messages.GetInbox(token)
+			if err != nil {
+				c.Printf("Couldn't retrieve inbox, %s\n", err.Error())
+				return
+			}
+			if len(inbox) == 0 {
+				c.Print("You have no conversations\n")
+			}
+			for _, conversation := range inbox {
+				c.Println(conversation)
+			}
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "read",
+		Help: "Reads a conversation and marks it as read, 'read <id>'. Use 'read next' and 'read prev' to change pages",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			if len(c.Args) != 1 {
+				c.Print("Usage: read <id>\n")
+				return
+			}
+			request := service.FirstPage(timelinePageSize)
+			switch c.Args[0] {
+			case "next":
+				if !readPage.HasOlder {
+					c.Print("There are no older messages\n")
+					return
+				}
+				request = readPage.Older(timelinePageSize)
+			case "prev":
+				if !readPage.HasNewer {
+					c.Print("There are no newer messages\n")
+					return
+				}
+				request = readPage.Newer(timelinePageSize)
+			default:
+				id, err := strconv.Atoi(c.Args[0])
+				if err != nil {
+					c.Print("Invalid conversation ID\n")
+					return
+				}
+				readConversation = id
+			}
+
+			page, err := messages.ReadConversation(token, readConversation, request)
+			if err != nil {
+				c.Printf("%s\n", err.Error())
+				return
+			}
+			readPage = page
+			for _, message := range page.Messages {
+				c.Println(message)
+			}
+			if page.HasOlder {
+				c.Print("Type 'read next' for older messages\n")
+			}
+			messages.MarkAsRead(token, readConversation)
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "drafts",
+		Help: "Shows your drafts. Use 'drafts new', 'drafts edit <id>', 'drafts delete <id>' and 'drafts publish <id>' to manage them",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			if len(c.Args) == 0 {
+				saved, err := drafts.GetDrafts(token)
+				if err != nil {
+					c.Printf("Couldn't retrieve drafts, %s\n", err.Error())
+					return
+				}
+				if len(saved) == 0 {
+					c.Print("You have no drafts\n")
+				}
+				for _, draft := range saved {
+					c.Println(draft)
+				}
+				return
+			}
+			if c.Args[0] == "new" {
+				text, url, ok := readTweetContent(c)
+				if !ok {
+					return
+				}
+				draft, err := drafts.SaveDraft(token, text, url)
+				if err != nil {
+					c.Printf("%s\n", err.Error())
+					return
+				}
+				c.Printf("Draft %d saved\n", draft.ID)
+				return
+			}
+			if len(c.Args) != 2 {
+				c.Print("Usage: drafts [new | edit <id> | delete <id> | publish <id>]\n")
+				return
+			}
+			id, err := strconv.Atoi(c.Args[1])
+			if err != nil {
+				c.Print("Invalid draft ID\n")
+				return
+			}
+			switch c.Args[0] {
+			case "edit":
+				text, url, ok := readTweetContent(c)
+				if !ok {
+					return
+				}
+				_, err = drafts.EditDraft(token, id, text, url)
+				if err == nil {
+					c.Print("Draft edited\n")
+				}
+			case "delete":
+				err = drafts.DeleteDraft(token, id)
+				if err == nil {
+					c.Print("Draft deleted\n")
+				}
+			case "publish":
+				var tweet domain.Tweeter
+				tweet, err = drafts.PublishDraft(token, id)
+				if err == nil {
+					c.Printf("Tweet %d sent\n", tweet.GetID())
+				}
+			default:
+				c.Print("Usage: drafts [new | edit <id> | delete <id> | publish <id>]\n")
+				return
+			}
+			if err != nil {
+				c.Printf("%s\n", err.Error())
+			}
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "schedule",
+		Help: "Schedules a new tweet to be published later, or a draft with 'schedule <id>'",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			if len(c.Args) > 1 {
+				c.Print("Usage: schedule [<id>]\n")
+				return
+			}
+			id := -1
+			var text, url string
+			if len(c.Args) == 1 {
+				var err error
+				id, err = strconv.Atoi(c.Args[0])
+				if err != nil {
+					c.Print("Invalid draft ID\n")
+					return
+				}
+			} else {
+				var ok bool
+				text, url, ok = readTweetContent(c)
+				if !ok {
+					return
+				}
+			}
+			c.Printf("When should it be published? (%s): ", scheduleLayout)
+			publishAt, err := time.ParseInLocation(scheduleLayout, c.ReadLine(), time.Local)
+			if err != nil {
+				c.Print("Invalid date\n")
+				return
+			}
+
+			var draft *domain.Draft
+			if id >= 0 {
+				draft, err = drafts.ScheduleDraft(token, id, publishAt)
+			} else {
+				draft, err = drafts.ScheduleTweet(token, text, url, publishAt)
+			}
+			if err != nil {
+				c.Printf("%s\n", err.Error())
+				return
+			}
+			c.Printf("Scheduled as draft %d for %s\n", draft.ID, draft.PublishAt.Format(scheduleLayout))
+			return
+		},
+	})
+
+	shell.AddCmd(&ishell.Cmd{
+		Name: "scheduled",
+		Help: "Shows your scheduled tweets, the next one first. Use 'scheduled cancel <id>' to make one a draft again",
+		Func: func(c *ishell.Context) {
+
+			defer c.ShowPrompt(true)
+
+			if len(c.Args) == 2 && c.Args[0] == "cancel" {
+				id, err := strconv.Atoi(c.Args[1])
+				if err != nil {
+					c.Print("Invalid draft ID\n")
+					return
+				}
+				if _, err := drafts.UnscheduleDraft(token, id); err != nil {
+					c.Printf("%s\n", err.Error())
+					return
+				}
+				c.Print("Tweet unscheduled, it is a draft again\n")
+				return
+			}
+			if len(c.Args) != 0 {
+				c.Print("Usage: scheduled [cancel <id>]\n")
+				return
+			}
+			scheduled, err := drafts.GetScheduledTweets(token)
+			if err != nil {
+				c.Printf("Couldn't retrieve scheduled tweets, %s\n", err.Error())
+				return
+			}
+			if len(scheduled) == 0 {
+				c.Print("You have no scheduled tweets\n")
+			}
+			for _, draft := range scheduled {
+				c.Println(draft)
+			}
+			return
+		},
+	})
+
+	shell.Run()
+
+}
+
+//printFollows prints a list of users followed by how many there are, marking the mutuals
+func printFollows(c *ishell.Context, users []domain.User, mutuals []domain.User) {
+	for _, user := range users {
+		if containsUser(mutuals, user) {
+			c.Printf("@%s (mutual)\n", user)
+			continue
+		}
+		c.Printf("@%s\n", user)
+	}
+	c.Printf("%d users\n", len(users))
+}
+
+//readTweetContent asks for the text of a tweet and the URL of its image, which is empty for a text tweet.
+//It returns false if the answers weren't valid
+func readTweetContent(c *ishell.Context) (string, string, bool) {
+	c.Print("Write your tweet: ")
+	text := c.ReadLine()
+	c.Print("Add image? (y/n): ")
+	switch c.ReadLine() {
+	case "y":
+		c.Print("Insert image URL: ")
+		return text, c.ReadLine(), true
+	case "n":
+		return text, "", true
+	}
+	c.Print("Invalid answer\n")
+	return "", "", false
+}
+
+//containsUser returns if a user is in a list
+func containsUser(users []domain.User, user domain.User) bool {
+	for _, other := range users {
+		if other.Equals(user) {
+			return true
+		}
+	}
+	return false
+}
+
+//newRepository returns where the tweets are stored: the event log named by TWEETER_EVENT_LOG,
+//the JSON file named by TWEETER_DATA_FILE, or memory if none of them is set
+func newRepository() service.TweetRepository {
+	if path := os.Getenv("TWEETER_EVENT_LOG"); path != "" {
+		return service.NewEventLogRepository(path, 100)
+	}
+	if path := os.Getenv("TWEETER_DATA_FILE"); path != "" {
+		return service.NewFileTweetRepository(path)
+	}
+	return service.NewMemoryTweetRepository()
+}
 
+//serve runs the HTTP API, on the address given after "serve" or on :8080. Drafts and tweets scheduled through it
+//are kept in memory, and published when they are due while it runs
+//TWEETER_WEBHOOK_URL adds a webhook for the events of every user, signed with TWEETER_WEBHOOK_SECRET
+func serve(manager *service.TweetManager) {
+	addr := ":8080"
+	if len(os.Args) > 2 {
+		addr = os.Args[2]
+	}
+	webhooks := service.NewWebhookDispatcher(manager)
+	defer webhooks.Close()
+	drafts := service.NewDraftManager(manager)
+	defer drafts.Close()
+	if webhookURL := os.Getenv("TWEETER_WEBHOOK_URL"); webhookURL != "" {
+		if _, err := webhooks.AddGlobalWebhook(webhookURL, os.Getenv("TWEETER_WEBHOOK_SECRET")); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+	}
+	fmt.Printf("Serving the tweeter API on %s\n", addr)
+	err := server.NewServerWith(manager, webhooks, drafts).Run(addr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
 }